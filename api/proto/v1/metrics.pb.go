@@ -370,6 +370,1223 @@ func (x *Metric) GetTimestamp() int64 {
 	return 0
 }
 
+type GetTargetsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTargetsRequest) Reset() {
+	*x = GetTargetsRequest{}
+	mi := &file_metrics_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTargetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTargetsRequest) ProtoMessage() {}
+
+func (x *GetTargetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTargetsRequest.ProtoReflect.Descriptor instead.
+func (*GetTargetsRequest) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{7}
+}
+
+type GetTargetsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ActiveTargets []*Target              `protobuf:"bytes,1,rep,name=active_targets,json=activeTargets,proto3" json:"active_targets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTargetsResponse) Reset() {
+	*x = GetTargetsResponse{}
+	mi := &file_metrics_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTargetsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTargetsResponse) ProtoMessage() {}
+
+func (x *GetTargetsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTargetsResponse.ProtoReflect.Descriptor instead.
+func (*GetTargetsResponse) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetTargetsResponse) GetActiveTargets() []*Target {
+	if x != nil {
+		return x.ActiveTargets
+	}
+	return nil
+}
+
+type Target struct {
+	state                     protoimpl.MessageState `protogen:"open.v1"`
+	Job                       string                 `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	Instance                  string                 `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"`
+	ScrapeUrl                 string                 `protobuf:"bytes,3,opt,name=scrape_url,json=scrapeUrl,proto3" json:"scrape_url,omitempty"`
+	Labels                    map[string]string      `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Health                    string                 `protobuf:"bytes,5,opt,name=health,proto3" json:"health,omitempty"` // up, down or unknown
+	LastError                 string                 `protobuf:"bytes,6,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	LastScrape                int64                  `protobuf:"varint,7,opt,name=last_scrape,json=lastScrape,proto3" json:"last_scrape,omitempty"` // Unix timestamp in seconds
+	LastScrapeDurationSeconds float64                `protobuf:"fixed64,8,opt,name=last_scrape_duration_seconds,json=lastScrapeDurationSeconds,proto3" json:"last_scrape_duration_seconds,omitempty"`
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *Target) Reset() {
+	*x = Target{}
+	mi := &file_metrics_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Target) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Target) ProtoMessage() {}
+
+func (x *Target) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Target.ProtoReflect.Descriptor instead.
+func (*Target) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Target) GetJob() string {
+	if x != nil {
+		return x.Job
+	}
+	return ""
+}
+
+func (x *Target) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *Target) GetScrapeUrl() string {
+	if x != nil {
+		return x.ScrapeUrl
+	}
+	return ""
+}
+
+func (x *Target) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Target) GetHealth() string {
+	if x != nil {
+		return x.Health
+	}
+	return ""
+}
+
+func (x *Target) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *Target) GetLastScrape() int64 {
+	if x != nil {
+		return x.LastScrape
+	}
+	return 0
+}
+
+func (x *Target) GetLastScrapeDurationSeconds() float64 {
+	if x != nil {
+		return x.LastScrapeDurationSeconds
+	}
+	return 0
+}
+
+type GetAlertsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAlertsRequest) Reset() {
+	*x = GetAlertsRequest{}
+	mi := &file_metrics_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAlertsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAlertsRequest) ProtoMessage() {}
+
+func (x *GetAlertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAlertsRequest.ProtoReflect.Descriptor instead.
+func (*GetAlertsRequest) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{10}
+}
+
+type GetAlertsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Alerts        []*Alert               `protobuf:"bytes,1,rep,name=alerts,proto3" json:"alerts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAlertsResponse) Reset() {
+	*x = GetAlertsResponse{}
+	mi := &file_metrics_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAlertsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAlertsResponse) ProtoMessage() {}
+
+func (x *GetAlertsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAlertsResponse.ProtoReflect.Descriptor instead.
+func (*GetAlertsResponse) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetAlertsResponse) GetAlerts() []*Alert {
+	if x != nil {
+		return x.Alerts
+	}
+	return nil
+}
+
+type Alert struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Labels        map[string]string      `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Annotations   map[string]string      `protobuf:"bytes,2,rep,name=annotations,proto3" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	State         string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`                        // pending or firing
+	ActiveAt      int64                  `protobuf:"varint,4,opt,name=active_at,json=activeAt,proto3" json:"active_at,omitempty"` // Unix timestamp in seconds
+	Value         float64                `protobuf:"fixed64,5,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Alert) Reset() {
+	*x = Alert{}
+	mi := &file_metrics_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Alert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Alert) ProtoMessage() {}
+
+func (x *Alert) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Alert.ProtoReflect.Descriptor instead.
+func (*Alert) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Alert) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Alert) GetAnnotations() map[string]string {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+func (x *Alert) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Alert) GetActiveAt() int64 {
+	if x != nil {
+		return x.ActiveAt
+	}
+	return 0
+}
+
+func (x *Alert) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+type GetRulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // Optional filter: alert or record
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRulesRequest) Reset() {
+	*x = GetRulesRequest{}
+	mi := &file_metrics_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRulesRequest) ProtoMessage() {}
+
+func (x *GetRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRulesRequest.ProtoReflect.Descriptor instead.
+func (*GetRulesRequest) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetRulesRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type GetRulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []*RuleGroup           `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRulesResponse) Reset() {
+	*x = GetRulesResponse{}
+	mi := &file_metrics_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRulesResponse) ProtoMessage() {}
+
+func (x *GetRulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRulesResponse.ProtoReflect.Descriptor instead.
+func (*GetRulesResponse) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetRulesResponse) GetGroups() []*RuleGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type RuleGroup struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Name                  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	File                  string                 `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	IntervalSeconds       float64                `protobuf:"fixed64,3,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"`
+	Rules                 []*Rule                `protobuf:"bytes,4,rep,name=rules,proto3" json:"rules,omitempty"`
+	LastEvaluation        int64                  `protobuf:"varint,5,opt,name=last_evaluation,json=lastEvaluation,proto3" json:"last_evaluation,omitempty"` // Unix timestamp in seconds
+	EvaluationTimeSeconds float64                `protobuf:"fixed64,6,opt,name=evaluation_time_seconds,json=evaluationTimeSeconds,proto3" json:"evaluation_time_seconds,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *RuleGroup) Reset() {
+	*x = RuleGroup{}
+	mi := &file_metrics_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuleGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleGroup) ProtoMessage() {}
+
+func (x *RuleGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleGroup.ProtoReflect.Descriptor instead.
+func (*RuleGroup) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RuleGroup) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RuleGroup) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *RuleGroup) GetIntervalSeconds() float64 {
+	if x != nil {
+		return x.IntervalSeconds
+	}
+	return 0
+}
+
+func (x *RuleGroup) GetRules() []*Rule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *RuleGroup) GetLastEvaluation() int64 {
+	if x != nil {
+		return x.LastEvaluation
+	}
+	return 0
+}
+
+func (x *RuleGroup) GetEvaluationTimeSeconds() float64 {
+	if x != nil {
+		return x.EvaluationTimeSeconds
+	}
+	return 0
+}
+
+type Rule struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Name                  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Query                 string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	Type                  string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"` // recording or alerting
+	Labels                map[string]string      `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Health                string                 `protobuf:"bytes,5,opt,name=health,proto3" json:"health,omitempty"` // ok, err or unknown
+	LastError             string                 `protobuf:"bytes,6,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	LastEvaluation        int64                  `protobuf:"varint,7,opt,name=last_evaluation,json=lastEvaluation,proto3" json:"last_evaluation,omitempty"` // Unix timestamp in seconds
+	EvaluationTimeSeconds float64                `protobuf:"fixed64,8,opt,name=evaluation_time_seconds,json=evaluationTimeSeconds,proto3" json:"evaluation_time_seconds,omitempty"`
+	// Alerting rules only
+	Annotations          map[string]string `protobuf:"bytes,9,rep,name=annotations,proto3" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	DurationSeconds      float64           `protobuf:"fixed64,10,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`
+	KeepFiringForSeconds float64           `protobuf:"fixed64,11,opt,name=keep_firing_for_seconds,json=keepFiringForSeconds,proto3" json:"keep_firing_for_seconds,omitempty"`
+	State                string            `protobuf:"bytes,12,opt,name=state,proto3" json:"state,omitempty"` // inactive, pending or firing
+	Alerts               []*Alert          `protobuf:"bytes,13,rep,name=alerts,proto3" json:"alerts,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Rule) Reset() {
+	*x = Rule{}
+	mi := &file_metrics_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rule) ProtoMessage() {}
+
+func (x *Rule) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rule.ProtoReflect.Descriptor instead.
+func (*Rule) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Rule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Rule) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *Rule) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Rule) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Rule) GetHealth() string {
+	if x != nil {
+		return x.Health
+	}
+	return ""
+}
+
+func (x *Rule) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *Rule) GetLastEvaluation() int64 {
+	if x != nil {
+		return x.LastEvaluation
+	}
+	return 0
+}
+
+func (x *Rule) GetEvaluationTimeSeconds() float64 {
+	if x != nil {
+		return x.EvaluationTimeSeconds
+	}
+	return 0
+}
+
+func (x *Rule) GetAnnotations() map[string]string {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+func (x *Rule) GetDurationSeconds() float64 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+func (x *Rule) GetKeepFiringForSeconds() float64 {
+	if x != nil {
+		return x.KeepFiringForSeconds
+	}
+	return 0
+}
+
+func (x *Rule) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Rule) GetAlerts() []*Alert {
+	if x != nil {
+		return x.Alerts
+	}
+	return nil
+}
+
+type InstantQueryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Time          int64                  `protobuf:"varint,2,opt,name=time,proto3" json:"time,omitempty"` // Unix timestamp in milliseconds, now if unset
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstantQueryRequest) Reset() {
+	*x = InstantQueryRequest{}
+	mi := &file_metrics_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstantQueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstantQueryRequest) ProtoMessage() {}
+
+func (x *InstantQueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstantQueryRequest.ProtoReflect.Descriptor instead.
+func (*InstantQueryRequest) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *InstantQueryRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *InstantQueryRequest) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+type RangeQueryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Start         int64                  `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"` // Unix timestamp in milliseconds
+	End           int64                  `protobuf:"varint,3,opt,name=end,proto3" json:"end,omitempty"`     // Unix timestamp in milliseconds
+	Step          int64                  `protobuf:"varint,4,opt,name=step,proto3" json:"step,omitempty"`   // Milliseconds between evaluations
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RangeQueryRequest) Reset() {
+	*x = RangeQueryRequest{}
+	mi := &file_metrics_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RangeQueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RangeQueryRequest) ProtoMessage() {}
+
+func (x *RangeQueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RangeQueryRequest.ProtoReflect.Descriptor instead.
+func (*RangeQueryRequest) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RangeQueryRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *RangeQueryRequest) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *RangeQueryRequest) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *RangeQueryRequest) GetStep() int64 {
+	if x != nil {
+		return x.Step
+	}
+	return 0
+}
+
+type QueryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ResultType    string                 `protobuf:"bytes,1,opt,name=result_type,json=resultType,proto3" json:"result_type,omitempty"` // scalar, string, vector or matrix
+	Result        []*QuerySeries         `protobuf:"bytes,2,rep,name=result,proto3" json:"result,omitempty"`                           // A scalar is a single series without labels
+	StringValue   string                 `protobuf:"bytes,3,opt,name=string_value,json=stringValue,proto3" json:"string_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryResponse) Reset() {
+	*x = QueryResponse{}
+	mi := &file_metrics_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryResponse) ProtoMessage() {}
+
+func (x *QueryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryResponse.ProtoReflect.Descriptor instead.
+func (*QueryResponse) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *QueryResponse) GetResultType() string {
+	if x != nil {
+		return x.ResultType
+	}
+	return ""
+}
+
+func (x *QueryResponse) GetResult() []*QuerySeries {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *QueryResponse) GetStringValue() string {
+	if x != nil {
+		return x.StringValue
+	}
+	return ""
+}
+
+type QuerySeries struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Labels        map[string]string      `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Points        []*QueryPoint          `protobuf:"bytes,2,rep,name=points,proto3" json:"points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuerySeries) Reset() {
+	*x = QuerySeries{}
+	mi := &file_metrics_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuerySeries) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuerySeries) ProtoMessage() {}
+
+func (x *QuerySeries) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuerySeries.ProtoReflect.Descriptor instead.
+func (*QuerySeries) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *QuerySeries) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *QuerySeries) GetPoints() []*QueryPoint {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+type QueryPoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Unix timestamp in milliseconds
+	Value         float64                `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryPoint) Reset() {
+	*x = QueryPoint{}
+	mi := &file_metrics_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryPoint) ProtoMessage() {}
+
+func (x *QueryPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryPoint.ProtoReflect.Descriptor instead.
+func (*QueryPoint) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *QueryPoint) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *QueryPoint) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+type GetTSDBStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"` // Entries per statistic, 10 if unset
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTSDBStatusRequest) Reset() {
+	*x = GetTSDBStatusRequest{}
+	mi := &file_metrics_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTSDBStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTSDBStatusRequest) ProtoMessage() {}
+
+func (x *GetTSDBStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTSDBStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetTSDBStatusRequest) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetTSDBStatusRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetTSDBStatusResponse struct {
+	state                       protoimpl.MessageState `protogen:"open.v1"`
+	NumSeries                   int64                  `protobuf:"varint,1,opt,name=num_series,json=numSeries,proto3" json:"num_series,omitempty"`
+	NumSamples                  int64                  `protobuf:"varint,2,opt,name=num_samples,json=numSamples,proto3" json:"num_samples,omitempty"`
+	MinTime                     int64                  `protobuf:"varint,3,opt,name=min_time,json=minTime,proto3" json:"min_time,omitempty"` // Unix timestamp in milliseconds of the oldest sample
+	MaxTime                     int64                  `protobuf:"varint,4,opt,name=max_time,json=maxTime,proto3" json:"max_time,omitempty"` // Unix timestamp in milliseconds of the newest sample
+	NumLabelPairs               int64                  `protobuf:"varint,5,opt,name=num_label_pairs,json=numLabelPairs,proto3" json:"num_label_pairs,omitempty"`
+	SeriesCountByMetricName     []*CardinalityStat     `protobuf:"bytes,6,rep,name=series_count_by_metric_name,json=seriesCountByMetricName,proto3" json:"series_count_by_metric_name,omitempty"`
+	LabelValueCountByLabelName  []*CardinalityStat     `protobuf:"bytes,7,rep,name=label_value_count_by_label_name,json=labelValueCountByLabelName,proto3" json:"label_value_count_by_label_name,omitempty"`
+	SeriesCountByLabelValuePair []*CardinalityStat     `protobuf:"bytes,8,rep,name=series_count_by_label_value_pair,json=seriesCountByLabelValuePair,proto3" json:"series_count_by_label_value_pair,omitempty"`
+	unknownFields               protoimpl.UnknownFields
+	sizeCache                   protoimpl.SizeCache
+}
+
+func (x *GetTSDBStatusResponse) Reset() {
+	*x = GetTSDBStatusResponse{}
+	mi := &file_metrics_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTSDBStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTSDBStatusResponse) ProtoMessage() {}
+
+func (x *GetTSDBStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTSDBStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetTSDBStatusResponse) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetTSDBStatusResponse) GetNumSeries() int64 {
+	if x != nil {
+		return x.NumSeries
+	}
+	return 0
+}
+
+func (x *GetTSDBStatusResponse) GetNumSamples() int64 {
+	if x != nil {
+		return x.NumSamples
+	}
+	return 0
+}
+
+func (x *GetTSDBStatusResponse) GetMinTime() int64 {
+	if x != nil {
+		return x.MinTime
+	}
+	return 0
+}
+
+func (x *GetTSDBStatusResponse) GetMaxTime() int64 {
+	if x != nil {
+		return x.MaxTime
+	}
+	return 0
+}
+
+func (x *GetTSDBStatusResponse) GetNumLabelPairs() int64 {
+	if x != nil {
+		return x.NumLabelPairs
+	}
+	return 0
+}
+
+func (x *GetTSDBStatusResponse) GetSeriesCountByMetricName() []*CardinalityStat {
+	if x != nil {
+		return x.SeriesCountByMetricName
+	}
+	return nil
+}
+
+func (x *GetTSDBStatusResponse) GetLabelValueCountByLabelName() []*CardinalityStat {
+	if x != nil {
+		return x.LabelValueCountByLabelName
+	}
+	return nil
+}
+
+func (x *GetTSDBStatusResponse) GetSeriesCountByLabelValuePair() []*CardinalityStat {
+	if x != nil {
+		return x.SeriesCountByLabelValuePair
+	}
+	return nil
+}
+
+type CardinalityStat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value         int64                  `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CardinalityStat) Reset() {
+	*x = CardinalityStat{}
+	mi := &file_metrics_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CardinalityStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CardinalityStat) ProtoMessage() {}
+
+func (x *CardinalityStat) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CardinalityStat.ProtoReflect.Descriptor instead.
+func (*CardinalityStat) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CardinalityStat) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CardinalityStat) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+type WatchMetricsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Selector      string                 `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"` // Series selector, e.g. up{job="api"}
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchMetricsRequest) Reset() {
+	*x = WatchMetricsRequest{}
+	mi := &file_metrics_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchMetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchMetricsRequest) ProtoMessage() {}
+
+func (x *WatchMetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchMetricsRequest.ProtoReflect.Descriptor instead.
+func (*WatchMetricsRequest) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *WatchMetricsRequest) GetSelector() string {
+	if x != nil {
+		return x.Selector
+	}
+	return ""
+}
+
+type WatchMetricsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metrics       []*Metric              `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchMetricsResponse) Reset() {
+	*x = WatchMetricsResponse{}
+	mi := &file_metrics_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchMetricsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchMetricsResponse) ProtoMessage() {}
+
+func (x *WatchMetricsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_metrics_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchMetricsResponse.ProtoReflect.Descriptor instead.
+func (*WatchMetricsResponse) Descriptor() ([]byte, []int) {
+	return file_metrics_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *WatchMetricsResponse) GetMetrics() []*Metric {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
 var File_metrics_proto protoreflect.FileDescriptor
 
 const file_metrics_proto_rawDesc = "" +
@@ -396,13 +1613,131 @@ const file_metrics_proto_rawDesc = "" +
 	"\ttimestamp\x18\x05 \x01(\x03R\ttimestamp\x1a9\n" +
 	"\vLabelsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012\xe0\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x13\n" +
+	"\x11GetTargetsRequest\"T\n" +
+	"\x12GetTargetsResponse\x12>\n" +
+	"\x0eactive_targets\x18\x01 \x03(\v2\x17.promenitheus.v1.TargetR\ractiveTargets\"\xe6\x02\n" +
+	"\x06Target\x12\x10\n" +
+	"\x03job\x18\x01 \x01(\tR\x03job\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\x12\x1d\n" +
+	"\n" +
+	"scrape_url\x18\x03 \x01(\tR\tscrapeUrl\x12;\n" +
+	"\x06labels\x18\x04 \x03(\v2#.promenitheus.v1.Target.LabelsEntryR\x06labels\x12\x16\n" +
+	"\x06health\x18\x05 \x01(\tR\x06health\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x06 \x01(\tR\tlastError\x12\x1f\n" +
+	"\vlast_scrape\x18\a \x01(\x03R\n" +
+	"lastScrape\x12?\n" +
+	"\x1clast_scrape_duration_seconds\x18\b \x01(\x01R\x19lastScrapeDurationSeconds\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x12\n" +
+	"\x10GetAlertsRequest\"C\n" +
+	"\x11GetAlertsResponse\x12.\n" +
+	"\x06alerts\x18\x01 \x03(\v2\x16.promenitheus.v1.AlertR\x06alerts\"\xd2\x02\n" +
+	"\x05Alert\x12:\n" +
+	"\x06labels\x18\x01 \x03(\v2\".promenitheus.v1.Alert.LabelsEntryR\x06labels\x12I\n" +
+	"\vannotations\x18\x02 \x03(\v2'.promenitheus.v1.Alert.AnnotationsEntryR\vannotations\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x1b\n" +
+	"\tactive_at\x18\x04 \x01(\x03R\bactiveAt\x12\x14\n" +
+	"\x05value\x18\x05 \x01(\x01R\x05value\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a>\n" +
+	"\x10AnnotationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"%\n" +
+	"\x0fGetRulesRequest\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\"F\n" +
+	"\x10GetRulesResponse\x122\n" +
+	"\x06groups\x18\x01 \x03(\v2\x1a.promenitheus.v1.RuleGroupR\x06groups\"\xec\x01\n" +
+	"\tRuleGroup\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04file\x18\x02 \x01(\tR\x04file\x12)\n" +
+	"\x10interval_seconds\x18\x03 \x01(\x01R\x0fintervalSeconds\x12+\n" +
+	"\x05rules\x18\x04 \x03(\v2\x15.promenitheus.v1.RuleR\x05rules\x12'\n" +
+	"\x0flast_evaluation\x18\x05 \x01(\x03R\x0elastEvaluation\x126\n" +
+	"\x17evaluation_time_seconds\x18\x06 \x01(\x01R\x15evaluationTimeSeconds\"\x84\x05\n" +
+	"\x04Rule\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x129\n" +
+	"\x06labels\x18\x04 \x03(\v2!.promenitheus.v1.Rule.LabelsEntryR\x06labels\x12\x16\n" +
+	"\x06health\x18\x05 \x01(\tR\x06health\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x06 \x01(\tR\tlastError\x12'\n" +
+	"\x0flast_evaluation\x18\a \x01(\x03R\x0elastEvaluation\x126\n" +
+	"\x17evaluation_time_seconds\x18\b \x01(\x01R\x15evaluationTimeSeconds\x12H\n" +
+	"\vannotations\x18\t \x03(\v2&.promenitheus.v1.Rule.AnnotationsEntryR\vannotations\x12)\n" +
+	"\x10duration_seconds\x18\n" +
+	" \x01(\x01R\x0fdurationSeconds\x125\n" +
+	"\x17keep_firing_for_seconds\x18\v \x01(\x01R\x14keepFiringForSeconds\x12\x14\n" +
+	"\x05state\x18\f \x01(\tR\x05state\x12.\n" +
+	"\x06alerts\x18\r \x03(\v2\x16.promenitheus.v1.AlertR\x06alerts\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a>\n" +
+	"\x10AnnotationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"?\n" +
+	"\x13InstantQueryRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x12\n" +
+	"\x04time\x18\x02 \x01(\x03R\x04time\"e\n" +
+	"\x11RangeQueryRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\x03R\x05start\x12\x10\n" +
+	"\x03end\x18\x03 \x01(\x03R\x03end\x12\x12\n" +
+	"\x04step\x18\x04 \x01(\x03R\x04step\"\x89\x01\n" +
+	"\rQueryResponse\x12\x1f\n" +
+	"\vresult_type\x18\x01 \x01(\tR\n" +
+	"resultType\x124\n" +
+	"\x06result\x18\x02 \x03(\v2\x1c.promenitheus.v1.QuerySeriesR\x06result\x12!\n" +
+	"\fstring_value\x18\x03 \x01(\tR\vstringValue\"\xbf\x01\n" +
+	"\vQuerySeries\x12@\n" +
+	"\x06labels\x18\x01 \x03(\v2(.promenitheus.v1.QuerySeries.LabelsEntryR\x06labels\x123\n" +
+	"\x06points\x18\x02 \x03(\v2\x1b.promenitheus.v1.QueryPointR\x06points\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"@\n" +
+	"\n" +
+	"QueryPoint\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value\",\n" +
+	"\x14GetTSDBStatusRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\"\xe5\x03\n" +
+	"\x15GetTSDBStatusResponse\x12\x1d\n" +
+	"\n" +
+	"num_series\x18\x01 \x01(\x03R\tnumSeries\x12\x1f\n" +
+	"\vnum_samples\x18\x02 \x01(\x03R\n" +
+	"numSamples\x12\x19\n" +
+	"\bmin_time\x18\x03 \x01(\x03R\aminTime\x12\x19\n" +
+	"\bmax_time\x18\x04 \x01(\x03R\amaxTime\x12&\n" +
+	"\x0fnum_label_pairs\x18\x05 \x01(\x03R\rnumLabelPairs\x12^\n" +
+	"\x1bseries_count_by_metric_name\x18\x06 \x03(\v2 .promenitheus.v1.CardinalityStatR\x17seriesCountByMetricName\x12e\n" +
+	"\x1flabel_value_count_by_label_name\x18\a \x03(\v2 .promenitheus.v1.CardinalityStatR\x1alabelValueCountByLabelName\x12g\n" +
+	" series_count_by_label_value_pair\x18\b \x03(\v2 .promenitheus.v1.CardinalityStatR\x1bseriesCountByLabelValuePair\";\n" +
+	"\x0fCardinalityStat\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value\"1\n" +
+	"\x13WatchMetricsRequest\x12\x1a\n" +
+	"\bselector\x18\x01 \x01(\tR\bselector\"I\n" +
+	"\x14WatchMetricsResponse\x121\n" +
+	"\ametrics\x18\x01 \x03(\v2\x17.promenitheus.v1.MetricR\ametrics2\xe4\b\n" +
 	"\x0eMetricsService\x12g\n" +
 	"\n" +
 	"GetMetrics\x12\".promenitheus.v1.GetMetricsRequest\x1a#.promenitheus.v1.GetMetricsResponse\"\x10\x82\xd3\xe4\x93\x02\n" +
 	"\x12\b/metrics\x12r\n" +
 	"\fQueryMetrics\x12$.promenitheus.v1.QueryMetricsRequest\x1a%.promenitheus.v1.QueryMetricsResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/api/v1/query\x12q\n" +
-	"\vListMetrics\x12#.promenitheus.v1.ListMetricsRequest\x1a$.promenitheus.v1.ListMetricsResponse\"\x17\x82\xd3\xe4\x93\x02\x11\x12\x0f/api/v1/metricsBBZ@github.com/Avinash7390/Promenitheus/api/proto/v1;prometnitheusv1b\x06proto3"
+	"\vListMetrics\x12#.promenitheus.v1.ListMetricsRequest\x1a$.promenitheus.v1.ListMetricsResponse\"\x17\x82\xd3\xe4\x93\x02\x11\x12\x0f/api/v1/metrics\x12n\n" +
+	"\n" +
+	"GetTargets\x12\".promenitheus.v1.GetTargetsRequest\x1a#.promenitheus.v1.GetTargetsResponse\"\x17\x82\xd3\xe4\x93\x02\x11\x12\x0f/api/v1/targets\x12j\n" +
+	"\tGetAlerts\x12!.promenitheus.v1.GetAlertsRequest\x1a\".promenitheus.v1.GetAlertsResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/api/v1/alerts\x12f\n" +
+	"\bGetRules\x12 .promenitheus.v1.GetRulesRequest\x1a!.promenitheus.v1.GetRulesResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/api/v1/rules\x12s\n" +
+	"\fInstantQuery\x12$.promenitheus.v1.InstantQueryRequest\x1a\x1e.promenitheus.v1.QueryResponse\"\x1d\x82\xd3\xe4\x93\x02\x17\x12\x15/api/v1/query_instant\x12m\n" +
+	"\n" +
+	"RangeQuery\x12\".promenitheus.v1.RangeQueryRequest\x1a\x1e.promenitheus.v1.QueryResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/api/v1/query_range\x12{\n" +
+	"\rGetTSDBStatus\x12%.promenitheus.v1.GetTSDBStatusRequest\x1a&.promenitheus.v1.GetTSDBStatusResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/api/v1/status/tsdb\x12]\n" +
+	"\fWatchMetrics\x12$.promenitheus.v1.WatchMetricsRequest\x1a%.promenitheus.v1.WatchMetricsResponse0\x01BBZ@github.com/Avinash7390/Promenitheus/api/proto/v1;prometnitheusv1b\x06proto3"
 
 var (
 	file_metrics_proto_rawDescOnce sync.Once
@@ -416,32 +1751,89 @@ func file_metrics_proto_rawDescGZIP() []byte {
 	return file_metrics_proto_rawDescData
 }
 
-var file_metrics_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_metrics_proto_msgTypes = make([]protoimpl.MessageInfo, 34)
 var file_metrics_proto_goTypes = []any{
-	(*GetMetricsRequest)(nil),    // 0: promenitheus.v1.GetMetricsRequest
-	(*GetMetricsResponse)(nil),   // 1: promenitheus.v1.GetMetricsResponse
-	(*QueryMetricsRequest)(nil),  // 2: promenitheus.v1.QueryMetricsRequest
-	(*QueryMetricsResponse)(nil), // 3: promenitheus.v1.QueryMetricsResponse
-	(*ListMetricsRequest)(nil),   // 4: promenitheus.v1.ListMetricsRequest
-	(*ListMetricsResponse)(nil),  // 5: promenitheus.v1.ListMetricsResponse
-	(*Metric)(nil),               // 6: promenitheus.v1.Metric
-	nil,                          // 7: promenitheus.v1.Metric.LabelsEntry
+	(*GetMetricsRequest)(nil),     // 0: promenitheus.v1.GetMetricsRequest
+	(*GetMetricsResponse)(nil),    // 1: promenitheus.v1.GetMetricsResponse
+	(*QueryMetricsRequest)(nil),   // 2: promenitheus.v1.QueryMetricsRequest
+	(*QueryMetricsResponse)(nil),  // 3: promenitheus.v1.QueryMetricsResponse
+	(*ListMetricsRequest)(nil),    // 4: promenitheus.v1.ListMetricsRequest
+	(*ListMetricsResponse)(nil),   // 5: promenitheus.v1.ListMetricsResponse
+	(*Metric)(nil),                // 6: promenitheus.v1.Metric
+	(*GetTargetsRequest)(nil),     // 7: promenitheus.v1.GetTargetsRequest
+	(*GetTargetsResponse)(nil),    // 8: promenitheus.v1.GetTargetsResponse
+	(*Target)(nil),                // 9: promenitheus.v1.Target
+	(*GetAlertsRequest)(nil),      // 10: promenitheus.v1.GetAlertsRequest
+	(*GetAlertsResponse)(nil),     // 11: promenitheus.v1.GetAlertsResponse
+	(*Alert)(nil),                 // 12: promenitheus.v1.Alert
+	(*GetRulesRequest)(nil),       // 13: promenitheus.v1.GetRulesRequest
+	(*GetRulesResponse)(nil),      // 14: promenitheus.v1.GetRulesResponse
+	(*RuleGroup)(nil),             // 15: promenitheus.v1.RuleGroup
+	(*Rule)(nil),                  // 16: promenitheus.v1.Rule
+	(*InstantQueryRequest)(nil),   // 17: promenitheus.v1.InstantQueryRequest
+	(*RangeQueryRequest)(nil),     // 18: promenitheus.v1.RangeQueryRequest
+	(*QueryResponse)(nil),         // 19: promenitheus.v1.QueryResponse
+	(*QuerySeries)(nil),           // 20: promenitheus.v1.QuerySeries
+	(*QueryPoint)(nil),            // 21: promenitheus.v1.QueryPoint
+	(*GetTSDBStatusRequest)(nil),  // 22: promenitheus.v1.GetTSDBStatusRequest
+	(*GetTSDBStatusResponse)(nil), // 23: promenitheus.v1.GetTSDBStatusResponse
+	(*CardinalityStat)(nil),       // 24: promenitheus.v1.CardinalityStat
+	(*WatchMetricsRequest)(nil),   // 25: promenitheus.v1.WatchMetricsRequest
+	(*WatchMetricsResponse)(nil),  // 26: promenitheus.v1.WatchMetricsResponse
+	nil,                           // 27: promenitheus.v1.Metric.LabelsEntry
+	nil,                           // 28: promenitheus.v1.Target.LabelsEntry
+	nil,                           // 29: promenitheus.v1.Alert.LabelsEntry
+	nil,                           // 30: promenitheus.v1.Alert.AnnotationsEntry
+	nil,                           // 31: promenitheus.v1.Rule.LabelsEntry
+	nil,                           // 32: promenitheus.v1.Rule.AnnotationsEntry
+	nil,                           // 33: promenitheus.v1.QuerySeries.LabelsEntry
 }
 var file_metrics_proto_depIdxs = []int32{
-	6, // 0: promenitheus.v1.QueryMetricsResponse.data:type_name -> promenitheus.v1.Metric
-	6, // 1: promenitheus.v1.ListMetricsResponse.metrics:type_name -> promenitheus.v1.Metric
-	7, // 2: promenitheus.v1.Metric.labels:type_name -> promenitheus.v1.Metric.LabelsEntry
-	0, // 3: promenitheus.v1.MetricsService.GetMetrics:input_type -> promenitheus.v1.GetMetricsRequest
-	2, // 4: promenitheus.v1.MetricsService.QueryMetrics:input_type -> promenitheus.v1.QueryMetricsRequest
-	4, // 5: promenitheus.v1.MetricsService.ListMetrics:input_type -> promenitheus.v1.ListMetricsRequest
-	1, // 6: promenitheus.v1.MetricsService.GetMetrics:output_type -> promenitheus.v1.GetMetricsResponse
-	3, // 7: promenitheus.v1.MetricsService.QueryMetrics:output_type -> promenitheus.v1.QueryMetricsResponse
-	5, // 8: promenitheus.v1.MetricsService.ListMetrics:output_type -> promenitheus.v1.ListMetricsResponse
-	6, // [6:9] is the sub-list for method output_type
-	3, // [3:6] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	6,  // 0: promenitheus.v1.QueryMetricsResponse.data:type_name -> promenitheus.v1.Metric
+	6,  // 1: promenitheus.v1.ListMetricsResponse.metrics:type_name -> promenitheus.v1.Metric
+	27, // 2: promenitheus.v1.Metric.labels:type_name -> promenitheus.v1.Metric.LabelsEntry
+	9,  // 3: promenitheus.v1.GetTargetsResponse.active_targets:type_name -> promenitheus.v1.Target
+	28, // 4: promenitheus.v1.Target.labels:type_name -> promenitheus.v1.Target.LabelsEntry
+	12, // 5: promenitheus.v1.GetAlertsResponse.alerts:type_name -> promenitheus.v1.Alert
+	29, // 6: promenitheus.v1.Alert.labels:type_name -> promenitheus.v1.Alert.LabelsEntry
+	30, // 7: promenitheus.v1.Alert.annotations:type_name -> promenitheus.v1.Alert.AnnotationsEntry
+	15, // 8: promenitheus.v1.GetRulesResponse.groups:type_name -> promenitheus.v1.RuleGroup
+	16, // 9: promenitheus.v1.RuleGroup.rules:type_name -> promenitheus.v1.Rule
+	31, // 10: promenitheus.v1.Rule.labels:type_name -> promenitheus.v1.Rule.LabelsEntry
+	32, // 11: promenitheus.v1.Rule.annotations:type_name -> promenitheus.v1.Rule.AnnotationsEntry
+	12, // 12: promenitheus.v1.Rule.alerts:type_name -> promenitheus.v1.Alert
+	20, // 13: promenitheus.v1.QueryResponse.result:type_name -> promenitheus.v1.QuerySeries
+	33, // 14: promenitheus.v1.QuerySeries.labels:type_name -> promenitheus.v1.QuerySeries.LabelsEntry
+	21, // 15: promenitheus.v1.QuerySeries.points:type_name -> promenitheus.v1.QueryPoint
+	24, // 16: promenitheus.v1.GetTSDBStatusResponse.series_count_by_metric_name:type_name -> promenitheus.v1.CardinalityStat
+	24, // 17: promenitheus.v1.GetTSDBStatusResponse.label_value_count_by_label_name:type_name -> promenitheus.v1.CardinalityStat
+	24, // 18: promenitheus.v1.GetTSDBStatusResponse.series_count_by_label_value_pair:type_name -> promenitheus.v1.CardinalityStat
+	6,  // 19: promenitheus.v1.WatchMetricsResponse.metrics:type_name -> promenitheus.v1.Metric
+	0,  // 20: promenitheus.v1.MetricsService.GetMetrics:input_type -> promenitheus.v1.GetMetricsRequest
+	2,  // 21: promenitheus.v1.MetricsService.QueryMetrics:input_type -> promenitheus.v1.QueryMetricsRequest
+	4,  // 22: promenitheus.v1.MetricsService.ListMetrics:input_type -> promenitheus.v1.ListMetricsRequest
+	7,  // 23: promenitheus.v1.MetricsService.GetTargets:input_type -> promenitheus.v1.GetTargetsRequest
+	10, // 24: promenitheus.v1.MetricsService.GetAlerts:input_type -> promenitheus.v1.GetAlertsRequest
+	13, // 25: promenitheus.v1.MetricsService.GetRules:input_type -> promenitheus.v1.GetRulesRequest
+	17, // 26: promenitheus.v1.MetricsService.InstantQuery:input_type -> promenitheus.v1.InstantQueryRequest
+	18, // 27: promenitheus.v1.MetricsService.RangeQuery:input_type -> promenitheus.v1.RangeQueryRequest
+	22, // 28: promenitheus.v1.MetricsService.GetTSDBStatus:input_type -> promenitheus.v1.GetTSDBStatusRequest
+	25, // 29: promenitheus.v1.MetricsService.WatchMetrics:input_type -> promenitheus.v1.WatchMetricsRequest
+	1,  // 30: promenitheus.v1.MetricsService.GetMetrics:output_type -> promenitheus.v1.GetMetricsResponse
+	3,  // 31: promenitheus.v1.MetricsService.QueryMetrics:output_type -> promenitheus.v1.QueryMetricsResponse
+	5,  // 32: promenitheus.v1.MetricsService.ListMetrics:output_type -> promenitheus.v1.ListMetricsResponse
+	8,  // 33: promenitheus.v1.MetricsService.GetTargets:output_type -> promenitheus.v1.GetTargetsResponse
+	11, // 34: promenitheus.v1.MetricsService.GetAlerts:output_type -> promenitheus.v1.GetAlertsResponse
+	14, // 35: promenitheus.v1.MetricsService.GetRules:output_type -> promenitheus.v1.GetRulesResponse
+	19, // 36: promenitheus.v1.MetricsService.InstantQuery:output_type -> promenitheus.v1.QueryResponse
+	19, // 37: promenitheus.v1.MetricsService.RangeQuery:output_type -> promenitheus.v1.QueryResponse
+	23, // 38: promenitheus.v1.MetricsService.GetTSDBStatus:output_type -> promenitheus.v1.GetTSDBStatusResponse
+	26, // 39: promenitheus.v1.MetricsService.WatchMetrics:output_type -> promenitheus.v1.WatchMetricsResponse
+	30, // [30:40] is the sub-list for method output_type
+	20, // [20:30] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
 }
 
 func init() { file_metrics_proto_init() }
@@ -455,7 +1847,7 @@ func file_metrics_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_metrics_proto_rawDesc), len(file_metrics_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   34,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
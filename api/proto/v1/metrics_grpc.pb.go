@@ -19,9 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MetricsService_GetMetrics_FullMethodName   = "/promenitheus.v1.MetricsService/GetMetrics"
-	MetricsService_QueryMetrics_FullMethodName = "/promenitheus.v1.MetricsService/QueryMetrics"
-	MetricsService_ListMetrics_FullMethodName  = "/promenitheus.v1.MetricsService/ListMetrics"
+	MetricsService_GetMetrics_FullMethodName    = "/promenitheus.v1.MetricsService/GetMetrics"
+	MetricsService_QueryMetrics_FullMethodName  = "/promenitheus.v1.MetricsService/QueryMetrics"
+	MetricsService_ListMetrics_FullMethodName   = "/promenitheus.v1.MetricsService/ListMetrics"
+	MetricsService_GetTargets_FullMethodName    = "/promenitheus.v1.MetricsService/GetTargets"
+	MetricsService_GetAlerts_FullMethodName     = "/promenitheus.v1.MetricsService/GetAlerts"
+	MetricsService_GetRules_FullMethodName      = "/promenitheus.v1.MetricsService/GetRules"
+	MetricsService_InstantQuery_FullMethodName  = "/promenitheus.v1.MetricsService/InstantQuery"
+	MetricsService_RangeQuery_FullMethodName    = "/promenitheus.v1.MetricsService/RangeQuery"
+	MetricsService_GetTSDBStatus_FullMethodName = "/promenitheus.v1.MetricsService/GetTSDBStatus"
+	MetricsService_WatchMetrics_FullMethodName  = "/promenitheus.v1.MetricsService/WatchMetrics"
 )
 
 // MetricsServiceClient is the client API for MetricsService service.
@@ -36,6 +43,21 @@ type MetricsServiceClient interface {
 	QueryMetrics(ctx context.Context, in *QueryMetricsRequest, opts ...grpc.CallOption) (*QueryMetricsResponse, error)
 	// ListMetrics returns all metrics in structured format
 	ListMetrics(ctx context.Context, in *ListMetricsRequest, opts ...grpc.CallOption) (*ListMetricsResponse, error)
+	// GetTargets returns the scrape status of all targets
+	GetTargets(ctx context.Context, in *GetTargetsRequest, opts ...grpc.CallOption) (*GetTargetsResponse, error)
+	// GetAlerts returns all pending and firing alerts
+	GetAlerts(ctx context.Context, in *GetAlertsRequest, opts ...grpc.CallOption) (*GetAlertsResponse, error)
+	// GetRules returns all rule groups with the state of their rules
+	GetRules(ctx context.Context, in *GetRulesRequest, opts ...grpc.CallOption) (*GetRulesResponse, error)
+	// InstantQuery evaluates an expression at a single point in time
+	InstantQuery(ctx context.Context, in *InstantQueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+	// RangeQuery evaluates an expression at every step of a time range
+	RangeQuery(ctx context.Context, in *RangeQueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+	// GetTSDBStatus returns statistics about the stored series
+	GetTSDBStatus(ctx context.Context, in *GetTSDBStatusRequest, opts ...grpc.CallOption) (*GetTSDBStatusResponse, error)
+	// WatchMetrics streams the latest sample of every series matching a
+	// selector, then every new sample of such series as it is registered
+	WatchMetrics(ctx context.Context, in *WatchMetricsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchMetricsResponse], error)
 }
 
 type metricsServiceClient struct {
@@ -76,6 +98,85 @@ func (c *metricsServiceClient) ListMetrics(ctx context.Context, in *ListMetricsR
 	return out, nil
 }
 
+func (c *metricsServiceClient) GetTargets(ctx context.Context, in *GetTargetsRequest, opts ...grpc.CallOption) (*GetTargetsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTargetsResponse)
+	err := c.cc.Invoke(ctx, MetricsService_GetTargets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) GetAlerts(ctx context.Context, in *GetAlertsRequest, opts ...grpc.CallOption) (*GetAlertsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAlertsResponse)
+	err := c.cc.Invoke(ctx, MetricsService_GetAlerts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) GetRules(ctx context.Context, in *GetRulesRequest, opts ...grpc.CallOption) (*GetRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRulesResponse)
+	err := c.cc.Invoke(ctx, MetricsService_GetRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) InstantQuery(ctx context.Context, in *InstantQueryRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, MetricsService_InstantQuery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) RangeQuery(ctx context.Context, in *RangeQueryRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, MetricsService_RangeQuery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) GetTSDBStatus(ctx context.Context, in *GetTSDBStatusRequest, opts ...grpc.CallOption) (*GetTSDBStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTSDBStatusResponse)
+	err := c.cc.Invoke(ctx, MetricsService_GetTSDBStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) WatchMetrics(ctx context.Context, in *WatchMetricsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchMetricsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MetricsService_ServiceDesc.Streams[0], MetricsService_WatchMetrics_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchMetricsRequest, WatchMetricsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricsService_WatchMetricsClient = grpc.ServerStreamingClient[WatchMetricsResponse]
+
 // MetricsServiceServer is the server API for MetricsService service.
 // All implementations must embed UnimplementedMetricsServiceServer
 // for forward compatibility.
@@ -88,6 +189,21 @@ type MetricsServiceServer interface {
 	QueryMetrics(context.Context, *QueryMetricsRequest) (*QueryMetricsResponse, error)
 	// ListMetrics returns all metrics in structured format
 	ListMetrics(context.Context, *ListMetricsRequest) (*ListMetricsResponse, error)
+	// GetTargets returns the scrape status of all targets
+	GetTargets(context.Context, *GetTargetsRequest) (*GetTargetsResponse, error)
+	// GetAlerts returns all pending and firing alerts
+	GetAlerts(context.Context, *GetAlertsRequest) (*GetAlertsResponse, error)
+	// GetRules returns all rule groups with the state of their rules
+	GetRules(context.Context, *GetRulesRequest) (*GetRulesResponse, error)
+	// InstantQuery evaluates an expression at a single point in time
+	InstantQuery(context.Context, *InstantQueryRequest) (*QueryResponse, error)
+	// RangeQuery evaluates an expression at every step of a time range
+	RangeQuery(context.Context, *RangeQueryRequest) (*QueryResponse, error)
+	// GetTSDBStatus returns statistics about the stored series
+	GetTSDBStatus(context.Context, *GetTSDBStatusRequest) (*GetTSDBStatusResponse, error)
+	// WatchMetrics streams the latest sample of every series matching a
+	// selector, then every new sample of such series as it is registered
+	WatchMetrics(*WatchMetricsRequest, grpc.ServerStreamingServer[WatchMetricsResponse]) error
 	mustEmbedUnimplementedMetricsServiceServer()
 }
 
@@ -107,6 +223,27 @@ func (UnimplementedMetricsServiceServer) QueryMetrics(context.Context, *QueryMet
 func (UnimplementedMetricsServiceServer) ListMetrics(context.Context, *ListMetricsRequest) (*ListMetricsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListMetrics not implemented")
 }
+func (UnimplementedMetricsServiceServer) GetTargets(context.Context, *GetTargetsRequest) (*GetTargetsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTargets not implemented")
+}
+func (UnimplementedMetricsServiceServer) GetAlerts(context.Context, *GetAlertsRequest) (*GetAlertsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAlerts not implemented")
+}
+func (UnimplementedMetricsServiceServer) GetRules(context.Context, *GetRulesRequest) (*GetRulesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRules not implemented")
+}
+func (UnimplementedMetricsServiceServer) InstantQuery(context.Context, *InstantQueryRequest) (*QueryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method InstantQuery not implemented")
+}
+func (UnimplementedMetricsServiceServer) RangeQuery(context.Context, *RangeQueryRequest) (*QueryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RangeQuery not implemented")
+}
+func (UnimplementedMetricsServiceServer) GetTSDBStatus(context.Context, *GetTSDBStatusRequest) (*GetTSDBStatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTSDBStatus not implemented")
+}
+func (UnimplementedMetricsServiceServer) WatchMetrics(*WatchMetricsRequest, grpc.ServerStreamingServer[WatchMetricsResponse]) error {
+	return status.Error(codes.Unimplemented, "method WatchMetrics not implemented")
+}
 func (UnimplementedMetricsServiceServer) mustEmbedUnimplementedMetricsServiceServer() {}
 func (UnimplementedMetricsServiceServer) testEmbeddedByValue()                        {}
 
@@ -182,6 +319,125 @@ func _MetricsService_ListMetrics_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MetricsService_GetTargets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTargetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).GetTargets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_GetTargets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).GetTargets(ctx, req.(*GetTargetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_GetAlerts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAlertsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).GetAlerts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_GetAlerts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).GetAlerts(ctx, req.(*GetAlertsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_GetRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).GetRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_GetRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).GetRules(ctx, req.(*GetRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_InstantQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InstantQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).InstantQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_InstantQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).InstantQuery(ctx, req.(*InstantQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_RangeQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RangeQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).RangeQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_RangeQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).RangeQuery(ctx, req.(*RangeQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_GetTSDBStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTSDBStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).GetTSDBStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_GetTSDBStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).GetTSDBStatus(ctx, req.(*GetTSDBStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_WatchMetrics_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchMetricsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MetricsServiceServer).WatchMetrics(m, &grpc.GenericServerStream[WatchMetricsRequest, WatchMetricsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MetricsService_WatchMetricsServer = grpc.ServerStreamingServer[WatchMetricsResponse]
+
 // MetricsService_ServiceDesc is the grpc.ServiceDesc for MetricsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -201,7 +457,37 @@ var MetricsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListMetrics",
 			Handler:    _MetricsService_ListMetrics_Handler,
 		},
+		{
+			MethodName: "GetTargets",
+			Handler:    _MetricsService_GetTargets_Handler,
+		},
+		{
+			MethodName: "GetAlerts",
+			Handler:    _MetricsService_GetAlerts_Handler,
+		},
+		{
+			MethodName: "GetRules",
+			Handler:    _MetricsService_GetRules_Handler,
+		},
+		{
+			MethodName: "InstantQuery",
+			Handler:    _MetricsService_InstantQuery_Handler,
+		},
+		{
+			MethodName: "RangeQuery",
+			Handler:    _MetricsService_RangeQuery_Handler,
+		},
+		{
+			MethodName: "GetTSDBStatus",
+			Handler:    _MetricsService_GetTSDBStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchMetrics",
+			Handler:       _MetricsService_WatchMetrics_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "metrics.proto",
 }
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/lint"
+	"github.com/Avinash7390/Promenitheus/pkg/rules"
+)
+
+// checkConfig validates configuration files and the rule files they
+// reference
+func checkConfig(args []string) int {
+	fs := flag.NewFlagSet("check config", flag.ContinueOnError)
+	expandEnv := fs.Bool("expand-env", false, "Replace ${VAR} in the files with environment variables")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	files := fs.Args()
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: promenitheus-tool check config [--expand-env] <config-file>...")
+		return 2
+	}
+
+	code := 0
+	for _, file := range files {
+		fmt.Printf("Checking %s\n", file)
+
+		cfg, err := config.LoadConfigWithOptions(file, config.LoadOptions{ExpandEnv: *expandEnv})
+		if err != nil {
+			fmt.Printf("  FAILED: %v\n\n", err)
+			code = 1
+			continue
+		}
+		fmt.Printf("  SUCCESS: %d scrape config(s) found\n\n", len(cfg.ScrapeConfigs))
+
+		for _, pattern := range cfg.RuleFiles {
+			ruleFiles, err := filepath.Glob(pattern)
+			if err != nil {
+				fmt.Printf("  FAILED: invalid rule file pattern %q: %v\n\n", pattern, err)
+				code = 1
+				continue
+			}
+			if !checkRuleFiles(ruleFiles) {
+				code = 1
+			}
+		}
+	}
+	return code
+}
+
+// checkRules validates rule files
+func checkRules(files []string) int {
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: promenitheus-tool check rules <rule-file>...")
+		return 2
+	}
+
+	if !checkRuleFiles(files) {
+		return 1
+	}
+	return 0
+}
+
+// checkRuleFiles reports for each rule file whether it is valid and how
+// many rules it contains
+func checkRuleFiles(files []string) bool {
+	ok := true
+	for _, file := range files {
+		fmt.Printf("Checking %s\n", file)
+
+		rf, err := rules.ParseFile(file)
+		if err != nil {
+			fmt.Printf("  FAILED: %v\n\n", err)
+			ok = false
+			continue
+		}
+
+		n := 0
+		for _, g := range rf.Groups {
+			n += len(g.Rules)
+		}
+		fmt.Printf("  SUCCESS: %d rule(s) found\n\n", n)
+	}
+	return ok
+}
+
+// checkMetrics lints metrics read from stdin
+func checkMetrics() int {
+	problems, err := lint.Lint(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing metrics: %v\n", err)
+		return 1
+	}
+
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// testRules runs rule unit tests
+func testRules(files []string) int {
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: promenitheus-tool test rules <test-file>...")
+		return 2
+	}
+
+	if !rules.RunUnitTests(os.Stdout, files...) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: promenitheus-tool <command> [<args>]
+
+Commands:
+  check config [--expand-env] <config-file>...
+                                  Validate configuration files and their rule files
+  check rules <rule-file>...      Validate rule files
+  check metrics                   Lint metrics in the text format read from stdin
+  test rules <test-file>...       Run rule unit tests
+  query instant [flags] <expr>    Evaluate an expression at a single point in time
+  query range [flags] <expr>      Evaluate an expression over a time range
+  tsdb analyze [flags]            Report series and label cardinality of a server
+
+Run 'promenitheus-tool <command> <subcommand> -h' for the flags of query and
+tsdb commands.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run executes a command and returns the exit code: 0 on success, 1 if a
+// check or query failed and 2 for usage errors
+func run(args []string) int {
+	if len(args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	command, args := args[0]+" "+args[1], args[2:]
+	switch command {
+	case "check config":
+		return checkConfig(args)
+	case "check rules":
+		return checkRules(args)
+	case "check metrics":
+		return checkMetrics()
+	case "test rules":
+		return testRules(args)
+	case "query instant":
+		return queryInstant(args)
+	case "query range":
+		return queryRange(args)
+	case "tsdb analyze":
+		return tsdbAnalyze(args)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", command, usage)
+		return 2
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// serverFlags are the flags shared by commands talking to a server
+type serverFlags struct {
+	server  *string
+	timeout *time.Duration
+
+	tls                *bool
+	caFile             *string
+	certFile           *string
+	keyFile            *string
+	serverName         *string
+	insecureSkipVerify *bool
+
+	basicAuth   *string
+	bearerToken *string
+}
+
+func addServerFlags(fs *flag.FlagSet) serverFlags {
+	return serverFlags{
+		server:  fs.String("server", "localhost:9090", "Address of the Promenitheus server"),
+		timeout: fs.Duration("timeout", 30*time.Second, "Timeout of the request"),
+
+		tls:                fs.Bool("tls", false, "Connect with TLS, implied by the other --tls flags"),
+		caFile:             fs.String("tls.ca-file", "", "CA certificate to verify the server certificate against (default: system roots)"),
+		certFile:           fs.String("tls.cert-file", "", "Client certificate for servers requiring mTLS"),
+		keyFile:            fs.String("tls.key-file", "", "Key of the client certificate"),
+		serverName:         fs.String("tls.server-name", "", "Name to verify the server certificate against (default: host of --server)"),
+		insecureSkipVerify: fs.Bool("tls.insecure-skip-verify", false, "Do not verify the server certificate"),
+
+		basicAuth:   fs.String("basic-auth", "", "Credentials as user:password"),
+		bearerToken: fs.String("bearer-token", "", "Bearer token sent in the Authorization header"),
+	}
+}
+
+// dialOptions returns the transport and call credentials selected by the
+// flags
+func (f serverFlags) dialOptions() ([]grpc.DialOption, error) {
+	var opts []grpc.DialOption
+
+	useTLS := *f.tls || *f.caFile != "" || *f.certFile != "" || *f.keyFile != "" || *f.serverName != "" || *f.insecureSkipVerify
+	if useTLS {
+		tlsConfig := &tls.Config{
+			ServerName:         *f.serverName,
+			InsecureSkipVerify: *f.insecureSkipVerify,
+		}
+		if *f.caFile != "" {
+			caPEM, err := os.ReadFile(*f.caFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+				return nil, fmt.Errorf("no certificates found in CA file %s", *f.caFile)
+			}
+		}
+		if *f.certFile != "" || *f.keyFile != "" {
+			cert, err := tls.LoadX509KeyPair(*f.certFile, *f.keyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	switch {
+	case *f.basicAuth != "" && *f.bearerToken != "":
+		return nil, fmt.Errorf("--basic-auth and --bearer-token are mutually exclusive")
+	case *f.basicAuth != "":
+		if !strings.Contains(*f.basicAuth, ":") {
+			return nil, fmt.Errorf("--basic-auth must be user:password")
+		}
+		opts = append(opts, grpc.WithPerRPCCredentials(authCredentials{
+			header: "Basic " + base64.StdEncoding.EncodeToString([]byte(*f.basicAuth)),
+			secure: useTLS,
+		}))
+	case *f.bearerToken != "":
+		opts = append(opts, grpc.WithPerRPCCredentials(authCredentials{
+			header: "Bearer " + *f.bearerToken,
+			secure: useTLS,
+		}))
+	}
+	return opts, nil
+}
+
+// authCredentials sends an Authorization header as metadata of every call
+type authCredentials struct {
+	header string
+	// secure is set with TLS, so gRPC refuses to send the header if the
+	// connection turns out not to be encrypted
+	secure bool
+}
+
+func (c authCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": c.header}, nil
+}
+
+func (c authCredentials) RequireTransportSecurity() bool {
+	return c.secure
+}
+
+// connect opens a gRPC connection to the server and returns a client with a
+// context bound by the request timeout. The returned function releases both.
+func (f serverFlags) connect() (pb.MetricsServiceClient, context.Context, func(), error) {
+	opts, err := f.dialOptions()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	conn, err := grpc.NewClient(*f.server, opts...)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to connect to %s: %w", *f.server, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *f.timeout)
+	return pb.NewMetricsServiceClient(conn), ctx, func() {
+		cancel()
+		conn.Close()
+	}, nil
+}
+
+// queryInstant evaluates an expression at a single point in time
+func queryInstant(args []string) int {
+	fs := flag.NewFlagSet("query instant", flag.ContinueOnError)
+	sf := addServerFlags(fs)
+	at := fs.String("time", "", "Evaluation time as RFC3339 or Unix timestamp (default: now)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: promenitheus-tool query instant [flags] <expr>")
+		return 2
+	}
+
+	req := &pb.InstantQueryRequest{Query: fs.Arg(0)}
+	if *at != "" {
+		ts, err := parseTime(*at)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing time: %v\n", err)
+			return 2
+		}
+		req.Time = ts.UnixMilli()
+	}
+
+	client, ctx, closeFn, err := sf.connect()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer closeFn()
+
+	resp, err := client.InstantQuery(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error querying server: %v\n", status.Convert(err).Message())
+		return 1
+	}
+	printQueryResponse(resp)
+	return 0
+}
+
+// queryRange evaluates an expression at every step of a time range
+func queryRange(args []string) int {
+	fs := flag.NewFlagSet("query range", flag.ContinueOnError)
+	sf := addServerFlags(fs)
+	startFlag := fs.String("start", "", "Start time as RFC3339 or Unix timestamp (default: one hour before end)")
+	endFlag := fs.String("end", "", "End time as RFC3339 or Unix timestamp (default: now)")
+	step := fs.Duration("step", 0, "Time between evaluations (default: 1/250 of the range, at least 1s)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: promenitheus-tool query range [flags] <expr>")
+		return 2
+	}
+
+	end := time.Now()
+	if *endFlag != "" {
+		ts, err := parseTime(*endFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing end: %v\n", err)
+			return 2
+		}
+		end = ts
+	}
+	start := end.Add(-time.Hour)
+	if *startFlag != "" {
+		ts, err := parseTime(*startFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing start: %v\n", err)
+			return 2
+		}
+		start = ts
+	}
+	if *step == 0 {
+		*step = max(end.Sub(start)/250, time.Second).Round(time.Second)
+	}
+
+	client, ctx, closeFn, err := sf.connect()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer closeFn()
+
+	resp, err := client.RangeQuery(ctx, &pb.RangeQueryRequest{
+		Query: fs.Arg(0),
+		Start: start.UnixMilli(),
+		End:   end.UnixMilli(),
+		Step:  step.Milliseconds(),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error querying server: %v\n", status.Convert(err).Message())
+		return 1
+	}
+	printQueryResponse(resp)
+	return 0
+}
+
+// printQueryResponse writes a query result as one line per sample, or per
+// series followed by its points for matrices
+func printQueryResponse(resp *pb.QueryResponse) {
+	switch resp.ResultType {
+	case "string":
+		fmt.Println(strconv.Quote(resp.StringValue))
+	case "matrix":
+		for _, s := range resp.Result {
+			fmt.Println(seriesName(s.Labels))
+			for _, p := range s.Points {
+				fmt.Printf("  %s @[%s]\n", formatValue(p.Value), formatTimestamp(p.Timestamp))
+			}
+		}
+	default:
+		for _, s := range resp.Result {
+			for _, p := range s.Points {
+				if resp.ResultType == "scalar" {
+					fmt.Printf("scalar: %s @[%s]\n", formatValue(p.Value), formatTimestamp(p.Timestamp))
+				} else {
+					fmt.Printf("%s => %s @[%s]\n", seriesName(s.Labels), formatValue(p.Value), formatTimestamp(p.Timestamp))
+				}
+			}
+		}
+	}
+}
+
+// seriesName formats labels as name{label="value", ...}
+func seriesName(labels map[string]string) string {
+	name, rest := query.SplitLabels(labels)
+	if len(rest) == 0 && name != "" {
+		return name
+	}
+	return name + query.LabelsString(rest)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// formatTimestamp formats Unix milliseconds as seconds with three decimals
+func formatTimestamp(ms int64) string {
+	return strconv.FormatFloat(float64(ms)/1000, 'f', 3, 64)
+}
+
+// parseTime parses an RFC3339 time or a Unix timestamp in seconds
+func parseTime(s string) (time.Time, error) {
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.UnixMilli(int64(math.Round(secs * 1000))), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse %q as RFC3339 or Unix timestamp", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"google.golang.org/grpc/status"
+)
+
+// tsdbAnalyze reports the number of series and samples stored by a server
+// and the metric names and labels with the highest cardinality
+func tsdbAnalyze(args []string) int {
+	fs := flag.NewFlagSet("tsdb analyze", flag.ContinueOnError)
+	sf := addServerFlags(fs)
+	limit := fs.Int("limit", 20, "How many entries to show per statistic")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "Usage: promenitheus-tool tsdb analyze [flags]")
+		return 2
+	}
+
+	client, ctx, closeFn, err := sf.connect()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer closeFn()
+
+	resp, err := client.GetTSDBStatus(ctx, &pb.GetTSDBStatusRequest{Limit: int32(*limit)})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error querying server: %v\n", status.Convert(err).Message())
+		return 1
+	}
+
+	fmt.Printf("Series: %d\n", resp.NumSeries)
+	fmt.Printf("Samples: %d\n", resp.NumSamples)
+	fmt.Printf("Label pairs: %d\n", resp.NumLabelPairs)
+	if resp.NumSamples > 0 {
+		minTime, maxTime := time.UnixMilli(resp.MinTime).UTC(), time.UnixMilli(resp.MaxTime).UTC()
+		fmt.Printf("Time range: %s to %s (%s)\n", minTime.Format(time.RFC3339), maxTime.Format(time.RFC3339), maxTime.Sub(minTime))
+	}
+
+	printCardinality("Highest cardinality metric names", resp.SeriesCountByMetricName)
+	printCardinality("Highest cardinality labels", resp.LabelValueCountByLabelName)
+	printCardinality("Most common label pairs", resp.SeriesCountByLabelValuePair)
+	return 0
+}
+
+func printCardinality(title string, stats []*pb.CardinalityStat) {
+	fmt.Printf("\n%s:\n", title)
+	for _, s := range stats {
+		fmt.Printf("%8d %s\n", s.Value, s.Name)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"github.com/Avinash7390/Promenitheus/pkg/instrument"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/notifier"
+	"github.com/Avinash7390/Promenitheus/pkg/remote"
+)
+
+// registerSelfMetrics adds the metrics of the registry, the notifier and the
+// remote write queues to reg. writeStorage may be nil.
+func registerSelfMetrics(reg *instrument.Registry, registry *metrics.MetricRegistry, notifierManager *notifier.Manager, writeStorage *remote.WriteStorage) {
+	appended := instrument.NewCounterVec("promenitheus_registry_samples_appended_total",
+		"Samples appended to the registry.")
+	registry.AddListener(func(*metrics.Metric) { appended.Inc() })
+
+	reg.MustRegister(
+		instrument.NewGoCollector(),
+		appended,
+		instrument.NewGaugeFunc("promenitheus_registry_series",
+			"Series held in the registry.", func() float64 {
+				return float64(registry.Stats().NumSeries)
+			}),
+		instrument.NewGaugeFunc("promenitheus_registry_samples",
+			"Samples held in the registry.", func() float64 {
+				return float64(registry.Stats().NumSamples)
+			}),
+	)
+
+	reg.MustRegister(
+		instrument.NewCounterFunc("promenitheus_notifications_sent_total",
+			"Alerts sent, counted once per Alertmanager.", func() float64 {
+				return float64(notifierManager.Stats().Sent)
+			}),
+		instrument.NewCounterFunc("promenitheus_notifications_errors_total",
+			"Alerts that failed to reach an Alertmanager.", func() float64 {
+				return float64(notifierManager.Stats().Errors)
+			}),
+		instrument.NewCounterFunc("promenitheus_notifications_dropped_total",
+			"Alerts dropped because the queue was full or no Alertmanager accepted them.", func() float64 {
+				return float64(notifierManager.Stats().Dropped)
+			}),
+		instrument.NewGaugeFunc("promenitheus_notifications_queue_length",
+			"Alerts waiting to be sent.", func() float64 {
+				return float64(notifierManager.Stats().QueueLength)
+			}),
+	)
+
+	if writeStorage == nil {
+		return
+	}
+	queueStat := func(name, help string, typ instrument.Type, value func(remote.Stats) float64) instrument.Collector {
+		return instrument.NewFunc(name, help, typ, func() []instrument.Sample {
+			var samples []instrument.Sample
+			for _, qm := range writeStorage.Queues() {
+				samples = append(samples, instrument.Sample{
+					Labels: map[string]string{"remote_name": qm.Name()},
+					Value:  value(qm.Stats()),
+				})
+			}
+			return samples
+		})
+	}
+	reg.MustRegister(
+		queueStat("promenitheus_remote_storage_samples_in_total", "Samples accepted into the remote write queue.",
+			instrument.Counter, func(st remote.Stats) float64 { return float64(st.SamplesIn) }),
+		queueStat("promenitheus_remote_storage_samples_sent_total", "Samples accepted by the remote endpoint.",
+			instrument.Counter, func(st remote.Stats) float64 { return float64(st.SamplesSent) }),
+		queueStat("promenitheus_remote_storage_samples_failed_total", "Samples rejected with a non-recoverable error.",
+			instrument.Counter, func(st remote.Stats) float64 { return float64(st.SamplesFailed) }),
+		queueStat("promenitheus_remote_storage_samples_retried_total", "Samples in requests that were retried.",
+			instrument.Counter, func(st remote.Stats) float64 { return float64(st.SamplesRetried) }),
+		queueStat("promenitheus_remote_storage_samples_dropped_total", "Samples dropped because a shard was full or the queue stopped.",
+			instrument.Counter, func(st remote.Stats) float64 { return float64(st.SamplesDropped) }),
+		queueStat("promenitheus_remote_storage_shards", "Current number of shards.",
+			instrument.Gauge, func(st remote.Stats) float64 { return float64(st.Shards) }),
+		queueStat("promenitheus_remote_storage_samples_pending", "Samples waiting to be sent.",
+			instrument.Gauge, func(st remote.Stats) float64 { return float64(st.Pending) }),
+	)
+}
@@ -4,28 +4,64 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/instrument"
 	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/notifier"
+	"github.com/Avinash7390/Promenitheus/pkg/push"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+	"github.com/Avinash7390/Promenitheus/pkg/remote"
+	"github.com/Avinash7390/Promenitheus/pkg/rules"
 	"github.com/Avinash7390/Promenitheus/pkg/scraper"
 	"github.com/Avinash7390/Promenitheus/pkg/storage"
 )
 
 func main() {
+	// Subcommands run instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "test" {
+		os.Exit(runTest(os.Args[2:]))
+	}
+
 	configPath := flag.String("config", "config.yaml", "Path to configuration file")
+	expandEnv := flag.Bool("config.expand-env", false, "Replace ${VAR} in the configuration file with environment variables")
 	port := flag.Int("port", 9090, "Port to expose metrics on")
+	enableRemoteWriteReceiver := flag.Bool("web.enable-remote-write-receiver", false, "Accept remote write requests at /api/v1/write")
+	enablePush := flag.Bool("web.enable-push", false, "Accept pushed metrics at /metrics/job/<job>")
+	enableLifecycle := flag.Bool("web.enable-lifecycle", false, "Reload the configuration on POST or PUT requests to /-/reload")
+	webConfigPath := flag.String("web.config.file", "", "Path to a file configuring TLS and authentication of the HTTP and gRPC endpoints")
+	logLevel := flag.String("log.level", "info", "Only log messages with the given severity or above: debug, info, warn or error")
+	logFormat := flag.String("log.format", "logfmt", "Output format of log messages: logfmt or json")
 	flag.Parse()
 
+	logger, err := newLogger(os.Stderr, *logLevel, *logFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+
 	// Load configuration
-	cfg, err := config.LoadConfig(*configPath)
+	loadOpts := config.LoadOptions{ExpandEnv: *expandEnv}
+	cfg, err := config.LoadConfigWithOptions(*configPath, loadOpts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		slog.Error("Error loading config", "file", *configPath, "err", err)
 		os.Exit(1)
 	}
 
+	var webConfig *config.WebConfig
+	if *webConfigPath != "" {
+		webConfig, err = config.LoadWebConfig(*webConfigPath)
+		if err != nil {
+			slog.Error("Error loading web config", "file", *webConfigPath, "err", err)
+			os.Exit(1)
+		}
+	}
+
 	// Create metric registry
 	registry := metrics.NewMetricRegistry()
 
@@ -33,23 +69,177 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	scr := scraper.NewScraper(cfg, registry)
+	// Send accepted samples to remote storage
+	var writeStorage *remote.WriteStorage
+	if len(cfg.RemoteWriteConfigs) > 0 {
+		writeStorage, err = remote.NewWriteStorage(cfg.RemoteWriteConfigs, cfg.Global.ExternalLabels)
+		if err != nil {
+			slog.Error("Error creating remote write", "err", err)
+			os.Exit(1)
+		}
+		registry.AddListener(writeStorage.Append)
+		go writeStorage.Run(ctx)
+	}
+
+	scr, err := scraper.NewScraper(cfg, registry)
+	if err != nil {
+		slog.Error("Error creating scraper", "err", err)
+		os.Exit(1)
+	}
 	scr.Start(ctx)
 
-	// Setup signal handling for graceful shutdown
+	// Create and start the alert notifier
+	notifierManager, err := notifier.NewManager(cfg.Alerting)
+	if err != nil {
+		slog.Error("Error creating notifier", "err", err)
+		os.Exit(1)
+	}
+	go notifierManager.Run(ctx)
+	senders := []notifier.Sender{notifierManager}
+
+	// Optionally route alerts to receivers without an external Alertmanager
+	if cfg.Alerting.Routing != nil {
+		dispatcher, err := notifier.NewDispatcher(*cfg.Alerting.Routing)
+		if err != nil {
+			slog.Error("Error creating alert dispatcher", "err", err)
+			os.Exit(1)
+		}
+		go dispatcher.Run(ctx)
+		senders = append(senders, dispatcher)
+	}
+
+	// Queries read remote storage in addition to local data if configured
+	var queryable query.Queryable = registry
+	if len(cfg.RemoteReadConfigs) > 0 {
+		queryable, err = remote.NewQueryable(registry, cfg.RemoteReadConfigs)
+		if err != nil {
+			slog.Error("Error creating remote read", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	// Rules and API queries share one engine
+	engine := query.NewEngine(queryable)
+	if cfg.Global.QueryLogFile != "" {
+		queryLogFile, err := os.OpenFile(cfg.Global.QueryLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			slog.Error("Error opening query log file", "err", err)
+			os.Exit(1)
+		}
+		defer queryLogFile.Close()
+		engine.SetQueryLog(queryLogFile)
+	}
+
+	// Load and start rule evaluation
+	ruleManager := rules.NewManager(engine, registry)
+	ruleManager.SetEvaluationInterval(cfg.Global.EvaluationInterval)
+	ruleManager.SetNotifier(sendAlerts(senders, cfg.Global.ExternalLabels), cfg.Alerting.ResendDelay)
+	if err := ruleManager.LoadGroups(cfg.RuleFiles); err != nil {
+		slog.Error("Error loading rules", "err", err)
+		os.Exit(1)
+	}
+	ruleManager.Start(ctx)
+
+	// The initial load counts as a successful reload
+	reload := &reloader{configPath: *configPath, loadOpts: loadOpts, scraper: scr, rules: ruleManager}
+	reload.report(nil)
+
+	// Metrics about Promenitheus itself, served separately from stored data
+	selfMetrics := instrument.NewRegistry()
+	registerSelfMetrics(selfMetrics, registry, notifierManager, writeStorage)
+	scr.RegisterMetrics(selfMetrics)
+	engine.RegisterMetrics(selfMetrics)
+	selfMetrics.MustRegister(reload.collectors()...)
+
+	// Setup signal handling for graceful shutdown and reloads
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
 
 	go func() {
-		<-sigChan
-		fmt.Println("\nShutting down gracefully...")
-		cancel()
+		for {
+			select {
+			case <-hupChan:
+				reload.reload()
+			case <-sigChan:
+				slog.Info("Shutting down gracefully")
+				cancel()
+				return
+			}
+		}
 	}()
 
 	// Start HTTP server
 	server := storage.NewServer(registry, *port)
+	server.SetTargetProvider(scr)
+	server.SetRuleProvider(ruleManager)
+	server.SetQueryEngine(engine)
+	server.SetExternalLabels(cfg.Global.ExternalLabels)
+	if *enableLifecycle {
+		server.SetReloadFunc(reload.reload)
+	}
+	server.SetSelfMetrics(selfMetrics)
+	if webConfig != nil {
+		server.SetWebConfig(webConfig)
+	}
+	if *enableRemoteWriteReceiver {
+		server.EnableRemoteWriteReceiver()
+	}
+	if *enablePush {
+		pushStore := push.NewStore(registry)
+		go pushStore.Run(ctx, cfg.Global.ScrapeInterval)
+		server.SetPushStore(pushStore)
+	}
 	if err := server.Start(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error starting server: %v\n", err)
+		slog.Error("Error starting server", "err", err)
 		os.Exit(1)
 	}
 }
+
+// runTest runs the 'test' subcommand and returns the exit code
+func runTest(args []string) int {
+	if len(args) < 2 || args[0] != "rules" {
+		fmt.Fprintln(os.Stderr, "Usage: promenitheus test rules <test-file>...")
+		return 2
+	}
+	if !rules.RunUnitTests(os.Stdout, args[1:]...) {
+		return 1
+	}
+	return 0
+}
+
+// sendAlerts returns a function passing alerts from the rule manager to the
+// notifiers in the Alertmanager format, with the external labels they lack
+func sendAlerts(senders []notifier.Sender, externalLabels map[string]string) rules.NotifyFunc {
+	return func(alerts []*rules.Alert) {
+		res := make([]*notifier.Alert, 0, len(alerts))
+		for _, a := range alerts {
+			labels := a.Labels
+			if len(externalLabels) > 0 {
+				labels = make(map[string]string, len(a.Labels)+len(externalLabels))
+				for k, v := range externalLabels {
+					labels[k] = v
+				}
+				for k, v := range a.Labels {
+					labels[k] = v
+				}
+			}
+
+			alert := &notifier.Alert{
+				Labels:      labels,
+				Annotations: a.Annotations,
+				StartsAt:    a.ActiveAt,
+			}
+			if !a.ResolvedAt.IsZero() {
+				alert.EndsAt = a.ResolvedAt
+			} else {
+				alert.EndsAt = a.ValidUntil
+			}
+			res = append(res, alert)
+		}
+		for _, s := range senders {
+			s.Send(res...)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/instrument"
+	"github.com/Avinash7390/Promenitheus/pkg/rules"
+	"github.com/Avinash7390/Promenitheus/pkg/scraper"
+)
+
+// reloader applies changes of the configuration file to a running server.
+// Scrape jobs and rule files are reloaded; other sections require a
+// restart.
+type reloader struct {
+	configPath string
+	loadOpts   config.LoadOptions
+	scraper    *scraper.Scraper
+	rules      *rules.Manager
+
+	mu sync.Mutex
+
+	// Outcome of the last reload, guarded by statusMu
+	statusMu    sync.Mutex
+	successful  bool
+	lastSuccess time.Time
+}
+
+// reload loads and applies the configuration file and records the outcome
+func (r *reloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.apply()
+	if err != nil {
+		slog.Error("Error reloading config", "file", r.configPath, "err", err)
+	} else {
+		slog.Info("Reloaded config", "file", r.configPath)
+	}
+	r.report(err)
+	return err
+}
+
+func (r *reloader) apply() error {
+	cfg, err := config.LoadConfigWithOptions(r.configPath, r.loadOpts)
+	if err != nil {
+		return err
+	}
+	// Everything that can fail is checked before changing anything, so a
+	// failed reload keeps the running configuration. The scraper checks its
+	// jobs before stopping any.
+	groups, err := r.rules.ParseGroups(cfg.RuleFiles, cfg.Global.EvaluationInterval)
+	if err != nil {
+		return fmt.Errorf("failed to load rules: %w", err)
+	}
+	if err := r.scraper.ApplyConfig(cfg); err != nil {
+		return fmt.Errorf("failed to apply scrape config: %w", err)
+	}
+	r.rules.ReplaceGroups(groups, cfg.Global.EvaluationInterval)
+	return nil
+}
+
+// report records whether the last reload succeeded and, if so, when
+func (r *reloader) report(err error) {
+	r.statusMu.Lock()
+	defer r.statusMu.Unlock()
+
+	r.successful = err == nil
+	if err == nil {
+		r.lastSuccess = time.Now()
+	}
+}
+
+// collectors expose the outcome of the last reload
+func (r *reloader) collectors() []instrument.Collector {
+	return []instrument.Collector{
+		instrument.NewGaugeFunc("promenitheus_config_last_reload_successful",
+			"Whether the last configuration reload attempt was successful.", func() float64 {
+				r.statusMu.Lock()
+				defer r.statusMu.Unlock()
+				if r.successful {
+					return 1
+				}
+				return 0
+			}),
+		instrument.NewGaugeFunc("promenitheus_config_last_reload_success_timestamp_seconds",
+			"Timestamp of the last successful configuration reload.", func() float64 {
+				r.statusMu.Lock()
+				defer r.statusMu.Unlock()
+				return float64(r.lastSuccess.Unix())
+			}),
+	}
+}
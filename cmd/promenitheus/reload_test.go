@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+	"github.com/Avinash7390/Promenitheus/pkg/rules"
+	"github.com/Avinash7390/Promenitheus/pkg/scraper"
+)
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	rulesPath := filepath.Join(dir, "rules.yml")
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(configPath, `global:
+  evaluation_interval: 1m
+scrape_configs:
+  - job_name: 'api'
+    static_configs:
+      - targets: ['localhost:8080']
+rule_files:
+  - rules.yml
+`)
+	write(rulesPath, `groups:
+  - name: example
+    rules:
+      - record: job:up:sum
+        expr: sum by (job) (up)
+`)
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	registry := metrics.NewMetricRegistry()
+	scr, err := scraper.NewScraper(cfg, registry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ruleManager := rules.NewManager(query.NewEngine(registry), registry)
+	ruleManager.SetEvaluationInterval(cfg.Global.EvaluationInterval)
+	if err := ruleManager.LoadGroups(cfg.RuleFiles); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	r := &reloader{configPath: configPath, scraper: scr, rules: ruleManager}
+
+	// A new job and interval with a broken rule file
+	write(configPath, `global:
+  evaluation_interval: 10s
+scrape_configs:
+  - job_name: 'api'
+    static_configs:
+      - targets: ['localhost:8080']
+  - job_name: 'web'
+    static_configs:
+      - targets: ['localhost:8081']
+rule_files:
+  - rules.yml
+`)
+	write(rulesPath, `groups:
+  - name: example
+    rules:
+      - record: job:up:sum
+        expr: sum by (job) (up
+`)
+
+	t.Run("Broken rule file changes nothing", func(t *testing.T) {
+		groups := ruleManager.Groups()
+		if err := r.reload(); err == nil {
+			t.Fatal("Expected reload to fail")
+		}
+
+		if targets := scr.Targets(); len(targets) != 1 || targets[0].Job != "api" {
+			t.Errorf("Expected only the api job to be scraped, got %v", targets)
+		}
+		if got := ruleManager.Groups(); len(got) != 1 || got[0] != groups[0] {
+			t.Errorf("Expected rule groups to be kept, got %v", got)
+		}
+		if r.successful {
+			t.Error("Expected reload to be reported as failed")
+		}
+	})
+
+	t.Run("Fixed rule file applies everything", func(t *testing.T) {
+		write(rulesPath, `groups:
+  - name: example
+    rules:
+      - record: job:up:sum
+        expr: sum by (job) (up)
+`)
+		if err := r.reload(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if targets := scr.Targets(); len(targets) != 2 {
+			t.Errorf("Expected api and web jobs to be scraped, got %v", targets)
+		}
+		if groups := ruleManager.Groups(); len(groups) != 1 || groups[0].Interval() != 10*time.Second {
+			t.Errorf("Expected rule group evaluated every 10s, got %v", groups)
+		}
+		if !r.successful {
+			t.Error("Expected reload to be reported as successful")
+		}
+	})
+}
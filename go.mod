@@ -3,8 +3,10 @@ module github.com/Avinash7390/Promenitheus
 go 1.24.11
 
 require (
+	github.com/golang/snappy v1.0.0
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.27.3
 	github.com/soheilhy/cmux v0.1.5
+	golang.org/x/crypto v0.46.0
 	google.golang.org/genproto/googleapis/api v0.0.0-20251029180050-ab9386a59fda
 	google.golang.org/grpc v1.78.0
 	google.golang.org/protobuf v1.36.11
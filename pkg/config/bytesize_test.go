@@ -0,0 +1,35 @@
+package config
+
+import "testing"
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ByteSize
+	}{
+		{"0", 0},
+		{"1024", 1024},
+		{"100B", 100},
+		{"512KB", 512 << 10},
+		{"10MB", 10 << 20},
+		{"2GiB", 2 << 30},
+		{" 1 MiB ", 1 << 20},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.input)
+		if err != nil {
+			t.Errorf("ParseByteSize(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseByteSize(%q) = %d, expected %d", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "MB", "ten", "-5", "1.5MB"} {
+		if _, err := ParseByteSize(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"reflect"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -12,20 +16,253 @@ import (
 type Config struct {
 	Global        GlobalConfig   `yaml:"global"`
 	ScrapeConfigs []ScrapeConfig `yaml:"scrape_configs"`
+
+	// ScrapeConfigFiles lists glob patterns of files whose scrape_configs
+	// are added to ScrapeConfigs. Relative paths are resolved against the
+	// directory of the config file.
+	ScrapeConfigFiles []string `yaml:"scrape_config_files,omitempty"`
+
+	Alerting AlertingConfig `yaml:"alerting,omitempty"`
+
+	// RuleFiles lists glob patterns of rule files. Relative paths are
+	// resolved against the directory of the config file.
+	RuleFiles []string `yaml:"rule_files,omitempty"`
+
+	RemoteWriteConfigs []RemoteWriteConfig `yaml:"remote_write,omitempty"`
+	RemoteReadConfigs  []RemoteReadConfig  `yaml:"remote_read,omitempty"`
 }
 
 // GlobalConfig contains global settings
 type GlobalConfig struct {
 	ScrapeInterval time.Duration `yaml:"scrape_interval"`
 	ScrapeTimeout  time.Duration `yaml:"scrape_timeout"`
+
+	// EvaluationInterval is how often rule groups without their own
+	// interval are evaluated
+	EvaluationInterval time.Duration `yaml:"evaluation_interval,omitempty"`
+
+	// ExternalLabels are added to series and alerts leaving this server
+	// through federation, remote write and alert notifications, unless
+	// they already have a label of the same name
+	ExternalLabels map[string]string `yaml:"external_labels,omitempty"`
+
+	// QueryLogFile is a file every query run through the API is appended
+	// to as a JSON line
+	QueryLogFile string `yaml:"query_log_file,omitempty"`
+
+	// ScrapeFailureLogFile is the default file failed scrapes are appended
+	// to as JSON lines
+	ScrapeFailureLogFile string `yaml:"scrape_failure_log_file,omitempty"`
 }
 
 // ScrapeConfig defines a scrape job
 type ScrapeConfig struct {
-	JobName       string          `yaml:"job_name"`
-	ScrapeInterval time.Duration  `yaml:"scrape_interval,omitempty"`
-	ScrapeTimeout  time.Duration  `yaml:"scrape_timeout,omitempty"`
-	StaticConfigs []StaticConfig  `yaml:"static_configs"`
+	JobName        string        `yaml:"job_name"`
+	ScrapeInterval time.Duration `yaml:"scrape_interval,omitempty"`
+	ScrapeTimeout  time.Duration `yaml:"scrape_timeout,omitempty"`
+
+	// HonorLabels keeps labels exposed by the target when they conflict with
+	// target labels; otherwise conflicting labels are renamed to exported_<name>
+	HonorLabels bool `yaml:"honor_labels,omitempty"`
+	// HonorTimestamps uses timestamps from the exposition instead of the
+	// scrape time
+	HonorTimestamps bool `yaml:"honor_timestamps,omitempty"`
+
+	// MetricsPath is the HTTP path scraped on each target, /metrics by
+	// default. Params are added to the URL as query parameters, e.g.
+	// match[] selectors when scraping /federate.
+	MetricsPath string              `yaml:"metrics_path,omitempty"`
+	Params      map[string][]string `yaml:"params,omitempty"`
+
+	Scheme        string         `yaml:"scheme,omitempty"`
+	TLSConfig     TLSConfig      `yaml:"tls_config,omitempty"`
+	BasicAuth     *BasicAuth     `yaml:"basic_auth,omitempty"`
+	Authorization *Authorization `yaml:"authorization,omitempty"`
+	ProxyURL      string         `yaml:"proxy_url,omitempty"`
+
+	// Limits applied to each scrape. Zero means no limit.
+	SampleLimit           int      `yaml:"sample_limit,omitempty"`
+	LabelLimit            int      `yaml:"label_limit,omitempty"`
+	LabelNameLengthLimit  int      `yaml:"label_name_length_limit,omitempty"`
+	LabelValueLengthLimit int      `yaml:"label_value_length_limit,omitempty"`
+	BodySizeLimit         ByteSize `yaml:"body_size_limit,omitempty"`
+
+	// ScrapeFailureLogFile is a file failed scrapes of the job's targets
+	// are appended to as JSON lines, the global one by default
+	ScrapeFailureLogFile string `yaml:"scrape_failure_log_file,omitempty"`
+
+	StaticConfigs []StaticConfig `yaml:"static_configs"`
+}
+
+// TLSConfig configures TLS for scrape requests
+type TLSConfig struct {
+	CAFile             string `yaml:"ca_file,omitempty"`
+	CertFile           string `yaml:"cert_file,omitempty"`
+	KeyFile            string `yaml:"key_file,omitempty"`
+	ServerName         string `yaml:"server_name,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+}
+
+// BasicAuth configures HTTP basic authentication for scrape requests
+type BasicAuth struct {
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password,omitempty"`
+	PasswordFile string `yaml:"password_file,omitempty"`
+}
+
+// Authorization configures the Authorization header for scrape requests
+type Authorization struct {
+	Type            string `yaml:"type,omitempty"`
+	Credentials     string `yaml:"credentials,omitempty"`
+	CredentialsFile string `yaml:"credentials_file,omitempty"`
+}
+
+// AlertingConfig configures where and how alerts are sent
+type AlertingConfig struct {
+	Alertmanagers []AlertmanagerConfig `yaml:"alertmanagers,omitempty"`
+
+	// QueueCapacity is the maximum number of alerts waiting to be sent.
+	// The oldest alerts are dropped when the queue is full. Zero uses
+	// notifier.DefaultQueueCapacity.
+	QueueCapacity int `yaml:"queue_capacity,omitempty"`
+	// ResendDelay is how often still-firing alerts are sent again
+	ResendDelay time.Duration `yaml:"resend_delay,omitempty"`
+
+	// Routing enables the built-in notifier that groups alerts and sends
+	// them to receivers without an external Alertmanager
+	Routing *RoutingConfig `yaml:"routing,omitempty"`
+}
+
+// RoutingConfig configures the built-in alert notifier
+type RoutingConfig struct {
+	// Receiver is the name of the receiver notified for all alerts
+	Receiver string `yaml:"receiver"`
+	// GroupBy lists the labels alerts are grouped by into one notification.
+	// The special value '...' groups by all labels.
+	GroupBy []string `yaml:"group_by,omitempty"`
+	// GroupWait is how long to wait before notifying about a new group
+	GroupWait time.Duration `yaml:"group_wait,omitempty"`
+	// GroupInterval is how long to wait before notifying about changes to
+	// a group that was already notified
+	GroupInterval time.Duration `yaml:"group_interval,omitempty"`
+	// RepeatInterval is how long to wait before repeating an unchanged
+	// notification
+	RepeatInterval time.Duration `yaml:"repeat_interval,omitempty"`
+
+	Receivers []ReceiverConfig `yaml:"receivers"`
+	Silences  []SilenceConfig  `yaml:"silences,omitempty"`
+}
+
+// ReceiverConfig is a named set of notification integrations
+type ReceiverConfig struct {
+	Name           string          `yaml:"name"`
+	WebhookConfigs []WebhookConfig `yaml:"webhook_configs,omitempty"`
+	EmailConfigs   []EmailConfig   `yaml:"email_configs,omitempty"`
+}
+
+// WebhookConfig sends notifications as JSON to an HTTP endpoint
+type WebhookConfig struct {
+	URL          string `yaml:"url"`
+	SendResolved bool   `yaml:"send_resolved,omitempty"`
+}
+
+// EmailConfig sends notifications by email through an SMTP server
+type EmailConfig struct {
+	To           string `yaml:"to"`
+	From         string `yaml:"from"`
+	Smarthost    string `yaml:"smarthost"`
+	AuthUsername string `yaml:"auth_username,omitempty"`
+	AuthPassword string `yaml:"auth_password,omitempty"`
+	SendResolved bool   `yaml:"send_resolved,omitempty"`
+}
+
+// SilenceConfig mutes notifications for alerts matching all matchers, such
+// as 'alertname="InstanceDown"' or 'job=~"batch.*"'. A zero start or end
+// leaves the silence open on that side.
+type SilenceConfig struct {
+	Matchers []string  `yaml:"matchers"`
+	StartsAt time.Time `yaml:"starts_at,omitempty"`
+	EndsAt   time.Time `yaml:"ends_at,omitempty"`
+	Comment  string    `yaml:"comment,omitempty"`
+}
+
+// AlertmanagerConfig defines a set of Alertmanagers receiving alerts
+type AlertmanagerConfig struct {
+	Scheme        string         `yaml:"scheme,omitempty"`
+	PathPrefix    string         `yaml:"path_prefix,omitempty"`
+	Timeout       time.Duration  `yaml:"timeout,omitempty"`
+	StaticConfigs []StaticConfig `yaml:"static_configs"`
+}
+
+// RemoteWriteConfig configures sending samples to a remote write endpoint
+type RemoteWriteConfig struct {
+	URL           string            `yaml:"url"`
+	Name          string            `yaml:"name,omitempty"`
+	RemoteTimeout time.Duration     `yaml:"remote_timeout,omitempty"`
+	Headers       map[string]string `yaml:"headers,omitempty"`
+	BasicAuth     *BasicAuth        `yaml:"basic_auth,omitempty"`
+	Authorization *Authorization    `yaml:"authorization,omitempty"`
+
+	// WriteRelabelConfigs are applied to every sample before it is sent
+	WriteRelabelConfigs []RelabelConfig `yaml:"write_relabel_configs,omitempty"`
+	QueueConfig         QueueConfig     `yaml:"queue_config,omitempty"`
+}
+
+// RemoteReadConfig configures reading samples from a remote read endpoint
+type RemoteReadConfig struct {
+	URL           string            `yaml:"url"`
+	Name          string            `yaml:"name,omitempty"`
+	RemoteTimeout time.Duration     `yaml:"remote_timeout,omitempty"`
+	Headers       map[string]string `yaml:"headers,omitempty"`
+	BasicAuth     *BasicAuth        `yaml:"basic_auth,omitempty"`
+	Authorization *Authorization    `yaml:"authorization,omitempty"`
+
+	// RequiredMatchers are equality matchers a query must contain for the
+	// endpoint to be read
+	RequiredMatchers map[string]string `yaml:"required_matchers,omitempty"`
+	// ReadRecent also reads the endpoint for time ranges covered by local
+	// storage
+	ReadRecent bool `yaml:"read_recent,omitempty"`
+}
+
+// QueueConfig tunes the sharded queue of a remote write endpoint
+type QueueConfig struct {
+	// Capacity is the number of samples buffered per shard. Samples are
+	// dropped when a shard is full.
+	Capacity          int           `yaml:"capacity,omitempty"`
+	MinShards         int           `yaml:"min_shards,omitempty"`
+	MaxShards         int           `yaml:"max_shards,omitempty"`
+	MaxSamplesPerSend int           `yaml:"max_samples_per_send,omitempty"`
+	BatchSendDeadline time.Duration `yaml:"batch_send_deadline,omitempty"`
+	MinBackoff        time.Duration `yaml:"min_backoff,omitempty"`
+	MaxBackoff        time.Duration `yaml:"max_backoff,omitempty"`
+}
+
+// RelabelConfig rewrites, keeps or drops label sets
+type RelabelConfig struct {
+	SourceLabels []string `yaml:"source_labels,flow,omitempty"`
+	Separator    string   `yaml:"separator,omitempty"`
+	Regex        string   `yaml:"regex,omitempty"`
+	Modulus      uint64   `yaml:"modulus,omitempty"`
+	TargetLabel  string   `yaml:"target_label,omitempty"`
+	Replacement  string   `yaml:"replacement,omitempty"`
+	Action       string   `yaml:"action,omitempty"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, applying the defaults of
+// fields that may be set to an empty value
+func (c *RelabelConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain RelabelConfig
+	*c = RelabelConfig{
+		Separator:   ";",
+		Regex:       "(.*)",
+		Replacement: "$1",
+		Action:      "replace",
+	}
+	if err := checkKnownFields(value, c); err != nil {
+		return err
+	}
+	return value.Decode((*plain)(c))
 }
 
 // StaticConfig defines static targets
@@ -34,24 +271,81 @@ type StaticConfig struct {
 	Labels  map[string]string `yaml:"labels,omitempty"`
 }
 
-// LoadConfig loads configuration from a YAML file
+// scrapeConfigFile is the content of a file listed in scrape_config_files
+type scrapeConfigFile struct {
+	ScrapeConfigs []ScrapeConfig `yaml:"scrape_configs"`
+}
+
+// LoadOptions changes how a config file is read
+type LoadOptions struct {
+	// ExpandEnv replaces ${VAR} references in the values of the config file
+	// and included files with the value of the environment variable VAR.
+	// $$ is a literal $.
+	ExpandEnv bool
+}
+
+// LoadConfig loads configuration from a YAML file. Unknown keys and
+// settings that cannot work, such as duplicate job names or malformed
+// targets, are errors; all of them are reported at once with their line.
 func LoadConfig(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
+	return LoadConfigWithOptions(path, LoadOptions{})
+}
+
+// LoadConfigWithOptions loads configuration like LoadConfig with the given
+// options
+func LoadConfigWithOptions(path string, opts LoadOptions) (*Config, error) {
+	var config Config
+	root, err := decodeFile(path, &config, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, fmt.Errorf("failed to load config file: %w", err)
 	}
 
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	jobs := make([]jobSource, 0, len(config.ScrapeConfigs))
+	for i := range config.ScrapeConfigs {
+		jobs = append(jobs, jobSource{source: source{root: root}, path: []any{"scrape_configs", i}})
+	}
+
+	// Add the scrape configs of included files, each file once
+	seen := make(map[string]bool)
+	for i, pattern := range config.ScrapeConfigFiles {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+			config.ScrapeConfigFiles[i] = pattern
+		}
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scrape config file pattern %q: %w", pattern, err)
+		}
+
+		for _, file := range files {
+			if seen[file] {
+				continue
+			}
+			seen[file] = true
+
+			var included scrapeConfigFile
+			fileRoot, err := decodeFile(file, &included, opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load scrape config file: %w", err)
+			}
+			for j, sc := range included.ScrapeConfigs {
+				config.ScrapeConfigs = append(config.ScrapeConfigs, sc)
+				jobs = append(jobs, jobSource{source: source{file: file, root: fileRoot}, path: []any{"scrape_configs", j}})
+			}
+		}
 	}
 
 	// Set defaults
 	if config.Global.ScrapeInterval == 0 {
 		config.Global.ScrapeInterval = 15 * time.Second
 	}
+	// The default timeout is capped at the interval, so only an explicit
+	// timeout can exceed it
 	if config.Global.ScrapeTimeout == 0 {
-		config.Global.ScrapeTimeout = 10 * time.Second
+		config.Global.ScrapeTimeout = min(10*time.Second, config.Global.ScrapeInterval)
+	}
+	if config.Global.EvaluationInterval == 0 {
+		config.Global.EvaluationInterval = time.Minute
 	}
 
 	// Apply global defaults to scrape configs
@@ -59,10 +353,139 @@ func LoadConfig(path string) (*Config, error) {
 		if config.ScrapeConfigs[i].ScrapeInterval == 0 {
 			config.ScrapeConfigs[i].ScrapeInterval = config.Global.ScrapeInterval
 		}
+		// An inherited timeout is capped at the interval of the job
 		if config.ScrapeConfigs[i].ScrapeTimeout == 0 {
-			config.ScrapeConfigs[i].ScrapeTimeout = config.Global.ScrapeTimeout
+			config.ScrapeConfigs[i].ScrapeTimeout = min(config.Global.ScrapeTimeout, config.ScrapeConfigs[i].ScrapeInterval)
+		}
+		if config.ScrapeConfigs[i].ScrapeFailureLogFile == "" {
+			config.ScrapeConfigs[i].ScrapeFailureLogFile = config.Global.ScrapeFailureLogFile
+		}
+		if config.ScrapeConfigs[i].Scheme == "" {
+			config.ScrapeConfigs[i].Scheme = "http"
+		}
+		if config.ScrapeConfigs[i].MetricsPath == "" {
+			config.ScrapeConfigs[i].MetricsPath = "/metrics"
+		}
+		if auth := config.ScrapeConfigs[i].Authorization; auth != nil && auth.Type == "" {
+			auth.Type = "Bearer"
+		}
+	}
+
+	// Apply alerting defaults
+	if config.Alerting.ResendDelay == 0 {
+		config.Alerting.ResendDelay = time.Minute
+	}
+	for i := range config.Alerting.Alertmanagers {
+		if config.Alerting.Alertmanagers[i].Scheme == "" {
+			config.Alerting.Alertmanagers[i].Scheme = "http"
+		}
+		if config.Alerting.Alertmanagers[i].Timeout == 0 {
+			config.Alerting.Alertmanagers[i].Timeout = 10 * time.Second
+		}
+	}
+
+	if routing := config.Alerting.Routing; routing != nil {
+		if routing.GroupWait == 0 {
+			routing.GroupWait = 30 * time.Second
+		}
+		if routing.GroupInterval == 0 {
+			routing.GroupInterval = 5 * time.Minute
+		}
+		if routing.RepeatInterval == 0 {
+			routing.RepeatInterval = 4 * time.Hour
+		}
+	}
+
+	for i := range config.RemoteWriteConfigs {
+		rw := &config.RemoteWriteConfigs[i]
+		if rw.RemoteTimeout == 0 {
+			rw.RemoteTimeout = 30 * time.Second
+		}
+		if rw.Authorization != nil && rw.Authorization.Type == "" {
+			rw.Authorization.Type = "Bearer"
+		}
+
+		q := &rw.QueueConfig
+		if q.Capacity == 0 {
+			q.Capacity = 10000
+		}
+		if q.MinShards == 0 {
+			q.MinShards = 1
+		}
+		if q.MaxShards == 0 {
+			q.MaxShards = 50
+		}
+		if q.MaxSamplesPerSend == 0 {
+			q.MaxSamplesPerSend = 2000
+		}
+		if q.BatchSendDeadline == 0 {
+			q.BatchSendDeadline = 5 * time.Second
+		}
+		if q.MinBackoff == 0 {
+			q.MinBackoff = 30 * time.Millisecond
+		}
+		if q.MaxBackoff == 0 {
+			q.MaxBackoff = 5 * time.Second
+		}
+	}
+
+	for i := range config.RemoteReadConfigs {
+		rr := &config.RemoteReadConfigs[i]
+		if rr.RemoteTimeout == 0 {
+			rr.RemoteTimeout = time.Minute
+		}
+		if rr.Authorization != nil && rr.Authorization.Type == "" {
+			rr.Authorization.Type = "Bearer"
+		}
+	}
+
+	if err := config.validate(root, jobs); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	for i, pattern := range config.RuleFiles {
+		if !filepath.IsAbs(pattern) {
+			config.RuleFiles[i] = filepath.Join(filepath.Dir(path), pattern)
 		}
 	}
 
 	return &config, nil
 }
+
+// decodeFile strictly decodes a YAML file into v and returns its parsed
+// document for error positions
+func decodeFile(path string, v any, opts LoadOptions) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if opts.ExpandEnv {
+		if err := expandEnv(&root); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		// The expanded document is decoded from its nodes, which does not
+		// check for unknown keys like the strict decoder
+		if errs := unknownFields(&root, reflect.TypeOf(v)); len(errs) > 0 {
+			return nil, fmt.Errorf("%s: %w", path, &yaml.TypeError{Errors: errs})
+		}
+		if root.Kind != 0 {
+			if err := root.Decode(v); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		return &root, nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(v); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &root, nil
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -102,6 +104,10 @@ scrape_configs:
 			t.Errorf("Expected default global scrape_interval 15s, got %v", cfg.Global.ScrapeInterval)
 		}
 
+		if cfg.Global.EvaluationInterval != time.Minute {
+			t.Errorf("Expected default evaluation_interval 1m, got %v", cfg.Global.EvaluationInterval)
+		}
+
 		if cfg.Global.ScrapeTimeout != 10*time.Second {
 			t.Errorf("Expected default global scrape_timeout 10s, got %v", cfg.Global.ScrapeTimeout)
 		}
@@ -115,6 +121,324 @@ scrape_configs:
 		if scrapeConfig.ScrapeTimeout != 10*time.Second {
 			t.Errorf("Expected inherited scrape_timeout 10s, got %v", scrapeConfig.ScrapeTimeout)
 		}
+
+		if scrapeConfig.Scheme != "http" {
+			t.Errorf("Expected default scheme 'http', got '%s'", scrapeConfig.Scheme)
+		}
+		if scrapeConfig.MetricsPath != "/metrics" {
+			t.Errorf("Expected default metrics path '/metrics', got '%s'", scrapeConfig.MetricsPath)
+		}
+	})
+
+	t.Run("Load TLS and auth settings", func(t *testing.T) {
+		configContent := `scrape_configs:
+  - job_name: 'secure-job'
+    scheme: https
+    tls_config:
+      ca_file: /etc/ca.pem
+      cert_file: /etc/client.pem
+      key_file: /etc/client-key.pem
+      server_name: metrics.internal
+      insecure_skip_verify: true
+    basic_auth:
+      username: admin
+      password_file: /etc/password
+    proxy_url: http://proxy:3128
+    metrics_path: /federate
+    params:
+      'match[]':
+        - '{job="api"}'
+    static_configs:
+      - targets:
+          - 'localhost:8443'
+  - job_name: 'token-job'
+    authorization:
+      credentials_file: /etc/token
+    static_configs:
+      - targets:
+          - 'localhost:8444'
+`
+
+		tmpFile, err := os.CreateTemp("", "config-*.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write([]byte(configContent)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		cfg, err := LoadConfig(tmpFile.Name())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		scrapeConfig := cfg.ScrapeConfigs[0]
+		if scrapeConfig.Scheme != "https" {
+			t.Errorf("Expected scheme 'https', got '%s'", scrapeConfig.Scheme)
+		}
+		if scrapeConfig.MetricsPath != "/federate" || len(scrapeConfig.Params["match[]"]) != 1 {
+			t.Errorf("Expected federate path and params, got '%s' %v", scrapeConfig.MetricsPath, scrapeConfig.Params)
+		}
+
+		tlsConfig := scrapeConfig.TLSConfig
+		if tlsConfig.CAFile != "/etc/ca.pem" || tlsConfig.CertFile != "/etc/client.pem" ||
+			tlsConfig.KeyFile != "/etc/client-key.pem" || tlsConfig.ServerName != "metrics.internal" ||
+			!tlsConfig.InsecureSkipVerify {
+			t.Errorf("TLS config not parsed correctly: %+v", tlsConfig)
+		}
+
+		if scrapeConfig.BasicAuth == nil || scrapeConfig.BasicAuth.Username != "admin" ||
+			scrapeConfig.BasicAuth.PasswordFile != "/etc/password" {
+			t.Errorf("Basic auth not parsed correctly: %+v", scrapeConfig.BasicAuth)
+		}
+
+		if scrapeConfig.ProxyURL != "http://proxy:3128" {
+			t.Errorf("Expected proxy_url 'http://proxy:3128', got '%s'", scrapeConfig.ProxyURL)
+		}
+
+		authorization := cfg.ScrapeConfigs[1].Authorization
+		if authorization == nil || authorization.CredentialsFile != "/etc/token" {
+			t.Fatalf("Authorization not parsed correctly: %+v", authorization)
+		}
+
+		if authorization.Type != "Bearer" {
+			t.Errorf("Expected default authorization type 'Bearer', got '%s'", authorization.Type)
+		}
+	})
+
+	t.Run("Resolve rule files", func(t *testing.T) {
+		configContent := `rule_files:
+  - 'rules/*.yml'
+  - '/etc/promenitheus/alerts.yml'
+`
+
+		tmpFile, err := os.CreateTemp("", "config-*.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write([]byte(configContent)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		cfg, err := LoadConfig(tmpFile.Name())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(cfg.RuleFiles) != 2 {
+			t.Fatalf("Expected 2 rule files, got %d", len(cfg.RuleFiles))
+		}
+
+		expected := filepath.Join(filepath.Dir(tmpFile.Name()), "rules/*.yml")
+		if cfg.RuleFiles[0] != expected {
+			t.Errorf("Expected relative rule file to resolve to '%s', got '%s'", expected, cfg.RuleFiles[0])
+		}
+
+		if cfg.RuleFiles[1] != "/etc/promenitheus/alerts.yml" {
+			t.Errorf("Expected absolute rule file to be kept, got '%s'", cfg.RuleFiles[1])
+		}
+	})
+
+	t.Run("Load alerting settings", func(t *testing.T) {
+		configContent := `alerting:
+  resend_delay: 30s
+  alertmanagers:
+    - path_prefix: /am
+      static_configs:
+        - targets:
+            - 'localhost:9093'
+`
+
+		tmpFile, err := os.CreateTemp("", "config-*.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write([]byte(configContent)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		cfg, err := LoadConfig(tmpFile.Name())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if cfg.Alerting.ResendDelay != 30*time.Second {
+			t.Errorf("Expected resend_delay 30s, got %v", cfg.Alerting.ResendDelay)
+		}
+
+		if cfg.Alerting.QueueCapacity != 0 {
+			t.Errorf("Expected queue_capacity to be left to the notifier, got %d", cfg.Alerting.QueueCapacity)
+		}
+
+		if len(cfg.Alerting.Alertmanagers) != 1 {
+			t.Fatalf("Expected 1 alertmanager config, got %d", len(cfg.Alerting.Alertmanagers))
+		}
+
+		am := cfg.Alerting.Alertmanagers[0]
+		if am.Scheme != "http" || am.Timeout != 10*time.Second || am.PathPrefix != "/am" {
+			t.Errorf("Alertmanager config not parsed correctly: %+v", am)
+		}
+	})
+
+	t.Run("Load routing settings", func(t *testing.T) {
+		configContent := `alerting:
+  routing:
+    receiver: team
+    group_by: [alertname, job]
+    group_wait: 10s
+    receivers:
+      - name: team
+        webhook_configs:
+          - url: http://localhost:5001/hook
+            send_resolved: true
+        email_configs:
+          - to: ops@example.com
+            from: promenitheus@example.com
+            smarthost: localhost:25
+    silences:
+      - matchers: ['alertname="Maintenance"']
+        ends_at: 2030-01-01T00:00:00Z
+`
+
+		tmpFile, err := os.CreateTemp("", "config-*.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write([]byte(configContent)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		cfg, err := LoadConfig(tmpFile.Name())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		routing := cfg.Alerting.Routing
+		if routing == nil {
+			t.Fatal("Expected routing config")
+		}
+
+		if routing.GroupWait != 10*time.Second || routing.GroupInterval != 5*time.Minute || routing.RepeatInterval != 4*time.Hour {
+			t.Errorf("Routing intervals not set correctly: %+v", routing)
+		}
+
+		if len(routing.Receivers) != 1 || len(routing.Receivers[0].WebhookConfigs) != 1 || len(routing.Receivers[0].EmailConfigs) != 1 {
+			t.Fatalf("Receivers not parsed correctly: %+v", routing.Receivers)
+		}
+
+		if !routing.Receivers[0].WebhookConfigs[0].SendResolved {
+			t.Error("Expected send_resolved to be parsed")
+		}
+
+		if len(routing.Silences) != 1 || !routing.Silences[0].EndsAt.Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Silences not parsed correctly: %+v", routing.Silences)
+		}
+	})
+
+	t.Run("Load remote write settings", func(t *testing.T) {
+		configContent := `remote_write:
+  - url: http://localhost:9201/write
+    queue_config:
+      max_shards: 10
+    write_relabel_configs:
+      - source_labels: [__name__]
+        regex: 'go_.*'
+        action: drop
+      - target_label: cluster
+        replacement: eu-1
+`
+
+		tmpFile, err := os.CreateTemp("", "config-*.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write([]byte(configContent)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		cfg, err := LoadConfig(tmpFile.Name())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(cfg.RemoteWriteConfigs) != 1 {
+			t.Fatalf("Expected 1 remote write config, got %d", len(cfg.RemoteWriteConfigs))
+		}
+		rw := cfg.RemoteWriteConfigs[0]
+		if rw.RemoteTimeout != 30*time.Second {
+			t.Errorf("Expected default remote timeout 30s, got %v", rw.RemoteTimeout)
+		}
+		if rw.QueueConfig.MaxShards != 10 || rw.QueueConfig.MinShards != 1 || rw.QueueConfig.MaxSamplesPerSend != 2000 {
+			t.Errorf("Queue config not set correctly: %+v", rw.QueueConfig)
+		}
+
+		if len(rw.WriteRelabelConfigs) != 2 {
+			t.Fatalf("Expected 2 relabel configs, got %d", len(rw.WriteRelabelConfigs))
+		}
+		drop := rw.WriteRelabelConfigs[0]
+		if drop.Action != "drop" || drop.Regex != "go_.*" || drop.Separator != ";" {
+			t.Errorf("Relabel config not parsed correctly: %+v", drop)
+		}
+		replace := rw.WriteRelabelConfigs[1]
+		if replace.Action != "replace" || replace.Regex != "(.*)" || replace.Replacement != "eu-1" {
+			t.Errorf("Relabel defaults not applied: %+v", replace)
+		}
+	})
+
+	t.Run("Load remote read settings", func(t *testing.T) {
+		configContent := `remote_read:
+  - url: http://localhost:9201/read
+    read_recent: true
+    required_matchers:
+      job: archive
+    authorization:
+      credentials: secret
+`
+
+		tmpFile, err := os.CreateTemp("", "config-*.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write([]byte(configContent)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		cfg, err := LoadConfig(tmpFile.Name())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(cfg.RemoteReadConfigs) != 1 {
+			t.Fatalf("Expected 1 remote read config, got %d", len(cfg.RemoteReadConfigs))
+		}
+		rr := cfg.RemoteReadConfigs[0]
+		if rr.RemoteTimeout != time.Minute {
+			t.Errorf("Expected default remote timeout 1m, got %v", rr.RemoteTimeout)
+		}
+		if !rr.ReadRecent || rr.RequiredMatchers["job"] != "archive" {
+			t.Errorf("Remote read config not parsed correctly: %+v", rr)
+		}
+		if rr.Authorization.Type != "Bearer" {
+			t.Errorf("Expected default authorization type Bearer, got %q", rr.Authorization.Type)
+		}
 	})
 
 	t.Run("Invalid file path", func(t *testing.T) {
@@ -143,4 +467,482 @@ scrape_configs:
 			t.Error("Expected error for invalid YAML")
 		}
 	})
+
+	t.Run("Reject unknown fields", func(t *testing.T) {
+		configContent := `scrape_configs:
+  - job_name: 'test-job'
+    scrape_intervall: 10s
+    static_configs:
+      - targets: ['localhost:8080']
+remote_write:
+  - url: http://localhost:9201/write
+    write_relabel_configs:
+      - source_label: [__name__]
+`
+
+		tmpFile, err := os.CreateTemp("", "config-*.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write([]byte(configContent)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		_, err = LoadConfig(tmpFile.Name())
+		if err == nil {
+			t.Fatal("Expected error for unknown fields")
+		}
+		for _, expected := range []string{
+			"line 3: field scrape_intervall not found",
+			"line 9: field source_label not found",
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("Expected error to contain %q, got: %v", expected, err)
+			}
+		}
+	})
+
+	t.Run("Report all validation problems", func(t *testing.T) {
+		configContent := `global:
+  scrape_interval: 10s
+scrape_configs:
+  - job_name: 'api'
+    static_configs:
+      - targets: ['localhost:8080']
+  - job_name: 'api'
+    scrape_interval: 5s
+    scrape_timeout: 10s
+    static_configs:
+      - targets: []
+  - job_name: 'web'
+    static_configs:
+      - targets:
+          - 'localhost'
+          - 'http://localhost:8080'
+          - 'localhost:99999'
+  - job_name: 'empty'
+  - static_configs:
+      - targets: ['localhost:8080']
+`
+
+		tmpFile, err := os.CreateTemp("", "config-*.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write([]byte(configContent)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		_, err = LoadConfig(tmpFile.Name())
+		if err == nil {
+			t.Fatal("Expected validation error")
+		}
+
+		expected := []string{
+			`line 7: job "api": duplicate job_name, first defined on line 4`,
+			`line 9: job "api": scrape_timeout 10s is greater than scrape_interval 5s`,
+			`line 11: job "api": static config 1 has no targets`,
+			`line 15: job "web": invalid target "localhost", must be host:port`,
+			`line 16: job "web": invalid target "http://localhost:8080", must be host:port`,
+			`line 17: job "web": invalid target "localhost:99999", port must be a number between 1 and 65535`,
+			`line 18: job "empty" has no static_configs`,
+			`line 19: scrape config 5 has no job_name`,
+		}
+		for _, e := range expected {
+			if !strings.Contains(err.Error(), e) {
+				t.Errorf("Expected error to contain %q, got:\n%v", e, err)
+			}
+		}
+		if !strings.Contains(err.Error(), "found 8 problem(s)") {
+			t.Errorf("Expected 8 problems, got:\n%v", err)
+		}
+	})
+
+	t.Run("Cap inherited timeout at job interval", func(t *testing.T) {
+		configContent := `scrape_configs:
+  - job_name: 'fast'
+    scrape_interval: 5s
+    static_configs:
+      - targets: ['localhost:8080']
+`
+
+		tmpFile, err := os.CreateTemp("", "config-*.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write([]byte(configContent)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		cfg, err := LoadConfig(tmpFile.Name())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.ScrapeConfigs[0].ScrapeTimeout != 5*time.Second {
+			t.Errorf("Expected scrape_timeout 5s, got %v", cfg.ScrapeConfigs[0].ScrapeTimeout)
+		}
+	})
+
+	t.Run("Cap default global timeout at global interval", func(t *testing.T) {
+		configContent := `global:
+  scrape_interval: 5s
+scrape_configs:
+  - job_name: 'api'
+    static_configs:
+      - targets: ['localhost:8080']
+`
+
+		tmpFile, err := os.CreateTemp("", "config-*.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write([]byte(configContent)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		cfg, err := LoadConfig(tmpFile.Name())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.Global.ScrapeTimeout != 5*time.Second {
+			t.Errorf("Expected global scrape_timeout 5s, got %v", cfg.Global.ScrapeTimeout)
+		}
+		if cfg.ScrapeConfigs[0].ScrapeTimeout != 5*time.Second {
+			t.Errorf("Expected scrape_timeout 5s, got %v", cfg.ScrapeConfigs[0].ScrapeTimeout)
+		}
+	})
+
+	t.Run("Expand environment variables", func(t *testing.T) {
+		t.Setenv("PROMENITHEUS_TEST_CLUSTER", "eu-1")
+		configContent := `scrape_configs:
+  - job_name: 'api-${PROMENITHEUS_TEST_CLUSTER}'
+    static_configs:
+      - targets: ['api.${PROMENITHEUS_TEST_CLUSTER}.internal:8080']
+        labels:
+          price: '$$5'
+remote_write:
+  - url: http://localhost:9201/write
+    write_relabel_configs:
+      - target_label: instance
+        replacement: '${1}'
+`
+
+		tmpFile, err := os.CreateTemp("", "config-*.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write([]byte(configContent)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		cfg, err := LoadConfigWithOptions(tmpFile.Name(), LoadOptions{ExpandEnv: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		sc := cfg.ScrapeConfigs[0]
+		if sc.JobName != "api-eu-1" || sc.StaticConfigs[0].Targets[0] != "api.eu-1.internal:8080" {
+			t.Errorf("Expected expanded job and target, got %q and %q", sc.JobName, sc.StaticConfigs[0].Targets[0])
+		}
+		if price := sc.StaticConfigs[0].Labels["price"]; price != "$5" {
+			t.Errorf("Expected escaped $ to be kept, got %q", price)
+		}
+		if r := cfg.RemoteWriteConfigs[0].WriteRelabelConfigs[0].Replacement; r != "${1}" {
+			t.Errorf("Expected regex reference to be kept, got %q", r)
+		}
+
+		// Without the option the config is used as written
+		cfg, err = LoadConfig(tmpFile.Name())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.ScrapeConfigs[0].JobName != "api-${PROMENITHEUS_TEST_CLUSTER}" {
+			t.Errorf("Expected no expansion by default, got %q", cfg.ScrapeConfigs[0].JobName)
+		}
+
+		os.Unsetenv("PROMENITHEUS_TEST_CLUSTER")
+		_, err = LoadConfigWithOptions(tmpFile.Name(), LoadOptions{ExpandEnv: true})
+		if err == nil || !strings.Contains(err.Error(), "line 2: environment variable PROMENITHEUS_TEST_CLUSTER is not set") {
+			t.Errorf("Expected error for unset variable, got %v", err)
+		}
+	})
+
+	t.Run("Expanded values are taken literally", func(t *testing.T) {
+		t.Setenv("PROMENITHEUS_TEST_PASSWORD", "s3cr#t: 'x\"\ny")
+		t.Setenv("PROMENITHEUS_TEST_LIMIT", "500")
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := `# Credentials come from ${PROMENITHEUS_TEST_UNSET}
+scrape_configs:
+  - job_name: 'api'
+    sample_limit: ${PROMENITHEUS_TEST_LIMIT}
+    basic_auth:
+      username: admin
+      password: ${PROMENITHEUS_TEST_PASSWORD}
+    static_configs:
+      - targets: ['localhost:8080']
+        labels:
+          password: "${PROMENITHEUS_TEST_PASSWORD}"
+`
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := LoadConfigWithOptions(path, LoadOptions{ExpandEnv: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		sc := cfg.ScrapeConfigs[0]
+		expected := "s3cr#t: 'x\"\ny"
+		if sc.BasicAuth == nil || sc.BasicAuth.Password != expected {
+			t.Errorf("Expected password %q, got %+v", expected, sc.BasicAuth)
+		}
+		if got := sc.StaticConfigs[0].Labels["password"]; got != expected {
+			t.Errorf("Expected quoted label value %q, got %q", expected, got)
+		}
+		if sc.SampleLimit != 500 {
+			t.Errorf("Expected sample_limit 500, got %d", sc.SampleLimit)
+		}
+	})
+
+	t.Run("Expanded config rejects unknown fields", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := `scrape_configs:
+  - job_name: 'api'
+    static_configs:
+      - targets: ['localhost:8080']
+        lables:
+          env: prod
+`
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := LoadConfigWithOptions(path, LoadOptions{ExpandEnv: true})
+		if err == nil || !strings.Contains(err.Error(), "line 5: field lables not found") {
+			t.Errorf("Expected unknown field error on line 5, got %v", err)
+		}
+	})
+
+	t.Run("Include scrape config files", func(t *testing.T) {
+		dir := t.TempDir()
+		write := func(name, content string) {
+			t.Helper()
+			if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		write("config.yaml", `scrape_configs:
+  - job_name: 'main'
+    static_configs:
+      - targets: ['localhost:9090']
+scrape_config_files:
+  - 'teams/*.yml'
+  - 'teams/api.yml'
+`)
+		write("teams/api.yml", `scrape_configs:
+  - job_name: 'api'
+    static_configs:
+      - targets: ['api:8080']
+`)
+		write("teams/web.yml", `scrape_configs:
+  - job_name: 'web'
+    scrape_interval: 5s
+    static_configs:
+      - targets: ['web:8080']
+`)
+
+		cfg, err := LoadConfig(filepath.Join(dir, "config.yaml"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var names []string
+		for _, sc := range cfg.ScrapeConfigs {
+			names = append(names, sc.JobName)
+		}
+		if strings.Join(names, ",") != "main,api,web" {
+			t.Errorf("Expected jobs main,api,web, got %v", names)
+		}
+		if web := cfg.ScrapeConfigs[2]; web.ScrapeInterval != 5*time.Second || web.ScrapeTimeout != 5*time.Second || web.MetricsPath != "/metrics" {
+			t.Errorf("Expected defaults applied to included job, got %+v", web)
+		}
+
+		// Jobs of included files must not clash with other jobs
+		write("teams/web.yml", `scrape_configs:
+  - job_name: 'web'
+    static_configs:
+      - targets: ['web:8080']
+  - job_name: 'main'
+    static_configs:
+      - targets: ['web']
+`)
+		_, err = LoadConfig(filepath.Join(dir, "config.yaml"))
+		if err == nil {
+			t.Fatal("Expected validation error")
+		}
+		webFile := filepath.Join(dir, "teams/web.yml")
+		for _, expected := range []string{
+			webFile + ` line 5: job "main": duplicate job_name, first defined on line 2`,
+			webFile + ` line 7: job "main": invalid target "web", must be host:port`,
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("Expected error to contain %q, got:\n%v", expected, err)
+			}
+		}
+
+		write("teams/web.yml", "scrape_configs:\n  - job_nam: 'web'\n")
+		if _, err = LoadConfig(filepath.Join(dir, "config.yaml")); err == nil || !strings.Contains(err.Error(), "field job_nam not found") {
+			t.Errorf("Expected unknown field in included file to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("Load global settings", func(t *testing.T) {
+		configContent := `global:
+  evaluation_interval: 30s
+  query_log_file: /var/log/promenitheus/queries.log
+  scrape_failure_log_file: /var/log/promenitheus/scrape-failures.log
+  external_labels:
+    cluster: eu-1
+    replica: a
+scrape_configs:
+  - job_name: 'inherits'
+    static_configs:
+      - targets: ['localhost:8080']
+  - job_name: 'own'
+    scrape_failure_log_file: /tmp/own.log
+    static_configs:
+      - targets: ['localhost:8081']
+`
+
+		tmpFile, err := os.CreateTemp("", "config-*.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write([]byte(configContent)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		cfg, err := LoadConfig(tmpFile.Name())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		global := cfg.Global
+		if global.EvaluationInterval != 30*time.Second || global.QueryLogFile != "/var/log/promenitheus/queries.log" {
+			t.Errorf("Global settings not parsed correctly: %+v", global)
+		}
+		if len(global.ExternalLabels) != 2 || global.ExternalLabels["cluster"] != "eu-1" {
+			t.Errorf("External labels not parsed correctly: %v", global.ExternalLabels)
+		}
+		if f := cfg.ScrapeConfigs[0].ScrapeFailureLogFile; f != "/var/log/promenitheus/scrape-failures.log" {
+			t.Errorf("Expected inherited scrape failure log file, got %q", f)
+		}
+		if f := cfg.ScrapeConfigs[1].ScrapeFailureLogFile; f != "/tmp/own.log" {
+			t.Errorf("Expected job scrape failure log file '/tmp/own.log', got %q", f)
+		}
+	})
+
+	t.Run("Reject invalid external labels", func(t *testing.T) {
+		configContent := `global:
+  external_labels:
+    __name__: up
+    cluster: ''
+`
+
+		tmpFile, err := os.CreateTemp("", "config-*.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write([]byte(configContent)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		_, err = LoadConfig(tmpFile.Name())
+		if err == nil {
+			t.Fatal("Expected validation error")
+		}
+		for _, expected := range []string{
+			`line 3: invalid external label name "__name__"`,
+			`line 4: external label "cluster" must not be empty`,
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("Expected error to contain %q, got:\n%v", expected, err)
+			}
+		}
+	})
+
+	t.Run("Reject basic_auth together with authorization", func(t *testing.T) {
+		configContent := `scrape_configs:
+  - job_name: 'api'
+    basic_auth:
+      username: admin
+      password: secret
+    authorization:
+      credentials: token
+    static_configs:
+      - targets: ['localhost:8080']
+remote_write:
+  - url: http://localhost:9201/write
+    basic_auth:
+      username: admin
+    authorization:
+      credentials: token
+remote_read:
+  - url: http://localhost:9201/read
+    basic_auth:
+      username: admin
+    authorization:
+      credentials: token
+`
+
+		tmpFile, err := os.CreateTemp("", "config-*.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tmpFile.Name())
+
+		if _, err := tmpFile.Write([]byte(configContent)); err != nil {
+			t.Fatal(err)
+		}
+		tmpFile.Close()
+
+		_, err = LoadConfig(tmpFile.Name())
+		if err == nil {
+			t.Fatal("Expected validation error")
+		}
+		for _, expected := range []string{
+			`line 7: job "api": at most one of basic_auth and authorization may be set`,
+			`line 15: remote write config 1: at most one of basic_auth and authorization may be set`,
+			`line 21: remote read config 1: at most one of basic_auth and authorization may be set`,
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("Expected error to contain %q, got:\n%v", expected, err)
+			}
+		}
+	})
 }
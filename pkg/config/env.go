@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envRefRE matches ${VAR} references and the $$ escape. Names must not start
+// with a digit, so regex references such as ${1} in relabel replacements are
+// left alone.
+var envRefRE = regexp.MustCompile(`\$\$|\$\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
+// expandEnv replaces ${VAR} references in the values of a parsed document
+// with the value of the environment variable VAR and $$ with a literal $.
+// Values are replaced after parsing, so they are taken literally and cannot
+// change the structure of the document; keys and comments are left alone.
+// References to unset variables are errors, reported with their line.
+func expandEnv(node *yaml.Node) error {
+	var errs []error
+	var expand func(n *yaml.Node)
+	expand = func(n *yaml.Node) {
+		switch n.Kind {
+		case yaml.DocumentNode, yaml.SequenceNode:
+			for _, c := range n.Content {
+				expand(c)
+			}
+		case yaml.MappingNode:
+			for i := 1; i < len(n.Content); i += 2 {
+				expand(n.Content[i])
+			}
+		case yaml.ScalarNode:
+			value := envRefRE.ReplaceAllStringFunc(n.Value, func(ref string) string {
+				if ref == "$$" {
+					return "$"
+				}
+				name := ref[2 : len(ref)-1]
+				value, ok := os.LookupEnv(name)
+				if !ok {
+					errs = append(errs, fmt.Errorf("line %d: environment variable %s is not set", n.Line, name))
+					return ref
+				}
+				return value
+			})
+			if value == n.Value {
+				return
+			}
+			n.Value = value
+			// Resolve the type of unquoted values again, so that
+			// sample_limit: ${LIMIT} is an integer
+			if n.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+				n.Tag = ""
+			}
+		}
+	}
+	expand(node)
+	return errors.Join(errs...)
+}
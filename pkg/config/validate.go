@@ -0,0 +1,309 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// source is a parsed config file, used to point problems at the line of the
+// offending setting
+type source struct {
+	// file is empty for the main config file
+	file string
+	root *yaml.Node
+}
+
+// position returns the line of the setting at path, a sequence of mapping
+// keys and sequence indexes. If the setting is not in the file, e.g. because
+// a default applies, the line of its closest parent is used.
+func (s source) position(path ...any) string {
+	line := 0
+	if node := lookup(s.root, path...); node != nil {
+		line = node.Line
+	}
+	if s.file != "" {
+		return fmt.Sprintf("%s line %d", s.file, line)
+	}
+	return fmt.Sprintf("line %d", line)
+}
+
+// jobSource is where a scrape config was defined
+type jobSource struct {
+	source
+	path []any
+}
+
+// problems collects validation errors
+type problems struct {
+	errs []error
+}
+
+// add records a problem with the setting at path in src
+func (p *problems) add(src source, path []any, format string, args ...any) {
+	p.errs = append(p.errs, fmt.Errorf("%s: %s", src.position(path...), fmt.Sprintf(format, args...)))
+}
+
+func (p *problems) err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("found %d problem(s):\n%w", len(p.errs), errors.Join(p.errs...))
+}
+
+// lookup returns the node at path below root, or the deepest node found on
+// the way
+func lookup(root *yaml.Node, path ...any) *yaml.Node {
+	node := root
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for _, elem := range path {
+		var next *yaml.Node
+		switch elem := elem.(type) {
+		case string:
+			if node != nil && node.Kind == yaml.MappingNode {
+				for i := 0; i+1 < len(node.Content); i += 2 {
+					if node.Content[i].Value == elem {
+						next = node.Content[i+1]
+						break
+					}
+				}
+			}
+		case int:
+			if node != nil && node.Kind == yaml.SequenceNode && elem < len(node.Content) {
+				next = node.Content[elem]
+			}
+		}
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+	return node
+}
+
+// validate checks settings that parse but cannot work, returning all
+// problems at once. root is the parsed main config file and jobs holds where
+// each scrape config was defined.
+func (c *Config) validate(root *yaml.Node, jobs []jobSource) error {
+	p := &problems{}
+	main := source{root: root}
+
+	if c.Global.ScrapeTimeout > c.Global.ScrapeInterval {
+		p.add(main, []any{"global", "scrape_timeout"}, "global scrape_timeout %v is greater than scrape_interval %v", c.Global.ScrapeTimeout, c.Global.ScrapeInterval)
+	}
+
+	if c.Global.EvaluationInterval < 0 {
+		p.add(main, []any{"global", "evaluation_interval"}, "global evaluation_interval must be positive")
+	}
+	names := make([]string, 0, len(c.Global.ExternalLabels))
+	for name := range c.Global.ExternalLabels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := c.Global.ExternalLabels[name]
+		if !metrics.IsValidLabelName(name) || name == "__name__" {
+			p.add(main, []any{"global", "external_labels", name}, "invalid external label name %q", name)
+		}
+		if value == "" {
+			p.add(main, []any{"global", "external_labels", name}, "external label %q must not be empty", name)
+		}
+	}
+
+	jobPositions := make(map[string]string)
+	for i, sc := range c.ScrapeConfigs {
+		src := jobs[i].source
+		at := func(elems ...any) []any {
+			return append(append([]any{}, jobs[i].path...), elems...)
+		}
+
+		name := sc.JobName
+		if name == "" {
+			p.add(src, at(), "scrape config %d has no job_name", i+1)
+			name = fmt.Sprintf("#%d", i+1)
+		} else if pos, ok := jobPositions[name]; ok {
+			p.add(src, at("job_name"), "job %q: duplicate job_name, first defined on %s", name, pos)
+		} else {
+			jobPositions[name] = src.position(at("job_name")...)
+		}
+
+		if sc.ScrapeInterval <= 0 {
+			p.add(src, at("scrape_interval"), "job %q: scrape_interval must be positive", name)
+		}
+		if sc.ScrapeTimeout > sc.ScrapeInterval {
+			p.add(src, at("scrape_timeout"), "job %q: scrape_timeout %v is greater than scrape_interval %v", name, sc.ScrapeTimeout, sc.ScrapeInterval)
+		}
+		if sc.Scheme != "http" && sc.Scheme != "https" {
+			p.add(src, at("scheme"), "job %q: unsupported scheme %q, must be http or https", name, sc.Scheme)
+		}
+		if !strings.HasPrefix(sc.MetricsPath, "/") {
+			p.add(src, at("metrics_path"), "job %q: metrics_path %q must start with /", name, sc.MetricsPath)
+		}
+		if sc.BasicAuth != nil && sc.Authorization != nil {
+			p.add(src, at("authorization"), "job %q: at most one of basic_auth and authorization may be set", name)
+		}
+
+		if len(sc.StaticConfigs) == 0 {
+			p.add(src, at(), "job %q has no static_configs", name)
+		}
+		for j, static := range sc.StaticConfigs {
+			if len(static.Targets) == 0 {
+				p.add(src, at("static_configs", j), "job %q: static config %d has no targets", name, j+1)
+			}
+			for k, target := range static.Targets {
+				if err := validateTarget(target); err != nil {
+					p.add(src, at("static_configs", j, "targets", k), "job %q: %v", name, err)
+				}
+			}
+		}
+	}
+
+	for i, am := range c.Alerting.Alertmanagers {
+		for j, static := range am.StaticConfigs {
+			for k, target := range static.Targets {
+				if err := validateTarget(target); err != nil {
+					p.add(main, []any{"alerting", "alertmanagers", i, "static_configs", j, "targets", k}, "alertmanager config %d: %v", i+1, err)
+				}
+			}
+		}
+	}
+
+	for i, rw := range c.RemoteWriteConfigs {
+		if err := validateURL(rw.URL); err != nil {
+			p.add(main, []any{"remote_write", i, "url"}, "remote write config %d: %v", i+1, err)
+		}
+		if rw.BasicAuth != nil && rw.Authorization != nil {
+			p.add(main, []any{"remote_write", i, "authorization"}, "remote write config %d: at most one of basic_auth and authorization may be set", i+1)
+		}
+	}
+	for i, rr := range c.RemoteReadConfigs {
+		if err := validateURL(rr.URL); err != nil {
+			p.add(main, []any{"remote_read", i, "url"}, "remote read config %d: %v", i+1, err)
+		}
+		if rr.BasicAuth != nil && rr.Authorization != nil {
+			p.add(main, []any{"remote_read", i, "authorization"}, "remote read config %d: at most one of basic_auth and authorization may be set", i+1)
+		}
+	}
+
+	return p.err()
+}
+
+// validateTarget checks that a target is a host:port pair
+func validateTarget(target string) error {
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		return fmt.Errorf("invalid target %q, must be host:port", target)
+	}
+	if host == "" {
+		return fmt.Errorf("invalid target %q, host must not be empty", target)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid target %q, port must be a number between 1 and 65535", target)
+	}
+	return nil
+}
+
+// validateURL checks that a remote endpoint is an absolute HTTP(S) URL
+func validateURL(rawURL string) error {
+	if rawURL == "" {
+		return fmt.Errorf("url must not be empty")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %v", rawURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q, must be an http or https URL", rawURL)
+	}
+	return nil
+}
+
+// checkKnownFields reports mapping keys of node that are not fields of the
+// struct v points to. Custom unmarshalers use it because yaml.Node.Decode
+// does not inherit the strictness of the decoder.
+func checkKnownFields(node *yaml.Node, v any) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	t := reflect.TypeOf(v).Elem()
+	fields := yamlFields(t)
+	var errs []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if _, ok := fields[key.Value]; !ok {
+			errs = append(errs, unknownFieldError(key, t))
+		}
+	}
+	if len(errs) > 0 {
+		return &yaml.TypeError{Errors: errs}
+	}
+	return nil
+}
+
+// unknownFields reports the mapping keys anywhere in node that are not
+// fields of the struct they decode into, the check the strict decoder does.
+// Types with their own UnmarshalYAML check their keys themselves.
+func unknownFields(node *yaml.Node, t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(reflect.TypeFor[yaml.Unmarshaler]()) {
+		return nil
+	}
+
+	var errs []string
+	switch {
+	case node.Kind == yaml.DocumentNode:
+		for _, c := range node.Content {
+			errs = append(errs, unknownFields(c, t)...)
+		}
+	case node.Kind == yaml.MappingNode && t.Kind() == reflect.Struct:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			ft, ok := fields[key.Value]
+			if !ok {
+				errs = append(errs, unknownFieldError(key, t))
+				continue
+			}
+			errs = append(errs, unknownFields(node.Content[i+1], ft)...)
+		}
+	case node.Kind == yaml.MappingNode && t.Kind() == reflect.Map:
+		for i := 1; i < len(node.Content); i += 2 {
+			errs = append(errs, unknownFields(node.Content[i], t.Elem())...)
+		}
+	case node.Kind == yaml.SequenceNode && t.Kind() == reflect.Slice:
+		for _, c := range node.Content {
+			errs = append(errs, unknownFields(c, t.Elem())...)
+		}
+	}
+	return errs
+}
+
+// yamlFields maps the YAML keys of a struct to the types of their fields
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		fields[name] = t.Field(i).Type
+	}
+	return fields
+}
+
+// unknownFieldError formats an unknown key like the strict decoder
+func unknownFieldError(key *yaml.Node, t reflect.Type) string {
+	return fmt.Sprintf("line %d: field %s not found in type %s", key.Line, key.Value, t)
+}
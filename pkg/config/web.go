@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// WebConfig configures TLS and authentication of the server's HTTP and gRPC
+// endpoints
+type WebConfig struct {
+	TLSServerConfig *TLSServerConfig `yaml:"tls_server_config,omitempty"`
+
+	// BasicAuthUsers maps user names to bcrypt hashes of their passwords
+	BasicAuthUsers map[string]string `yaml:"basic_auth_users,omitempty"`
+	// BearerTokens are accepted in an "Authorization: Bearer <token>" header
+	BearerTokens []string `yaml:"bearer_tokens,omitempty"`
+}
+
+// TLSServerConfig configures the certificate of the server and how client
+// certificates are verified. Relative paths are resolved against the
+// directory of the web config file.
+type TLSServerConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+
+	// ClientCAFile holds the CAs client certificates are verified against
+	ClientCAFile string `yaml:"client_ca_file,omitempty"`
+	// ClientAuthType is the name of a crypto/tls ClientAuthType, by default
+	// RequireAndVerifyClientCert if ClientCAFile is set and NoClientCert
+	// otherwise
+	ClientAuthType string `yaml:"client_auth_type,omitempty"`
+}
+
+// clientAuthTypes are the valid values of client_auth_type
+var clientAuthTypes = []string{
+	"NoClientCert",
+	"RequestClientCert",
+	"RequireAnyClientCert",
+	"VerifyClientCertIfGiven",
+	"RequireAndVerifyClientCert",
+}
+
+// AuthEnabled reports whether requests must carry credentials
+func (c *WebConfig) AuthEnabled() bool {
+	return len(c.BasicAuthUsers) > 0 || len(c.BearerTokens) > 0
+}
+
+// LoadWebConfig loads a web config file. Like LoadConfig, unknown keys and
+// invalid settings are errors.
+func LoadWebConfig(path string) (*WebConfig, error) {
+	var config WebConfig
+	root, err := decodeFile(path, &config, LoadOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load web config file: %w", err)
+	}
+
+	if tc := config.TLSServerConfig; tc != nil {
+		for _, file := range []*string{&tc.CertFile, &tc.KeyFile, &tc.ClientCAFile} {
+			if *file != "" && !filepath.IsAbs(*file) {
+				*file = filepath.Join(filepath.Dir(path), *file)
+			}
+		}
+		if tc.ClientAuthType == "" {
+			tc.ClientAuthType = "NoClientCert"
+			if tc.ClientCAFile != "" {
+				tc.ClientAuthType = "RequireAndVerifyClientCert"
+			}
+		}
+	}
+
+	if err := config.validate(source{root: root}); err != nil {
+		return nil, fmt.Errorf("invalid web config file %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+func (c *WebConfig) validate(src source) error {
+	p := &problems{}
+
+	if tc := c.TLSServerConfig; tc != nil {
+		if tc.CertFile == "" || tc.KeyFile == "" {
+			p.add(src, []any{"tls_server_config"}, "cert_file and key_file are required")
+		}
+		switch tc.ClientAuthType {
+		case "VerifyClientCertIfGiven", "RequireAndVerifyClientCert":
+			if tc.ClientCAFile == "" {
+				p.add(src, []any{"tls_server_config", "client_auth_type"}, "client_auth_type %s requires client_ca_file", tc.ClientAuthType)
+			}
+		case "NoClientCert", "RequestClientCert", "RequireAnyClientCert":
+		default:
+			p.add(src, []any{"tls_server_config", "client_auth_type"}, "invalid client_auth_type %q, expected one of %v", tc.ClientAuthType, clientAuthTypes)
+		}
+	}
+
+	users := make([]string, 0, len(c.BasicAuthUsers))
+	for user := range c.BasicAuthUsers {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	for _, user := range users {
+		if _, err := bcrypt.Cost([]byte(c.BasicAuthUsers[user])); err != nil {
+			p.add(src, []any{"basic_auth_users", user}, "password of user %q is not a bcrypt hash: %v", user, err)
+		}
+	}
+
+	for i, token := range c.BearerTokens {
+		if token == "" {
+			p.add(src, []any{"bearer_tokens", i}, "bearer token must not be empty")
+		}
+	}
+
+	return p.err()
+}
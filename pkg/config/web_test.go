@@ -0,0 +1,128 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLoadWebConfig(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeWebConfig := func(t *testing.T, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "web.yaml")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("Load valid config", func(t *testing.T) {
+		path := writeWebConfig(t, `tls_server_config:
+  cert_file: server.crt
+  key_file: /etc/promenitheus/server.key
+  client_ca_file: ca.crt
+basic_auth_users:
+  alice: '`+string(hash)+`'
+bearer_tokens:
+  - token-1
+`)
+
+		cfg, err := LoadWebConfig(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		tc := cfg.TLSServerConfig
+		if tc == nil {
+			t.Fatal("Expected tls_server_config to be loaded")
+		}
+		if expected := filepath.Join(filepath.Dir(path), "server.crt"); tc.CertFile != expected {
+			t.Errorf("Expected cert_file %s, got %s", expected, tc.CertFile)
+		}
+		if tc.KeyFile != "/etc/promenitheus/server.key" {
+			t.Errorf("Expected absolute key_file to be kept, got %s", tc.KeyFile)
+		}
+		if tc.ClientAuthType != "RequireAndVerifyClientCert" {
+			t.Errorf("Expected client_auth_type RequireAndVerifyClientCert with a client CA, got %s", tc.ClientAuthType)
+		}
+		if cfg.BasicAuthUsers["alice"] != string(hash) {
+			t.Errorf("Expected hash of alice to be loaded, got %q", cfg.BasicAuthUsers["alice"])
+		}
+		if len(cfg.BearerTokens) != 1 || cfg.BearerTokens[0] != "token-1" {
+			t.Errorf("Expected bearer token token-1, got %v", cfg.BearerTokens)
+		}
+		if !cfg.AuthEnabled() {
+			t.Error("Expected auth to be enabled")
+		}
+	})
+
+	t.Run("TLS only", func(t *testing.T) {
+		cfg, err := LoadWebConfig(writeWebConfig(t, `tls_server_config:
+  cert_file: server.crt
+  key_file: server.key
+`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.TLSServerConfig.ClientAuthType != "NoClientCert" {
+			t.Errorf("Expected client_auth_type NoClientCert, got %s", cfg.TLSServerConfig.ClientAuthType)
+		}
+		if cfg.AuthEnabled() {
+			t.Error("Expected auth to be disabled")
+		}
+	})
+
+	t.Run("Report all validation problems", func(t *testing.T) {
+		_, err := LoadWebConfig(writeWebConfig(t, `tls_server_config:
+  cert_file: server.crt
+  client_auth_type: VerifyClientCertIfGiven
+basic_auth_users:
+  alice: secret
+bearer_tokens:
+  - ''
+`))
+		if err == nil {
+			t.Fatal("Expected validation error")
+		}
+
+		expected := []string{
+			`line 2: cert_file and key_file are required`,
+			`line 3: client_auth_type VerifyClientCertIfGiven requires client_ca_file`,
+			`line 5: password of user "alice" is not a bcrypt hash`,
+			`line 7: bearer token must not be empty`,
+		}
+		for _, e := range expected {
+			if !strings.Contains(err.Error(), e) {
+				t.Errorf("Expected error to contain %q, got:\n%v", e, err)
+			}
+		}
+	})
+
+	t.Run("Reject invalid client auth type", func(t *testing.T) {
+		_, err := LoadWebConfig(writeWebConfig(t, `tls_server_config:
+  cert_file: server.crt
+  key_file: server.key
+  client_auth_type: Always
+`))
+		if err == nil || !strings.Contains(err.Error(), `invalid client_auth_type "Always"`) {
+			t.Errorf("Expected invalid client_auth_type error, got %v", err)
+		}
+	})
+
+	t.Run("Reject unknown fields", func(t *testing.T) {
+		_, err := LoadWebConfig(writeWebConfig(t, `basic_auth:
+  alice: secret
+`))
+		if err == nil {
+			t.Error("Expected error for unknown field")
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package federation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// handler serves the latest samples of selected series for federation
+type handler struct {
+	registry       *metrics.MetricRegistry
+	externalLabels map[string]string
+
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewHandler returns an HTTP handler for /federate. Every match[] parameter
+// is a series selector; the latest sample within the lookback window of each
+// series matching any selector is returned in the text exposition format
+// with its original labels and timestamp, so a scraping server using
+// honor_labels keeps the job and instance labels of the source. External
+// labels are added to series without a label of the same name.
+func NewHandler(registry *metrics.MetricRegistry, externalLabels map[string]string) http.Handler {
+	return &handler{registry: registry, externalLabels: externalLabels, now: time.Now}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse form: %v", err), http.StatusBadRequest)
+		return
+	}
+	selectors := r.Form["match[]"]
+	if len(selectors) == 0 {
+		http.Error(w, "no match[] parameter provided", http.StatusBadRequest)
+		return
+	}
+
+	var matcherSets [][]*metrics.Matcher
+	for _, s := range selectors {
+		matchers, err := query.ParseSelector(s)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		matcherSets = append(matcherSets, matchers)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(h.federate(r.Context(), matcherSets))
+}
+
+// federate renders the latest sample of every series matching any of the
+// matcher sets
+func (h *handler) federate(ctx context.Context, matcherSets [][]*metrics.Matcher) []byte {
+	end := h.now()
+	start := end.Add(-query.DefaultLookbackDelta)
+
+	// A series matching several selectors is only written once
+	seen := make(map[string]bool)
+	var series []*metrics.Series
+	for _, matchers := range matcherSets {
+		for _, s := range h.registry.Select(ctx, matchers, start, end) {
+			key := s.Name + "{" + formatLabels(s.Labels) + "}"
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			series = append(series, s)
+		}
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].Name != series[j].Name {
+			return series[i].Name < series[j].Name
+		}
+		return formatLabels(series[i].Labels) < formatLabels(series[j].Labels)
+	})
+
+	var buf bytes.Buffer
+	currentName := ""
+	for _, s := range series {
+		if s.Name != currentName {
+			typ := string(s.Type)
+			if typ == "" {
+				typ = "untyped"
+			}
+			fmt.Fprintf(&buf, "# TYPE %s %s\n", s.Name, typ)
+			currentName = s.Name
+		}
+
+		latest := s.Samples[len(s.Samples)-1]
+		labels := h.withExternalLabels(s.Labels)
+		buf.WriteString(s.Name)
+		if len(labels) > 0 {
+			buf.WriteString("{" + formatLabels(labels) + "}")
+		}
+		fmt.Fprintf(&buf, " %s %d\n", formatValue(latest.Value), latest.Timestamp.UnixMilli())
+	}
+	return buf.Bytes()
+}
+
+// withExternalLabels returns labels with the external labels it lacks
+func (h *handler) withExternalLabels(labels map[string]string) map[string]string {
+	if len(h.externalLabels) == 0 {
+		return labels
+	}
+	res := make(map[string]string, len(labels)+len(h.externalLabels))
+	for k, v := range h.externalLabels {
+		res[k] = v
+	}
+	for k, v := range labels {
+		res[k] = v
+	}
+	return res
+}
+
+// formatLabels renders labels sorted by name with escaped values
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+`="`+escapeLabelValue(v)+`"`)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+// formatValue renders a sample value as in the text exposition format
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,85 @@
+package federation
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+func TestHandler(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	registry := metrics.NewMetricRegistry()
+	register := func(name string, typ metrics.MetricType, value float64, labels map[string]string, ts time.Time) {
+		registry.Register(&metrics.Metric{Name: name, Type: typ, Value: value, Labels: labels, Timestamp: ts})
+	}
+
+	register("http_requests_total", metrics.MetricTypeCounter, 10, map[string]string{"job": "api", "instance": "a:80"}, now.Add(-time.Minute))
+	register("http_requests_total", metrics.MetricTypeCounter, 12, map[string]string{"job": "api", "instance": "a:80"}, now.Add(-15*time.Second))
+	register("http_requests_total", metrics.MetricTypeCounter, 7, map[string]string{"job": "web", "instance": "b:80"}, now.Add(-15*time.Second))
+	register("up", metrics.MetricTypeGauge, 1, map[string]string{"job": "api", "instance": "a:80"}, now.Add(-15*time.Second))
+	register("up", metrics.MetricTypeGauge, 0, map[string]string{"job": "gone", "instance": "c:80"}, now.Add(-10*time.Minute))
+	register("temperature", "", math.Inf(1), map[string]string{"job": "api", "room": "a \"b\"\\c\nd"}, now)
+
+	h := &handler{registry: registry, now: func() time.Time { return now }}
+	get := func(t *testing.T, selectors ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		form := url.Values{"match[]": selectors}
+		req := httptest.NewRequest(http.MethodGet, "/federate?"+form.Encode(), nil)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Returns latest samples of matching series", func(t *testing.T) {
+		rec := get(t, `{job="api"}`, `up`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != contentType {
+			t.Errorf("Expected content type %q, got %q", contentType, ct)
+		}
+
+		expected := `# TYPE http_requests_total counter
+http_requests_total{instance="a:80",job="api"} 12 1699999985000
+# TYPE temperature untyped
+temperature{job="api",room="a \"b\"\\c\nd"} +Inf 1700000000000
+# TYPE up gauge
+up{instance="a:80",job="api"} 1 1699999985000
+`
+		if got := rec.Body.String(); got != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+
+	t.Run("Adds external labels", func(t *testing.T) {
+		h := &handler{
+			registry:       registry,
+			externalLabels: map[string]string{"cluster": "eu-1", "job": "ignored"},
+			now:            func() time.Time { return now },
+		}
+		form := url.Values{"match[]": {`up{job="api"}`}}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/federate?"+form.Encode(), nil))
+
+		expected := `# TYPE up gauge
+up{cluster="eu-1",instance="a:80",job="api"} 1 1699999985000
+`
+		if got := rec.Body.String(); got != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+
+	t.Run("Rejects missing or invalid selectors", func(t *testing.T) {
+		if rec := get(t); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 without match[], got %d", rec.Code)
+		}
+		if rec := get(t, `rate(up[5m])`); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for expression, got %d", rec.Code)
+		}
+	})
+}
@@ -6,24 +6,62 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
 	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+	"github.com/Avinash7390/Promenitheus/pkg/rules"
+	"github.com/Avinash7390/Promenitheus/pkg/scraper"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// TargetProvider reports the scrape status of targets
+type TargetProvider interface {
+	Targets() []scraper.TargetStatus
+}
+
+// RuleProvider reports the state of rule groups and alerts
+type RuleProvider interface {
+	Groups() []*rules.Group
+	Alerts() []*rules.Alert
+}
+
 // MetricsServer implements the gRPC MetricsService
 type MetricsServer struct {
 	pb.UnimplementedMetricsServiceServer
 	registry *metrics.MetricRegistry
+	targets  TargetProvider
+	rules    RuleProvider
+	engine   *query.Engine
 }
 
 // NewMetricsServer creates a new gRPC metrics server
 func NewMetricsServer(registry *metrics.MetricRegistry) *MetricsServer {
 	return &MetricsServer{
 		registry: registry,
+		engine:   query.NewEngine(registry),
 	}
 }
 
+// SetQueryEngine sets the engine evaluating InstantQuery and RangeQuery,
+// one reading the registry by default
+func (s *MetricsServer) SetQueryEngine(engine *query.Engine) {
+	s.engine = engine
+}
+
+// SetTargetProvider sets the source of target status for GetTargets
+func (s *MetricsServer) SetTargetProvider(targets TargetProvider) {
+	s.targets = targets
+}
+
+// SetRuleProvider sets the source of rule and alert state for GetRules and
+// GetAlerts
+func (s *MetricsServer) SetRuleProvider(rules RuleProvider) {
+	s.rules = rules
+}
+
 // GetMetrics returns all metrics in Prometheus text format
 func (s *MetricsServer) GetMetrics(ctx context.Context, req *pb.GetMetricsRequest) (*pb.GetMetricsResponse, error) {
 	allMetrics := s.registry.GetAll()
@@ -79,13 +117,7 @@ func (s *MetricsServer) QueryMetrics(ctx context.Context, req *pb.QueryMetricsRe
 	allMetrics := s.registry.GetAll()
 	for _, m := range allMetrics {
 		if req.Query == "" || m.Name == req.Query {
-			result = append(result, &pb.Metric{
-				Name:      m.Name,
-				Type:      string(m.Type),
-				Value:     m.Value,
-				Labels:    m.Labels,
-				Timestamp: m.Timestamp.Unix(),
-			})
+			result = append(result, metricToProto(m))
 		}
 	}
 
@@ -102,13 +134,7 @@ func (s *MetricsServer) ListMetrics(ctx context.Context, req *pb.ListMetricsRequ
 	allMetrics := s.registry.GetAll()
 	for _, m := range allMetrics {
 		if req.Filter == "" || m.Name == req.Filter {
-			result = append(result, &pb.Metric{
-				Name:      m.Name,
-				Type:      string(m.Type),
-				Value:     m.Value,
-				Labels:    m.Labels,
-				Timestamp: m.Timestamp.Unix(),
-			})
+			result = append(result, metricToProto(m))
 		}
 	}
 
@@ -116,3 +142,242 @@ func (s *MetricsServer) ListMetrics(ctx context.Context, req *pb.ListMetricsRequ
 		Metrics: result,
 	}, nil
 }
+
+// GetTargets returns the scrape status of all targets
+func (s *MetricsServer) GetTargets(ctx context.Context, req *pb.GetTargetsRequest) (*pb.GetTargetsResponse, error) {
+	result := []*pb.Target{}
+
+	if s.targets != nil {
+		for _, t := range s.targets.Targets() {
+			target := &pb.Target{
+				Job:                       t.Job,
+				Instance:                  t.Instance,
+				ScrapeUrl:                 t.ScrapeURL,
+				Labels:                    t.Labels,
+				Health:                    string(t.Health),
+				LastError:                 t.LastError,
+				LastScrapeDurationSeconds: t.LastScrapeDuration.Seconds(),
+			}
+			if !t.LastScrape.IsZero() {
+				target.LastScrape = t.LastScrape.Unix()
+			}
+			result = append(result, target)
+		}
+	}
+
+	return &pb.GetTargetsResponse{
+		ActiveTargets: result,
+	}, nil
+}
+
+// GetAlerts returns all pending and firing alerts
+func (s *MetricsServer) GetAlerts(ctx context.Context, req *pb.GetAlertsRequest) (*pb.GetAlertsResponse, error) {
+	result := []*pb.Alert{}
+
+	if s.rules != nil {
+		for _, a := range s.rules.Alerts() {
+			result = append(result, alertToProto(a))
+		}
+	}
+
+	return &pb.GetAlertsResponse{
+		Alerts: result,
+	}, nil
+}
+
+// GetRules returns all rule groups, optionally only with rules of one type
+func (s *MetricsServer) GetRules(ctx context.Context, req *pb.GetRulesRequest) (*pb.GetRulesResponse, error) {
+	if req.Type != "" && req.Type != "alert" && req.Type != "record" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid rule type %q, must be alert or record", req.Type)
+	}
+
+	result := []*pb.RuleGroup{}
+
+	if s.rules != nil {
+		for _, g := range s.rules.Groups() {
+			group := &pb.RuleGroup{
+				Name:                  g.Name(),
+				File:                  g.File(),
+				IntervalSeconds:       g.Interval().Seconds(),
+				Rules:                 []*pb.Rule{},
+				EvaluationTimeSeconds: g.EvaluationDuration().Seconds(),
+			}
+			if last := g.LastEvaluation(); !last.IsZero() {
+				group.LastEvaluation = last.Unix()
+			}
+
+			for _, r := range g.Rules() {
+				rule := &pb.Rule{
+					Name:                  r.Name(),
+					Query:                 r.Query(),
+					Labels:                r.Labels(),
+					Health:                string(r.Health()),
+					LastError:             r.LastError(),
+					EvaluationTimeSeconds: r.EvaluationDuration().Seconds(),
+				}
+				if last := r.LastEvaluation(); !last.IsZero() {
+					rule.LastEvaluation = last.Unix()
+				}
+
+				switch r := r.(type) {
+				case *rules.AlertingRule:
+					if req.Type == "record" {
+						continue
+					}
+					rule.Type = "alerting"
+					rule.Annotations = r.Annotations()
+					rule.DurationSeconds = r.HoldDuration().Seconds()
+					rule.KeepFiringForSeconds = r.KeepFiringFor().Seconds()
+					rule.State = string(r.State())
+					rule.Alerts = []*pb.Alert{}
+					for _, a := range r.ActiveAlerts() {
+						rule.Alerts = append(rule.Alerts, alertToProto(a))
+					}
+				default:
+					if req.Type == "alert" {
+						continue
+					}
+					rule.Type = "recording"
+				}
+				group.Rules = append(group.Rules, rule)
+			}
+			result = append(result, group)
+		}
+	}
+
+	return &pb.GetRulesResponse{
+		Groups: result,
+	}, nil
+}
+
+// InstantQuery evaluates an expression at the requested time, now if unset
+func (s *MetricsServer) InstantQuery(ctx context.Context, req *pb.InstantQueryRequest) (*pb.QueryResponse, error) {
+	ts := time.Now()
+	if req.Time != 0 {
+		ts = time.UnixMilli(req.Time)
+	}
+
+	val, err := s.engine.Instant(ctx, req.Query, ts)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return valueToProto(val), nil
+}
+
+// RangeQuery evaluates an expression at every step between start and end
+func (s *MetricsServer) RangeQuery(ctx context.Context, req *pb.RangeQueryRequest) (*pb.QueryResponse, error) {
+	matrix, err := s.engine.Range(ctx, req.Query, time.UnixMilli(req.Start), time.UnixMilli(req.End), time.Duration(req.Step)*time.Millisecond)
+	if ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return valueToProto(matrix), nil
+}
+
+// GetTSDBStatus returns the number of series and samples and the label
+// sets with the highest cardinality
+func (s *MetricsServer) GetTSDBStatus(ctx context.Context, req *pb.GetTSDBStatusRequest) (*pb.GetTSDBStatusResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = 10
+	}
+
+	seriesByName := make(map[string]int64)
+	seriesByPair := make(map[string]int64)
+	valuesByLabel := make(map[string]map[string]bool)
+	for _, m := range s.registry.GetAll() {
+		seriesByName[m.Name]++
+		for name, value := range m.Labels {
+			seriesByPair[name+"="+value]++
+			if valuesByLabel[name] == nil {
+				valuesByLabel[name] = make(map[string]bool)
+			}
+			valuesByLabel[name][value] = true
+		}
+	}
+	valueCounts := make(map[string]int64, len(valuesByLabel))
+	for name, values := range valuesByLabel {
+		valueCounts[name] = int64(len(values))
+	}
+
+	stats := s.registry.Stats()
+	resp := &pb.GetTSDBStatusResponse{
+		NumSeries:                   int64(stats.NumSeries),
+		NumSamples:                  int64(stats.NumSamples),
+		NumLabelPairs:               int64(len(seriesByPair)),
+		SeriesCountByMetricName:     topCardinality(seriesByName, limit),
+		LabelValueCountByLabelName:  topCardinality(valueCounts, limit),
+		SeriesCountByLabelValuePair: topCardinality(seriesByPair, limit),
+	}
+	if !stats.MinTime.IsZero() {
+		resp.MinTime = stats.MinTime.UnixMilli()
+		resp.MaxTime = stats.MaxTime.UnixMilli()
+	}
+	return resp, nil
+}
+
+// topCardinality returns the limit entries with the highest counts, ties
+// ordered by name
+func topCardinality(counts map[string]int64, limit int) []*pb.CardinalityStat {
+	result := make([]*pb.CardinalityStat, 0, len(counts))
+	for name, value := range counts {
+		result = append(result, &pb.CardinalityStat{Name: name, Value: value})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Value != result[j].Value {
+			return result[i].Value > result[j].Value
+		}
+		return result[i].Name < result[j].Name
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// valueToProto converts a query result to its API representation. A scalar
+// is returned as a single series without labels.
+func valueToProto(val query.Value) *pb.QueryResponse {
+	resp := &pb.QueryResponse{
+		ResultType: string(val.Type()),
+		Result:     []*pb.QuerySeries{},
+	}
+
+	switch v := val.(type) {
+	case query.Scalar:
+		resp.Result = append(resp.Result, &pb.QuerySeries{
+			Points: []*pb.QueryPoint{{Timestamp: v.T.UnixMilli(), Value: v.V}},
+		})
+	case query.String:
+		resp.StringValue = v.V
+	case query.Vector:
+		for _, sample := range v {
+			resp.Result = append(resp.Result, &pb.QuerySeries{
+				Labels: sample.Labels,
+				Points: []*pb.QueryPoint{{Timestamp: sample.T.UnixMilli(), Value: sample.V}},
+			})
+		}
+	case query.Matrix:
+		for _, series := range v {
+			points := make([]*pb.QueryPoint, 0, len(series.Points))
+			for _, p := range series.Points {
+				points = append(points, &pb.QueryPoint{Timestamp: p.T.UnixMilli(), Value: p.V})
+			}
+			resp.Result = append(resp.Result, &pb.QuerySeries{Labels: series.Labels, Points: points})
+		}
+	}
+	return resp
+}
+
+// alertToProto converts an alert to its API representation
+func alertToProto(a *rules.Alert) *pb.Alert {
+	return &pb.Alert{
+		Labels:      a.Labels,
+		Annotations: a.Annotations,
+		State:       string(a.State),
+		ActiveAt:    a.ActiveAt.Unix(),
+		Value:       a.Value,
+	}
+}
@@ -8,6 +8,11 @@ import (
 
 	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
 	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+	"github.com/Avinash7390/Promenitheus/pkg/rules"
+	"github.com/Avinash7390/Promenitheus/pkg/scraper"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestMetricsServer(t *testing.T) {
@@ -211,3 +216,313 @@ func TestMetricsServer(t *testing.T) {
 		}
 	})
 }
+
+// staticTargets is a TargetProvider returning a fixed list of targets
+type staticTargets []scraper.TargetStatus
+
+func (s staticTargets) Targets() []scraper.TargetStatus {
+	return s
+}
+
+func TestGetTargets(t *testing.T) {
+	server := NewMetricsServer(metrics.NewMetricRegistry())
+
+	t.Run("No target provider", func(t *testing.T) {
+		resp, err := server.GetTargets(context.Background(), &pb.GetTargetsRequest{})
+		if err != nil {
+			t.Fatalf("GetTargets failed: %v", err)
+		}
+
+		if len(resp.ActiveTargets) != 0 {
+			t.Errorf("Expected no targets, got %d", len(resp.ActiveTargets))
+		}
+	})
+
+	t.Run("Reports target health and errors", func(t *testing.T) {
+		lastScrape := time.Unix(1700000000, 0)
+		server.SetTargetProvider(staticTargets{
+			{
+				Job:                "api",
+				Instance:           "api-1:8080",
+				ScrapeURL:          "http://api-1:8080/metrics",
+				Health:             scraper.HealthBad,
+				LastError:          "sample limit exceeded: 20 samples, limit is 10",
+				LastScrape:         lastScrape,
+				LastScrapeDuration: 250 * time.Millisecond,
+			},
+			{Job: "api", Instance: "api-2:8080", Health: scraper.HealthUnknown},
+		})
+
+		resp, err := server.GetTargets(context.Background(), &pb.GetTargetsRequest{})
+		if err != nil {
+			t.Fatalf("GetTargets failed: %v", err)
+		}
+
+		if len(resp.ActiveTargets) != 2 {
+			t.Fatalf("Expected 2 targets, got %d", len(resp.ActiveTargets))
+		}
+
+		target := resp.ActiveTargets[0]
+		if target.Health != "down" || !strings.Contains(target.LastError, "sample limit exceeded") {
+			t.Errorf("Expected down target with limit error, got %+v", target)
+		}
+
+		if target.LastScrape != lastScrape.Unix() || target.LastScrapeDurationSeconds != 0.25 {
+			t.Errorf("Scrape time not reported correctly: %+v", target)
+		}
+
+		if resp.ActiveTargets[1].LastScrape != 0 {
+			t.Error("Expected zero last scrape for unscraped target")
+		}
+	})
+}
+
+// staticRules is a RuleProvider serving fixed rule groups
+type staticRules []*rules.Group
+
+func (s staticRules) Groups() []*rules.Group {
+	return s
+}
+
+func (s staticRules) Alerts() []*rules.Alert {
+	var alerts []*rules.Alert
+	for _, g := range s {
+		for _, r := range g.AlertingRules() {
+			alerts = append(alerts, r.ActiveAlerts()...)
+		}
+	}
+	return alerts
+}
+
+func TestGetRulesAndAlerts(t *testing.T) {
+	registry := metrics.NewMetricRegistry()
+	server := NewMetricsServer(registry)
+
+	t.Run("No rule provider", func(t *testing.T) {
+		resp, err := server.GetAlerts(context.Background(), &pb.GetAlertsRequest{})
+		if err != nil {
+			t.Fatalf("GetAlerts failed: %v", err)
+		}
+
+		if len(resp.Alerts) != 0 {
+			t.Errorf("Expected no alerts, got %d", len(resp.Alerts))
+		}
+	})
+
+	ts := time.Unix(1700000000, 0)
+	registry.Register(&metrics.Metric{
+		Name:      "up",
+		Type:      metrics.MetricTypeGauge,
+		Value:     0,
+		Labels:    map[string]string{"job": "api", "instance": "api-1:8080"},
+		Timestamp: ts,
+	})
+
+	recording, err := rules.NewRecordingRule("job:up:sum", `sum by (job) (up)`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alerting, err := rules.NewAlertingRule("InstanceDown", `up == 0`, 5*time.Minute, 0,
+		map[string]string{"severity": "page"},
+		map[string]string{"summary": "{{ $labels.instance }} is down"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	group := rules.NewGroup("example", "rules.yml", time.Minute, []rules.Rule{recording, alerting})
+	group.Eval(context.Background(), query.NewEngine(registry), registry, ts)
+	server.SetRuleProvider(staticRules{group})
+
+	t.Run("Reports pending alerts", func(t *testing.T) {
+		resp, err := server.GetAlerts(context.Background(), &pb.GetAlertsRequest{})
+		if err != nil {
+			t.Fatalf("GetAlerts failed: %v", err)
+		}
+
+		if len(resp.Alerts) != 1 {
+			t.Fatalf("Expected 1 alert, got %d", len(resp.Alerts))
+		}
+
+		alert := resp.Alerts[0]
+		if alert.State != "pending" || alert.Labels["alertname"] != "InstanceDown" || alert.Labels["severity"] != "page" {
+			t.Errorf("Alert not reported correctly: %+v", alert)
+		}
+
+		if alert.Annotations["summary"] != "api-1:8080 is down" {
+			t.Errorf("Expected expanded summary, got '%s'", alert.Annotations["summary"])
+		}
+
+		if alert.ActiveAt != ts.Unix() {
+			t.Errorf("Expected active_at %d, got %d", ts.Unix(), alert.ActiveAt)
+		}
+	})
+
+	t.Run("Reports rule groups", func(t *testing.T) {
+		resp, err := server.GetRules(context.Background(), &pb.GetRulesRequest{})
+		if err != nil {
+			t.Fatalf("GetRules failed: %v", err)
+		}
+
+		if len(resp.Groups) != 1 || len(resp.Groups[0].Rules) != 2 {
+			t.Fatalf("Expected 1 group with 2 rules, got %+v", resp.Groups)
+		}
+
+		g := resp.Groups[0]
+		if g.Name != "example" || g.IntervalSeconds != 60 || g.LastEvaluation != ts.Unix() {
+			t.Errorf("Group not reported correctly: %+v", g)
+		}
+
+		if r := g.Rules[0]; r.Type != "recording" || r.Health != "ok" || r.Query != "sum by (job) (up)" {
+			t.Errorf("Recording rule not reported correctly: %+v", r)
+		}
+
+		r := g.Rules[1]
+		if r.Type != "alerting" || r.State != "pending" || r.DurationSeconds != 300 || len(r.Alerts) != 1 {
+			t.Errorf("Alerting rule not reported correctly: %+v", r)
+		}
+	})
+
+	t.Run("Filters by rule type", func(t *testing.T) {
+		resp, err := server.GetRules(context.Background(), &pb.GetRulesRequest{Type: "alert"})
+		if err != nil {
+			t.Fatalf("GetRules failed: %v", err)
+		}
+
+		if len(resp.Groups[0].Rules) != 1 || resp.Groups[0].Rules[0].Name != "InstanceDown" {
+			t.Errorf("Expected only the alerting rule, got %+v", resp.Groups[0].Rules)
+		}
+
+		if _, err := server.GetRules(context.Background(), &pb.GetRulesRequest{Type: "bogus"}); err == nil {
+			t.Error("Expected error for invalid rule type")
+		}
+	})
+}
+
+func TestQueries(t *testing.T) {
+	registry := metrics.NewMetricRegistry()
+	server := NewMetricsServer(registry)
+
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < 3; i++ {
+		for _, instance := range []string{"a:80", "b:80"} {
+			registry.Register(&metrics.Metric{
+				Name:      "requests_total",
+				Type:      metrics.MetricTypeCounter,
+				Value:     float64(10 * (i + 1)),
+				Labels:    map[string]string{"job": "api", "instance": instance},
+				Timestamp: start.Add(time.Duration(i) * time.Minute),
+			})
+		}
+	}
+
+	t.Run("InstantQuery returns a vector", func(t *testing.T) {
+		resp, err := server.InstantQuery(context.Background(), &pb.InstantQueryRequest{
+			Query: `sum by (job) (requests_total)`,
+			Time:  start.Add(2 * time.Minute).UnixMilli(),
+		})
+		if err != nil {
+			t.Fatalf("InstantQuery failed: %v", err)
+		}
+
+		if resp.ResultType != "vector" || len(resp.Result) != 1 {
+			t.Fatalf("Expected vector with 1 series, got %s with %d", resp.ResultType, len(resp.Result))
+		}
+		series := resp.Result[0]
+		if series.Labels["job"] != "api" || len(series.Points) != 1 || series.Points[0].Value != 60 {
+			t.Errorf("Expected {job=\"api\"} 60, got %v", series)
+		}
+	})
+
+	t.Run("InstantQuery returns a scalar", func(t *testing.T) {
+		resp, err := server.InstantQuery(context.Background(), &pb.InstantQueryRequest{Query: `1 + 2`})
+		if err != nil {
+			t.Fatalf("InstantQuery failed: %v", err)
+		}
+
+		if resp.ResultType != "scalar" || len(resp.Result) != 1 || resp.Result[0].Points[0].Value != 3 {
+			t.Errorf("Expected scalar 3, got %v", resp)
+		}
+	})
+
+	t.Run("RangeQuery returns a matrix", func(t *testing.T) {
+		resp, err := server.RangeQuery(context.Background(), &pb.RangeQueryRequest{
+			Query: `requests_total{instance="a:80"}`,
+			Start: start.UnixMilli(),
+			End:   start.Add(2 * time.Minute).UnixMilli(),
+			Step:  time.Minute.Milliseconds(),
+		})
+		if err != nil {
+			t.Fatalf("RangeQuery failed: %v", err)
+		}
+
+		if resp.ResultType != "matrix" || len(resp.Result) != 1 {
+			t.Fatalf("Expected matrix with 1 series, got %s with %d", resp.ResultType, len(resp.Result))
+		}
+		points := resp.Result[0].Points
+		if len(points) != 3 || points[0].Value != 10 || points[2].Value != 30 || points[1].Timestamp != start.Add(time.Minute).UnixMilli() {
+			t.Errorf("Expected points 10, 20, 30 one minute apart, got %v", points)
+		}
+	})
+
+	t.Run("Invalid queries are rejected", func(t *testing.T) {
+		if _, err := server.InstantQuery(context.Background(), &pb.InstantQueryRequest{Query: `sum(`}); err == nil {
+			t.Error("Expected error for invalid expression")
+		}
+		if _, err := server.RangeQuery(context.Background(), &pb.RangeQueryRequest{Query: `up`, Start: 1000, End: 2000}); err == nil {
+			t.Error("Expected error for zero step")
+		}
+		year := 365 * 24 * time.Hour
+		_, err := server.RangeQuery(context.Background(), &pb.RangeQueryRequest{Query: `up`, Start: start.UnixMilli(), End: start.Add(year).UnixMilli(), Step: 1})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for too many points, got %v", err)
+		}
+	})
+
+	t.Run("RangeQuery stops when the client is gone", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := server.RangeQuery(ctx, &pb.RangeQueryRequest{Query: `up`, Start: start.UnixMilli(), End: start.Add(time.Hour).UnixMilli(), Step: 1000})
+		if status.Code(err) != codes.Canceled {
+			t.Errorf("Expected Canceled, got %v", err)
+		}
+	})
+}
+
+func TestGetTSDBStatus(t *testing.T) {
+	registry := metrics.NewMetricRegistry()
+	server := NewMetricsServer(registry)
+
+	start := time.Unix(1700000000, 0)
+	register := func(name string, labels map[string]string, ts time.Time) {
+		registry.Register(&metrics.Metric{Name: name, Labels: labels, Timestamp: ts})
+	}
+	register("http_requests_total", map[string]string{"job": "api", "path": "/a"}, start)
+	register("http_requests_total", map[string]string{"job": "api", "path": "/a"}, start.Add(time.Minute))
+	register("http_requests_total", map[string]string{"job": "api", "path": "/b"}, start)
+	register("http_requests_total", map[string]string{"job": "web", "path": "/c"}, start)
+	register("up", map[string]string{"job": "api"}, start.Add(2*time.Minute))
+
+	resp, err := server.GetTSDBStatus(context.Background(), &pb.GetTSDBStatusRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("GetTSDBStatus failed: %v", err)
+	}
+
+	if resp.NumSeries != 4 || resp.NumSamples != 5 || resp.NumLabelPairs != 5 {
+		t.Errorf("Expected 4 series, 5 samples and 5 label pairs, got %d, %d and %d", resp.NumSeries, resp.NumSamples, resp.NumLabelPairs)
+	}
+	if resp.MinTime != start.UnixMilli() || resp.MaxTime != start.Add(2*time.Minute).UnixMilli() {
+		t.Errorf("Expected time range %d to %d, got %d to %d", start.UnixMilli(), start.Add(2*time.Minute).UnixMilli(), resp.MinTime, resp.MaxTime)
+	}
+
+	byName := resp.SeriesCountByMetricName
+	if len(byName) != 2 || byName[0].Name != "http_requests_total" || byName[0].Value != 3 || byName[1].Name != "up" {
+		t.Errorf("Unexpected series count by metric name: %v", byName)
+	}
+	byLabel := resp.LabelValueCountByLabelName
+	if len(byLabel) != 2 || byLabel[0].Name != "path" || byLabel[0].Value != 3 || byLabel[1].Name != "job" || byLabel[1].Value != 2 {
+		t.Errorf("Unexpected label value count by label name: %v", byLabel)
+	}
+	byPair := resp.SeriesCountByLabelValuePair
+	if len(byPair) != 2 || byPair[0].Name != "job=api" || byPair[0].Value != 3 {
+		t.Errorf("Unexpected series count by label pair: %v", byPair)
+	}
+}
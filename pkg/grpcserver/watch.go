@@ -0,0 +1,85 @@
+package grpcserver
+
+import (
+	"sort"
+
+	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// watchBufferSize is how many samples a watcher may fall behind before
+	// it is disconnected
+	watchBufferSize = 1024
+	// maxWatchBatch bounds the number of samples sent in one message
+	maxWatchBatch = 500
+)
+
+// WatchMetrics sends the latest sample of every series matching the
+// selector, then batches of new samples of matching series as they are
+// registered. A stream that cannot keep up is ended with ResourceExhausted.
+func (s *MetricsServer) WatchMetrics(req *pb.WatchMetricsRequest, stream grpc.ServerStreamingServer[pb.WatchMetricsResponse]) error {
+	matchers, err := query.ParseSelector(req.Selector)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Subscribe before taking the snapshot so no sample is missed in between
+	sub := s.registry.Subscribe(matchers, watchBufferSize)
+	defer sub.Close()
+
+	if err := stream.Send(&pb.WatchMetricsResponse{Metrics: snapshot(s.registry, matchers)}); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-sub.Done():
+			return status.Error(codes.ResourceExhausted, sub.Err().Error())
+		case m := <-sub.C():
+			batch := []*pb.Metric{metricToProto(m)}
+		drain:
+			for len(batch) < maxWatchBatch {
+				select {
+				case m := <-sub.C():
+					batch = append(batch, metricToProto(m))
+				default:
+					break drain
+				}
+			}
+			if err := stream.Send(&pb.WatchMetricsResponse{Metrics: batch}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// snapshot returns the latest sample of every series matching all matchers,
+// sorted by name
+func snapshot(registry *metrics.MetricRegistry, matchers []*metrics.Matcher) []*pb.Metric {
+	var result []*pb.Metric
+	for _, m := range registry.GetAll() {
+		if metrics.MatchesAll(matchers, m.Name, m.Labels) {
+			result = append(result, metricToProto(m))
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func metricToProto(m *metrics.Metric) *pb.Metric {
+	return &pb.Metric{
+		Name:      m.Name,
+		Type:      string(m.Type),
+		Value:     m.Value,
+		Labels:    m.Labels,
+		Timestamp: m.Timestamp.Unix(),
+	}
+}
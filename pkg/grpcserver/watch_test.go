@@ -0,0 +1,130 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves the metrics server over an in-memory connection
+func newTestClient(t *testing.T, registry *metrics.MetricRegistry) pb.MetricsServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterMetricsServiceServer(srv, NewMetricsServer(registry))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewMetricsServiceClient(conn)
+}
+
+func TestWatchMetrics(t *testing.T) {
+	registry := metrics.NewMetricRegistry()
+	registry.Register(&metrics.Metric{Name: "up", Type: metrics.MetricTypeGauge, Value: 1, Labels: map[string]string{"job": "api"}})
+	registry.Register(&metrics.Metric{Name: "up", Type: metrics.MetricTypeGauge, Value: 1, Labels: map[string]string{"job": "web"}})
+	client := newTestClient(t, registry)
+
+	t.Run("Sends snapshot then updates", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		stream, err := client.WatchMetrics(ctx, &pb.WatchMetricsRequest{Selector: `up{job="api"}`})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(resp.Metrics) != 1 || resp.Metrics[0].Labels["job"] != "api" {
+			t.Fatalf("Expected snapshot with up{job=\"api\"}, got %v", resp.Metrics)
+		}
+
+		registry.Register(&metrics.Metric{Name: "up", Type: metrics.MetricTypeGauge, Value: 0, Labels: map[string]string{"job": "web"}})
+		registry.Register(&metrics.Metric{Name: "up", Type: metrics.MetricTypeGauge, Value: 0, Labels: map[string]string{"job": "api"}})
+
+		resp, err = stream.Recv()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(resp.Metrics) != 1 || resp.Metrics[0].Labels["job"] != "api" || resp.Metrics[0].Value != 0 {
+			t.Errorf("Expected update of up{job=\"api\"} to 0, got %v", resp.Metrics)
+		}
+	})
+
+	t.Run("Invalid selector", func(t *testing.T) {
+		stream, err := client.WatchMetrics(context.Background(), &pb.WatchMetricsRequest{Selector: `up{`})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("Slow consumer is disconnected", func(t *testing.T) {
+		stream := &blockingStream{ctx: context.Background(), started: make(chan struct{}), release: make(chan struct{})}
+		errc := make(chan error, 1)
+		go func() {
+			errc <- NewMetricsServer(registry).WatchMetrics(&pb.WatchMetricsRequest{Selector: "flood"}, stream)
+		}()
+
+		// The first update blocks in Send while more samples arrive than
+		// fit in the buffer
+		<-stream.started
+		for i := 0; i < 3*watchBufferSize; i++ {
+			registry.Register(&metrics.Metric{Name: "flood", Value: float64(i)})
+		}
+		close(stream.release)
+
+		select {
+		case err := <-errc:
+			if status.Code(err) != codes.ResourceExhausted {
+				t.Errorf("Expected ResourceExhausted, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for slow consumer to be disconnected")
+		}
+	})
+}
+
+// blockingStream is a WatchMetrics stream whose sends after the snapshot
+// block until release is closed
+type blockingStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	started  chan struct{}
+	release  chan struct{}
+	snapshot bool
+}
+
+func (s *blockingStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *blockingStream) Send(*pb.WatchMetricsResponse) error {
+	if !s.snapshot {
+		s.snapshot = true
+		close(s.started)
+		return nil
+	}
+	<-s.release
+	return nil
+}
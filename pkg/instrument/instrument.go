@@ -0,0 +1,326 @@
+package instrument
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Type is the type of a metric family
+type Type string
+
+const (
+	Counter   Type = "counter"
+	Gauge     Type = "gauge"
+	Histogram Type = "histogram"
+)
+
+// Family is a named group of samples sharing a type and help text
+type Family struct {
+	Name    string
+	Help    string
+	Type    Type
+	Samples []Sample
+}
+
+// Sample is a single value of a family
+type Sample struct {
+	// Suffix is appended to the family name, e.g. _bucket for histograms
+	Suffix string
+	Labels map[string]string
+	Value  float64
+}
+
+// Collector produces metric families each time the registry is scraped
+type Collector interface {
+	Collect() []Family
+}
+
+// Registry holds the collectors exposing the metrics of the process itself
+type Registry struct {
+	mu         sync.Mutex
+	collectors []Collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// MustRegister adds collectors to the registry
+func (r *Registry) MustRegister(cs ...Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.collectors = append(r.collectors, cs...)
+}
+
+// Gather collects all families sorted by name. It panics if two collectors
+// produce a family of the same name.
+func (r *Registry) Gather() []Family {
+	r.mu.Lock()
+	collectors := r.collectors
+	r.mu.Unlock()
+
+	var families []Family
+	seen := make(map[string]bool)
+	for _, c := range collectors {
+		for _, f := range c.Collect() {
+			if seen[f.Name] {
+				panic(fmt.Sprintf("duplicate metric family %s", f.Name))
+			}
+			seen[f.Name] = true
+			families = append(families, f)
+		}
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].Name < families[j].Name })
+	return families
+}
+
+// ServeHTTP writes all families in the text exposition format
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	var buf bytes.Buffer
+	for _, f := range r.Gather() {
+		writeFamily(&buf, f)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
+
+func writeFamily(buf *bytes.Buffer, f Family) {
+	if f.Help != "" {
+		fmt.Fprintf(buf, "# HELP %s %s\n", f.Name, helpEscaper.Replace(f.Help))
+	}
+	fmt.Fprintf(buf, "# TYPE %s %s\n", f.Name, f.Type)
+	for _, s := range f.Samples {
+		buf.WriteString(f.Name + s.Suffix)
+		if len(s.Labels) > 0 {
+			buf.WriteString("{" + formatLabels(s.Labels) + "}")
+		}
+		buf.WriteString(" " + formatValue(s.Value) + "\n")
+	}
+}
+
+// formatLabels renders labels sorted by name with escaped values
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+`="`+labelValueEscaper.Replace(v)+`"`)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+// formatValue renders a sample value as in the text exposition format
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// funcCollector reads its samples from a function at collection time
+type funcCollector struct {
+	name, help string
+	typ        Type
+	fn         func() []Sample
+}
+
+// NewFunc creates a collector whose samples are returned by fn, called on
+// every collection. It exposes values counted elsewhere, such as the stats
+// of a component.
+func NewFunc(name, help string, typ Type, fn func() []Sample) Collector {
+	return &funcCollector{name: name, help: help, typ: typ, fn: fn}
+}
+
+// NewGaugeFunc creates a gauge whose value is returned by fn
+func NewGaugeFunc(name, help string, fn func() float64) Collector {
+	return NewFunc(name, help, Gauge, func() []Sample {
+		return []Sample{{Value: fn()}}
+	})
+}
+
+// NewCounterFunc creates a counter whose value is returned by fn
+func NewCounterFunc(name, help string, fn func() float64) Collector {
+	return NewFunc(name, help, Counter, func() []Sample {
+		return []Sample{{Value: fn()}}
+	})
+}
+
+func (c *funcCollector) Collect() []Family {
+	return []Family{{Name: c.name, Help: c.help, Type: c.typ, Samples: c.fn()}}
+}
+
+// vec tracks one value per combination of label values
+type vec[T any] struct {
+	name, help string
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]*labeled[T]
+	keys   []string
+}
+
+type labeled[T any] struct {
+	labels map[string]string
+	value  T
+}
+
+func newVec[T any](name, help string, labelNames []string) vec[T] {
+	return vec[T]{name: name, help: help, labelNames: labelNames, values: make(map[string]*labeled[T])}
+}
+
+// with returns the value for the label values, creating it with init if
+// needed. Must be called with v.mu held.
+func (v *vec[T]) with(labelValues []string, init func() T) *T {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("metric %s: expected %d label values, got %d", v.name, len(v.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	l, ok := v.values[key]
+	if !ok {
+		labels := make(map[string]string, len(labelValues))
+		for i, name := range v.labelNames {
+			labels[name] = labelValues[i]
+		}
+		l = &labeled[T]{labels: labels, value: init()}
+		v.values[key] = l
+		v.keys = append(v.keys, key)
+		sort.Strings(v.keys)
+	}
+	return &l.value
+}
+
+// CounterVec is a counter partitioned by labels
+type CounterVec struct {
+	vec[float64]
+}
+
+// NewCounterVec creates a counter with the given label names
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{newVec[float64](name, help, labelNames)}
+}
+
+// Add increases the counter for the label values by delta, which must not
+// be negative
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metric %s: counter cannot decrease", c.name))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	*c.with(labelValues, func() float64 { return 0 }) += delta
+}
+
+// Inc increases the counter for the label values by one
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Value returns the counter for the label values
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return *c.with(labelValues, func() float64 { return 0 })
+}
+
+func (c *CounterVec) Collect() []Family {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f := Family{Name: c.name, Help: c.help, Type: Counter}
+	for _, key := range c.keys {
+		l := c.values[key]
+		f.Samples = append(f.Samples, Sample{Labels: l.labels, Value: l.value})
+	}
+	return []Family{f}
+}
+
+// DefBuckets are the default histogram buckets in seconds, suited to
+// request latencies
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// histogram holds the observations of one label combination. counts[i] is
+// the number of observations in bucket i alone, not cumulative.
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// HistogramVec is a histogram partitioned by labels
+type HistogramVec struct {
+	vec[histogram]
+	buckets []float64
+}
+
+// NewHistogramVec creates a histogram with the given upper bucket bounds,
+// which must be sorted, and label names. A +Inf bucket is always added.
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	return &HistogramVec{vec: newVec[histogram](name, help, labelNames), buckets: buckets}
+}
+
+// Observe adds a value to the histogram for the label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hist := h.with(labelValues, func() histogram {
+		return histogram{counts: make([]uint64, len(h.buckets))}
+	})
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		hist.counts[i]++
+	}
+	hist.count++
+	hist.sum += value
+}
+
+func (h *HistogramVec) Collect() []Family {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f := Family{Name: h.name, Help: h.help, Type: Histogram}
+	for _, key := range h.keys {
+		l := h.values[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += l.value.counts[i]
+			f.Samples = append(f.Samples, Sample{Suffix: "_bucket", Labels: withLabel(l.labels, "le", formatValue(bound)), Value: float64(cumulative)})
+		}
+		f.Samples = append(f.Samples,
+			Sample{Suffix: "_bucket", Labels: withLabel(l.labels, "le", "+Inf"), Value: float64(l.value.count)},
+			Sample{Suffix: "_sum", Labels: l.labels, Value: l.value.sum},
+			Sample{Suffix: "_count", Labels: l.labels, Value: float64(l.value.count)},
+		)
+	}
+	return []Family{f}
+}
+
+// withLabel returns a copy of labels with one more label
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	res := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		res[k] = v
+	}
+	res[name] = value
+	return res
+}
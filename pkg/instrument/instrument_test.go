@@ -0,0 +1,78 @@
+package instrument
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	requests := NewCounterVec("requests_total", "Requests handled.", "handler", "code")
+	requests.Inc("/metrics", "200")
+	requests.Add(2, "/metrics", "200")
+	requests.Inc("/api", "500")
+
+	duration := NewHistogramVec("request_duration_seconds", "Request latency.", []float64{0.1, 1}, "handler")
+	duration.Observe(0.05, "/api")
+	duration.Observe(0.1, "/api")
+	duration.Observe(3, "/api")
+
+	registry := NewRegistry()
+	registry.MustRegister(requests, duration, NewGaugeFunc("queue_length", "Queued items.\nLine two.", func() float64 { return 7 }))
+
+	t.Run("Writes text format sorted by family", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		registry.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/-/metrics", nil))
+
+		if ct := rec.Header().Get("Content-Type"); ct != contentType {
+			t.Errorf("Expected content type %q, got %q", contentType, ct)
+		}
+		expected := `# HELP queue_length Queued items.\nLine two.
+# TYPE queue_length gauge
+queue_length 7
+# HELP request_duration_seconds Request latency.
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{handler="/api",le="0.1"} 2
+request_duration_seconds_bucket{handler="/api",le="1"} 2
+request_duration_seconds_bucket{handler="/api",le="+Inf"} 3
+request_duration_seconds_sum{handler="/api"} 3.15
+request_duration_seconds_count{handler="/api"} 3
+# HELP requests_total Requests handled.
+# TYPE requests_total counter
+requests_total{code="500",handler="/api"} 1
+requests_total{code="200",handler="/metrics"} 3
+`
+		if got := rec.Body.String(); got != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+
+	t.Run("Counter value", func(t *testing.T) {
+		if v := requests.Value("/metrics", "200"); v != 3 {
+			t.Errorf("Expected 3, got %v", v)
+		}
+	})
+
+	t.Run("Wrong number of label values panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic")
+			}
+		}()
+		requests.Inc("/metrics")
+	})
+
+	t.Run("Go collector", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		r := NewRegistry()
+		r.MustRegister(NewGoCollector())
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/-/metrics", nil))
+
+		for _, name := range []string{"go_goroutines ", "go_memstats_alloc_bytes ", "process_start_time_seconds "} {
+			if !strings.Contains(rec.Body.String(), "\n"+name) {
+				t.Errorf("Expected %s in output:\n%s", name, rec.Body.String())
+			}
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package instrument
+
+import (
+	"runtime"
+	"time"
+)
+
+// goCollector exposes Go runtime and process statistics
+type goCollector struct {
+	start time.Time
+}
+
+// NewGoCollector creates a collector for the goroutine count, memory and
+// garbage collection statistics of the Go runtime and the start time of the
+// process
+func NewGoCollector() Collector {
+	return &goCollector{start: time.Now()}
+}
+
+func (c *goCollector) Collect() []Family {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	gauge := func(name, help string, value float64) Family {
+		return Family{Name: name, Help: help, Type: Gauge, Samples: []Sample{{Value: value}}}
+	}
+	counter := func(name, help string, value float64) Family {
+		return Family{Name: name, Help: help, Type: Counter, Samples: []Sample{{Value: value}}}
+	}
+
+	return []Family{
+		{Name: "go_info", Help: "Information about the Go environment.", Type: Gauge,
+			Samples: []Sample{{Labels: map[string]string{"version": runtime.Version()}, Value: 1}}},
+		gauge("go_goroutines", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine())),
+		gauge("go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", float64(ms.Alloc)),
+		counter("go_memstats_alloc_bytes_total", "Total number of bytes allocated, even if freed.", float64(ms.TotalAlloc)),
+		gauge("go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", float64(ms.HeapInuse)),
+		gauge("go_memstats_heap_objects", "Number of allocated objects.", float64(ms.HeapObjects)),
+		gauge("go_memstats_sys_bytes", "Number of bytes obtained from the system.", float64(ms.Sys)),
+		counter("go_gc_cycles_total", "Number of completed GC cycles.", float64(ms.NumGC)),
+		counter("go_gc_pause_seconds_total", "Total time spent in GC stop-the-world pauses.", float64(ms.PauseTotalNs)/1e9),
+		gauge("process_start_time_seconds", "Start time of the process since unix epoch in seconds.", float64(c.start.UnixNano())/1e9),
+	}
+}
@@ -0,0 +1,222 @@
+package lint
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+var camelCaseRE = regexp.MustCompile(`[a-z][A-Z]`)
+
+// baseUnits maps name components with a non-base unit to the base unit
+// metrics should use instead
+var baseUnits = map[string]string{
+	"nanoseconds":  "seconds",
+	"microseconds": "seconds",
+	"milliseconds": "seconds",
+	"minutes":      "seconds",
+	"hours":        "seconds",
+	"days":         "seconds",
+	"kilobytes":    "bytes",
+	"megabytes":    "bytes",
+	"gigabytes":    "bytes",
+	"terabytes":    "bytes",
+	"percent":      "ratio",
+}
+
+// Problem is a convention a metric family does not follow
+type Problem struct {
+	Metric string
+	Text   string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s %s", p.Metric, p.Text)
+}
+
+// family is the metadata and samples found for one metric name
+type family struct {
+	name    string
+	typ     string
+	help    bool
+	samples int
+}
+
+// Lint checks an exposition in the text format against the Prometheus
+// naming conventions: HELP text, snake_case names, base units and the
+// _total suffix of counters. It returns an error if the exposition cannot
+// be parsed.
+func Lint(r io.Reader) ([]Problem, error) {
+	families, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	for _, f := range families {
+		add := func(format string, args ...any) {
+			problems = append(problems, Problem{Metric: f.name, Text: fmt.Sprintf(format, args...)})
+		}
+
+		if !f.help {
+			add("no help text")
+		}
+		if camelCaseRE.MatchString(f.name) {
+			add(`metric names should be written in 'snake_case' not 'camelCase'`)
+		}
+		if f.typ == "counter" && !strings.HasSuffix(f.name, "_total") {
+			add(`counter metrics should have "_total" suffix`)
+		}
+		if f.typ != "counter" && strings.HasSuffix(f.name, "_total") {
+			add(`non-counter metrics should not have "_total" suffix`)
+		}
+		for _, part := range strings.Split(strings.ToLower(f.name), "_") {
+			if base, ok := baseUnits[part]; ok {
+				add("use base unit %q instead of %q", base, part)
+			}
+		}
+	}
+	return problems, nil
+}
+
+// parse reads the metric families of an exposition in the order they first
+// appear
+func parse(r io.Reader) ([]*family, error) {
+	var families []*family
+	byName := make(map[string]*family)
+	get := func(name string) *family {
+		f, ok := byName[name]
+		if !ok {
+			f = &family{name: name}
+			byName[name] = f
+			families = append(families, f)
+		}
+		return f
+	}
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			fields := strings.Fields(line)
+			if len(fields) < 3 || (fields[1] != "HELP" && fields[1] != "TYPE") {
+				continue
+			}
+			if !metrics.IsValidMetricName(fields[2]) {
+				return nil, fmt.Errorf("line %d: invalid metric name %q", lineNum, fields[2])
+			}
+
+			f := get(fields[2])
+			if fields[1] == "HELP" {
+				f.help = true
+				continue
+			}
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("line %d: TYPE line must have the form '# TYPE <name> <type>'", lineNum)
+			}
+			switch fields[3] {
+			case "counter", "gauge", "histogram", "summary", "untyped":
+			default:
+				return nil, fmt.Errorf("line %d: invalid metric type %q", lineNum, fields[3])
+			}
+			if f.typ != "" || f.samples > 0 {
+				return nil, fmt.Errorf("line %d: TYPE for %s must come once and before its samples", lineNum, f.name)
+			}
+			f.typ = fields[3]
+			continue
+		}
+
+		name, err := parseSample(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		get(familyName(name, byName)).samples++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, f := range families {
+		if f.typ == "" {
+			f.typ = "untyped"
+		}
+	}
+	return families, nil
+}
+
+// familyName returns the family a sample belongs to, which for histograms
+// and summaries is the sample name without its _bucket, _sum or _count
+// suffix
+func familyName(name string, families map[string]*family) string {
+	for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+		base, ok := strings.CutSuffix(name, suffix)
+		if !ok {
+			continue
+		}
+		if f, ok := families[base]; ok && (f.typ == "histogram" || f.typ == "summary") {
+			return base
+		}
+	}
+	return name
+}
+
+// parseSample checks the syntax of a sample line and returns its metric
+// name
+func parseSample(line string) (string, error) {
+	end := strings.IndexAny(line, "{ \t")
+	if end < 0 {
+		return "", fmt.Errorf("sample %q has no value", line)
+	}
+	name, rest := line[:end], line[end:]
+	if !metrics.IsValidMetricName(name) {
+		return "", fmt.Errorf("invalid metric name %q", name)
+	}
+
+	if strings.HasPrefix(rest, "{") {
+		closing, err := labelsEnd(rest)
+		if err != nil {
+			return "", fmt.Errorf("metric %s: %v", name, err)
+		}
+		rest = rest[closing+1:]
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) < 1 || len(fields) > 2 {
+		return "", fmt.Errorf("metric %s: expected a value and an optional timestamp", name)
+	}
+	if _, err := strconv.ParseFloat(fields[0], 64); err != nil {
+		return "", fmt.Errorf("metric %s: invalid value %q", name, fields[0])
+	}
+	if len(fields) == 2 {
+		if _, err := strconv.ParseInt(fields[1], 10, 64); err != nil {
+			return "", fmt.Errorf("metric %s: invalid timestamp %q", name, fields[1])
+		}
+	}
+	return name, nil
+}
+
+// labelsEnd returns the index of the brace closing the label set that s
+// starts with, skipping braces in quoted label values
+func labelsEnd(s string) (int, error) {
+	inQuotes := false
+	for i := 1; i < len(s); i++ {
+		switch {
+		case inQuotes && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && s[i] == '}':
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated label set")
+}
@@ -0,0 +1,90 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	t.Run("Reports convention problems", func(t *testing.T) {
+		input := `# HELP http_requests_total Requests served.
+# TYPE http_requests_total counter
+http_requests_total{path="/a{b}"} 10
+# HELP requests Requests served.
+# TYPE requests counter
+requests 5
+# HELP queue_length_total Items in the queue.
+# TYPE queue_length_total gauge
+queue_length_total 3
+# TYPE cacheHits counter
+cacheHits_total 2
+cacheHits 1
+# HELP request_duration_milliseconds Request latency.
+# TYPE request_duration_milliseconds histogram
+request_duration_milliseconds_bucket{le="+Inf"} 4
+request_duration_milliseconds_sum 12.5
+request_duration_milliseconds_count 4
+`
+
+		problems, err := Lint(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []string{
+			`requests counter metrics should have "_total" suffix`,
+			`queue_length_total non-counter metrics should not have "_total" suffix`,
+			`cacheHits no help text`,
+			`cacheHits metric names should be written in 'snake_case' not 'camelCase'`,
+			`cacheHits counter metrics should have "_total" suffix`,
+			`cacheHits_total no help text`,
+			`cacheHits_total metric names should be written in 'snake_case' not 'camelCase'`,
+			`cacheHits_total non-counter metrics should not have "_total" suffix`,
+			`request_duration_milliseconds use base unit "seconds" instead of "milliseconds"`,
+		}
+		if len(problems) != len(expected) {
+			t.Fatalf("Expected %d problems, got %d: %v", len(expected), len(problems), problems)
+		}
+		for i, p := range problems {
+			if p.String() != expected[i] {
+				t.Errorf("Expected problem %q, got %q", expected[i], p.String())
+			}
+		}
+	})
+
+	t.Run("Clean exposition", func(t *testing.T) {
+		input := `# HELP up Whether the target is up.
+# TYPE up gauge
+up{job="api"} 1 1700000000000
+`
+		problems, err := Lint(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(problems) != 0 {
+			t.Errorf("Expected no problems, got %v", problems)
+		}
+	})
+
+	t.Run("Rejects invalid expositions", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			input string
+		}{
+			{"Missing value", "up\n"},
+			{"Invalid value", "up one\n"},
+			{"Unterminated labels", "up{job=\"a\" 1\n"},
+			{"Invalid type", "# TYPE up meter\nup 1\n"},
+			{"Type after samples", "up 1\n# TYPE up gauge\n"},
+			{"Invalid name", "0up 1\n"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if _, err := Lint(strings.NewReader(tt.input)); err == nil {
+					t.Error("Expected error")
+				}
+			})
+		}
+	})
+}
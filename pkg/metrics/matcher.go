@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// MetricNameLabel is the label name used to match on a metric's name
+const MetricNameLabel = "__name__"
+
+// MatchType is the comparison performed by a label matcher
+type MatchType int
+
+const (
+	MatchEqual MatchType = iota
+	MatchNotEqual
+	MatchRegexp
+	MatchNotRegexp
+)
+
+// String returns the selector operator for the match type
+func (t MatchType) String() string {
+	switch t {
+	case MatchEqual:
+		return "="
+	case MatchNotEqual:
+		return "!="
+	case MatchRegexp:
+		return "=~"
+	case MatchNotRegexp:
+		return "!~"
+	}
+	return fmt.Sprintf("MatchType(%d)", int(t))
+}
+
+// Matcher selects series by comparing the value of one label
+type Matcher struct {
+	Type  MatchType
+	Name  string
+	Value string
+
+	re *regexp.Regexp
+}
+
+// NewMatcher creates a label matcher. Regular expressions are fully anchored.
+func NewMatcher(t MatchType, name, value string) (*Matcher, error) {
+	m := &Matcher{Type: t, Name: name, Value: value}
+	if t == MatchRegexp || t == MatchNotRegexp {
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", value, err)
+		}
+		m.re = re
+	}
+	return m, nil
+}
+
+// Matches reports whether a label value satisfies the matcher. A missing
+// label has the empty value.
+func (m *Matcher) Matches(value string) bool {
+	switch m.Type {
+	case MatchEqual:
+		return value == m.Value
+	case MatchNotEqual:
+		return value != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(value)
+	case MatchNotRegexp:
+		return !m.re.MatchString(value)
+	}
+	return false
+}
+
+// String returns the matcher in selector syntax, e.g. job="api"
+func (m *Matcher) String() string {
+	return m.Name + m.Type.String() + strconv.Quote(m.Value)
+}
+
+// MatchesAll reports whether a series with the given name and labels
+// satisfies every matcher
+func MatchesAll(matchers []*Matcher, name string, labels map[string]string) bool {
+	for _, m := range matchers {
+		value := labels[m.Name]
+		if m.Name == MetricNameLabel {
+			value = name
+		}
+		if !m.Matches(value) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,7 +1,10 @@
 package metrics
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
@@ -23,27 +26,161 @@ type Metric struct {
 	Timestamp time.Time         `json:"timestamp"`
 }
 
-// MetricRegistry stores and manages metrics
+// DefaultRetention is how long the sample history of a series is kept
+const DefaultRetention = time.Hour
+
+// Sample is the value of a series at a point in time
+type Sample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// Series is the sample history of a single metric, oldest sample first
+type Series struct {
+	Name    string
+	Type    MetricType
+	Labels  map[string]string
+	Samples []Sample
+}
+
+// Listener is called with every sample accepted by the registry. It must
+// not modify the metric.
+type Listener func(metric *Metric)
+
+// MetricRegistry stores the latest value of each metric along with a
+// bounded history of its samples
 type MetricRegistry struct {
-	mu      sync.RWMutex
-	metrics map[string]*Metric
+	mu        sync.RWMutex
+	metrics   map[string]*Metric
+	history   map[string][]Sample
+	retention time.Duration
+	listeners []Listener
+
+	subscriptions []*Subscription
 }
 
 // NewMetricRegistry creates a new metric registry
 func NewMetricRegistry() *MetricRegistry {
 	return &MetricRegistry{
-		metrics: make(map[string]*Metric),
+		metrics:   make(map[string]*Metric),
+		history:   make(map[string][]Sample),
+		retention: DefaultRetention,
 	}
 }
 
-// Register adds or updates a metric in the registry
-func (r *MetricRegistry) Register(metric *Metric) {
+// SetRetention sets how long sample history is kept for each series
+func (r *MetricRegistry) SetRetention(retention time.Duration) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.retention = retention
+}
+
+// Retention returns how long sample history is kept for each series
+func (r *MetricRegistry) Retention() time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.retention
+}
+
+// AddListener registers a function called after every accepted sample
+func (r *MetricRegistry) AddListener(l Listener) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.listeners = append(r.listeners, l)
+}
+
+// ErrOutOfOrderSample is returned for a sample older than the latest sample
+// of its series
+var ErrOutOfOrderSample = errors.New("out of order sample")
+
+// Register adds or updates a metric in the registry. Metrics without a
+// timestamp are stamped with the current time. Samples older than the
+// latest sample of the series are dropped.
+func (r *MetricRegistry) Register(metric *Metric) {
+	r.Append(metric)
+}
+
+// Append adds a sample like Register but reports samples that are dropped
+// because they are out of order
+func (r *MetricRegistry) Append(metric *Metric) error {
+	r.mu.Lock()
 	key := r.generateKey(metric.Name, metric.Labels)
-	metric.Timestamp = time.Now()
+	if metric.Timestamp.IsZero() {
+		metric.Timestamp = time.Now()
+	}
+	if existing, ok := r.metrics[key]; ok && metric.Timestamp.Before(existing.Timestamp) {
+		r.mu.Unlock()
+		return ErrOutOfOrderSample
+	}
 	r.metrics[key] = metric
+	r.appendSample(key, Sample{Timestamp: metric.Timestamp, Value: metric.Value})
+	listeners := r.listeners
+	subscriptions := r.subscriptions
+	r.mu.Unlock()
+
+	// Listeners run outside the lock so they may read the registry
+	for _, l := range listeners {
+		l(metric)
+	}
+	for _, sub := range subscriptions {
+		sub.publish(metric)
+	}
+	return nil
+}
+
+// appendSample adds a sample to a series' history and drops samples that
+// have fallen out of the retention window. Must be called with r.mu held.
+func (r *MetricRegistry) appendSample(key string, sample Sample) {
+	samples := r.history[key]
+	if n := len(samples); n > 0 && samples[n-1].Timestamp.Equal(sample.Timestamp) {
+		samples[n-1] = sample
+		return
+	}
+	samples = append(samples, sample)
+
+	cutoff := sample.Timestamp.Add(-r.retention)
+	drop := 0
+	for drop < len(samples) && samples[drop].Timestamp.Before(cutoff) {
+		drop++
+	}
+	if drop > 0 {
+		samples = append([]Sample(nil), samples[drop:]...)
+	}
+	r.history[key] = samples
+}
+
+// Select returns the samples between start and end, inclusive, of every
+// series matching all matchers. Series without samples in the range are
+// omitted. Selecting from memory never blocks, so ctx is unused; it lets the
+// registry be queried like remote storage.
+func (r *MetricRegistry) Select(_ context.Context, matchers []*Matcher, start, end time.Time) []*Series {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*Series
+	for key, metric := range r.metrics {
+		if !MatchesAll(matchers, metric.Name, metric.Labels) {
+			continue
+		}
+
+		samples := r.history[key]
+		lo := sort.Search(len(samples), func(i int) bool { return !samples[i].Timestamp.Before(start) })
+		hi := sort.Search(len(samples), func(i int) bool { return samples[i].Timestamp.After(end) })
+		if lo >= hi {
+			continue
+		}
+
+		result = append(result, &Series{
+			Name:    metric.Name,
+			Type:    metric.Type,
+			Labels:  metric.Labels,
+			Samples: append([]Sample(nil), samples[lo:hi]...),
+		})
+	}
+	return result
 }
 
 // Get retrieves a metric by name and labels
@@ -68,12 +205,55 @@ func (r *MetricRegistry) GetAll() []*Metric {
 	return result
 }
 
+// Stats summarizes the contents of a registry
+type Stats struct {
+	NumSeries  int
+	NumSamples int
+	// MinTime and MaxTime are the timestamps of the oldest and newest
+	// sample, zero if the registry is empty
+	MinTime time.Time
+	MaxTime time.Time
+}
+
+// Stats returns the number of series and samples held in the registry and
+// the time range they cover
+func (r *MetricRegistry) Stats() Stats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := Stats{NumSeries: len(r.metrics)}
+	for _, samples := range r.history {
+		if len(samples) == 0 {
+			continue
+		}
+		stats.NumSamples += len(samples)
+		if first := samples[0].Timestamp; stats.MinTime.IsZero() || first.Before(stats.MinTime) {
+			stats.MinTime = first
+		}
+		if last := samples[len(samples)-1].Timestamp; last.After(stats.MaxTime) {
+			stats.MaxTime = last
+		}
+	}
+	return stats
+}
+
+// Delete removes a series and its sample history from the registry
+func (r *MetricRegistry) Delete(name string, labels map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := r.generateKey(name, labels)
+	delete(r.metrics, key)
+	delete(r.history, key)
+}
+
 // Clear removes all metrics from the registry
 func (r *MetricRegistry) Clear() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	r.metrics = make(map[string]*Metric)
+	r.history = make(map[string][]Sample)
 }
 
 // generateKey creates a unique key for a metric based on name and labels
@@ -82,9 +262,16 @@ func (r *MetricRegistry) generateKey(name string, labels map[string]string) stri
 		return name
 	}
 
+	// Sort label names so the key doesn't depend on map iteration order
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
 	key := name
-	for k, v := range labels {
-		key += fmt.Sprintf(",%s=%s", k, v)
+	for _, k := range names {
+		key += fmt.Sprintf(",%s=%s", k, labels[k])
 	}
 	return key
 }
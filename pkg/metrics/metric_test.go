@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -137,4 +138,275 @@ func TestMetricRegistry(t *testing.T) {
 			t.Error("Timestamp not set correctly")
 		}
 	})
+
+	t.Run("Explicit timestamp is kept", func(t *testing.T) {
+		registry.Clear()
+
+		ts := time.Unix(1700000000, 0)
+		registry.Register(&Metric{
+			Name:      "explicit_timestamp_test",
+			Type:      MetricTypeGauge,
+			Value:     1.0,
+			Timestamp: ts,
+		})
+
+		retrieved, exists := registry.Get("explicit_timestamp_test", nil)
+		if !exists {
+			t.Fatal("Expected metric to exist")
+		}
+
+		if !retrieved.Timestamp.Equal(ts) {
+			t.Errorf("Expected timestamp %v, got %v", ts, retrieved.Timestamp)
+		}
+	})
+
+	t.Run("Sample history and Select", func(t *testing.T) {
+		registry.Clear()
+
+		start := time.Unix(1700000000, 0)
+		for i := 0; i < 5; i++ {
+			registry.Register(&Metric{
+				Name:      "history_test",
+				Type:      MetricTypeCounter,
+				Value:     float64(i),
+				Labels:    map[string]string{"job": "api"},
+				Timestamp: start.Add(time.Duration(i) * time.Minute),
+			})
+		}
+
+		// Out-of-order samples are dropped
+		registry.Register(&Metric{
+			Name:      "history_test",
+			Type:      MetricTypeCounter,
+			Value:     100,
+			Labels:    map[string]string{"job": "api"},
+			Timestamp: start,
+		})
+
+		matcher, err := NewMatcher(MatchEqual, "job", "api")
+		if err != nil {
+			t.Fatal(err)
+		}
+		series := registry.Select(context.Background(), []*Matcher{matcher}, start.Add(time.Minute), start.Add(3*time.Minute))
+		if len(series) != 1 {
+			t.Fatalf("Expected 1 series, got %d", len(series))
+		}
+		if len(series[0].Samples) != 3 {
+			t.Fatalf("Expected 3 samples, got %d", len(series[0].Samples))
+		}
+		if series[0].Samples[0].Value != 1 {
+			t.Errorf("Expected first sample value 1, got %v", series[0].Samples[0].Value)
+		}
+
+		retrieved, _ := registry.Get("history_test", map[string]string{"job": "api"})
+		if retrieved.Value != 4 {
+			t.Errorf("Expected latest value 4, got %v", retrieved.Value)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		registry.Clear()
+
+		registry.Register(&Metric{Name: "delete_test", Value: 1, Labels: map[string]string{"a": "1"}})
+		registry.Register(&Metric{Name: "delete_test", Value: 2, Labels: map[string]string{"a": "2"}})
+		registry.Delete("delete_test", map[string]string{"a": "1"})
+
+		if _, exists := registry.Get("delete_test", map[string]string{"a": "1"}); exists {
+			t.Error("Expected deleted metric to be gone")
+		}
+		if _, exists := registry.Get("delete_test", map[string]string{"a": "2"}); !exists {
+			t.Error("Expected other metric to remain")
+		}
+		if series := registry.Select(context.Background(), nil, time.Time{}, time.Now()); len(series) != 1 {
+			t.Errorf("Expected 1 series with history, got %d", len(series))
+		}
+	})
+
+	t.Run("Retention", func(t *testing.T) {
+		registry.Clear()
+		registry.SetRetention(2 * time.Minute)
+		defer registry.SetRetention(DefaultRetention)
+
+		start := time.Unix(1700000000, 0)
+		for i := 0; i < 5; i++ {
+			registry.Register(&Metric{
+				Name:      "retention_test",
+				Value:     float64(i),
+				Timestamp: start.Add(time.Duration(i) * time.Minute),
+			})
+		}
+
+		series := registry.Select(context.Background(), nil, start, start.Add(time.Hour))
+		if len(series) != 1 || len(series[0].Samples) != 3 {
+			t.Errorf("Expected 3 retained samples, got %v", series)
+		}
+	})
+	t.Run("Listeners", func(t *testing.T) {
+		registry := NewMetricRegistry()
+
+		var accepted []float64
+		registry.AddListener(func(m *Metric) {
+			accepted = append(accepted, m.Value)
+		})
+
+		start := time.Unix(1700000000, 0)
+		registry.Register(&Metric{Name: "listener_test", Value: 1, Timestamp: start})
+		registry.Register(&Metric{Name: "listener_test", Value: 2, Timestamp: start.Add(time.Minute)})
+		// Out-of-order samples are not accepted
+		if err := registry.Append(&Metric{Name: "listener_test", Value: 3, Timestamp: start}); err != ErrOutOfOrderSample {
+			t.Errorf("Expected ErrOutOfOrderSample, got %v", err)
+		}
+
+		if len(accepted) != 2 || accepted[0] != 1 || accepted[1] != 2 {
+			t.Errorf("Expected listener to see accepted samples [1 2], got %v", accepted)
+		}
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		registry := NewMetricRegistry()
+		if stats := registry.Stats(); stats.NumSeries != 0 || !stats.MinTime.IsZero() {
+			t.Errorf("Expected empty stats, got %+v", stats)
+		}
+
+		start := time.Unix(1700000000, 0)
+		registry.Register(&Metric{Name: "stats_test", Value: 1, Labels: map[string]string{"a": "1"}, Timestamp: start})
+		registry.Register(&Metric{Name: "stats_test", Value: 2, Labels: map[string]string{"a": "1"}, Timestamp: start.Add(time.Minute)})
+		registry.Register(&Metric{Name: "stats_test", Value: 3, Labels: map[string]string{"a": "2"}, Timestamp: start.Add(2 * time.Minute)})
+
+		stats := registry.Stats()
+		if stats.NumSeries != 2 || stats.NumSamples != 3 {
+			t.Errorf("Expected 2 series and 3 samples, got %+v", stats)
+		}
+		if !stats.MinTime.Equal(start) || !stats.MaxTime.Equal(start.Add(2*time.Minute)) {
+			t.Errorf("Expected time range %v to %v, got %v to %v", start, start.Add(2*time.Minute), stats.MinTime, stats.MaxTime)
+		}
+	})
+}
+
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		matchType MatchType
+		value     string
+		input     string
+		expected  bool
+	}{
+		{MatchEqual, "api", "api", true},
+		{MatchEqual, "api", "web", false},
+		{MatchNotEqual, "api", "web", true},
+		{MatchRegexp, "a.*", "api", true},
+		{MatchRegexp, "p", "api", false},
+		{MatchNotRegexp, "a.*", "web", true},
+		{MatchEqual, "", "", true},
+	}
+
+	for _, tt := range tests {
+		m, err := NewMatcher(tt.matchType, "job", tt.value)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := m.Matches(tt.input); got != tt.expected {
+			t.Errorf("Expected %s to match %q: %v, got %v", m, tt.input, tt.expected, got)
+		}
+	}
+
+	if _, err := NewMatcher(MatchRegexp, "job", "("); err == nil {
+		t.Error("Expected error for invalid regular expression")
+	}
+}
+
+func TestValidNames(t *testing.T) {
+	tests := []struct {
+		name       string
+		metricName bool
+		labelName  bool
+	}{
+		{"http_requests_total", true, true},
+		{"job:up:sum", true, false},
+		{"_private", true, true},
+		{"1up", false, false},
+		{"bad-name", false, false},
+		{"", false, false},
+	}
+	for _, tt := range tests {
+		if got := IsValidMetricName(tt.name); got != tt.metricName {
+			t.Errorf("Expected IsValidMetricName(%q) to be %v, got %v", tt.name, tt.metricName, got)
+		}
+		if got := IsValidLabelName(tt.name); got != tt.labelName {
+			t.Errorf("Expected IsValidLabelName(%q) to be %v, got %v", tt.name, tt.labelName, got)
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	jobAPI, err := NewMatcher(MatchEqual, "job", "api")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Delivers matching samples", func(t *testing.T) {
+		registry := NewMetricRegistry()
+		sub := registry.Subscribe([]*Matcher{jobAPI}, 10)
+		defer sub.Close()
+
+		registry.Register(&Metric{Name: "up", Value: 1, Labels: map[string]string{"job": "api"}})
+		registry.Register(&Metric{Name: "up", Value: 1, Labels: map[string]string{"job": "web"}})
+		registry.Register(&Metric{Name: "requests_total", Value: 5, Labels: map[string]string{"job": "api"}})
+
+		for _, name := range []string{"up", "requests_total"} {
+			select {
+			case m := <-sub.C():
+				if m.Name != name || m.Labels["job"] != "api" {
+					t.Errorf("Expected %s{job=\"api\"}, got %s%v", name, m.Name, m.Labels)
+				}
+			default:
+				t.Fatalf("Expected sample of %s", name)
+			}
+		}
+		select {
+		case m := <-sub.C():
+			t.Errorf("Expected no more samples, got %s%v", m.Name, m.Labels)
+		default:
+		}
+	})
+
+	t.Run("Close stops delivery", func(t *testing.T) {
+		registry := NewMetricRegistry()
+		sub := registry.Subscribe(nil, 10)
+		sub.Close()
+		sub.Close()
+
+		registry.Register(&Metric{Name: "up", Value: 1})
+		if len(sub.C()) != 0 {
+			t.Errorf("Expected no samples after close, got %d", len(sub.C()))
+		}
+		if err := sub.Err(); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("Slow consumer is disconnected", func(t *testing.T) {
+		registry := NewMetricRegistry()
+		slow := registry.Subscribe(nil, 2)
+		fast := registry.Subscribe(nil, 10)
+		defer fast.Close()
+
+		for i := 0; i < 3; i++ {
+			registry.Register(&Metric{Name: "up", Value: float64(i)})
+		}
+
+		select {
+		case <-slow.Done():
+		default:
+			t.Fatal("Expected slow subscriber to be disconnected")
+		}
+		if err := slow.Err(); err != ErrSlowConsumer {
+			t.Errorf("Expected ErrSlowConsumer, got %v", err)
+		}
+		if len(slow.C()) != 2 {
+			t.Errorf("Expected 2 buffered samples, got %d", len(slow.C()))
+		}
+		if len(fast.C()) != 3 {
+			t.Errorf("Expected fast subscriber to receive 3 samples, got %d", len(fast.C()))
+		}
+	})
 }
@@ -0,0 +1,19 @@
+package metrics
+
+import "regexp"
+
+var (
+	metricNameRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRE  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// IsValidMetricName reports whether name is a valid metric name
+func IsValidMetricName(name string) bool {
+	return metricNameRE.MatchString(name)
+}
+
+// IsValidLabelName reports whether name is a valid label name. Unlike metric
+// names, label names may not contain colons.
+func IsValidLabelName(name string) bool {
+	return labelNameRE.MatchString(name)
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrSlowConsumer is the error of a subscription that was disconnected
+// because its buffer was full
+var ErrSlowConsumer = errors.New("subscriber too slow, disconnected")
+
+// Subscription receives every sample accepted by the registry for series
+// matching its matchers. Samples are buffered up to a fixed size; a
+// subscriber that falls further behind is disconnected rather than
+// blocking appends.
+type Subscription struct {
+	registry *MetricRegistry
+	matchers []*Matcher
+	ch       chan *Metric
+	done     chan struct{}
+
+	once sync.Once
+	err  error
+}
+
+// Subscribe returns a subscription to the samples of series matching all
+// matchers, buffering up to bufferSize samples. It must be closed when no
+// longer needed.
+func (r *MetricRegistry) Subscribe(matchers []*Matcher, bufferSize int) *Subscription {
+	sub := &Subscription{
+		registry: r,
+		matchers: matchers,
+		ch:       make(chan *Metric, bufferSize),
+		done:     make(chan struct{}),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscriptions = append(r.subscriptions, sub)
+	return sub
+}
+
+// C returns the channel samples are delivered on. Samples must not be
+// modified.
+func (s *Subscription) C() <-chan *Metric {
+	return s.ch
+}
+
+// Done is closed when the subscription is closed or disconnected
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns ErrSlowConsumer if the subscription was disconnected, nil
+// otherwise
+func (s *Subscription) Err() error {
+	<-s.done
+	return s.err
+}
+
+// Close ends the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.end(nil)
+}
+
+// end removes the subscription from the registry and closes done. Samples
+// still buffered can be drained from C.
+func (s *Subscription) end(err error) {
+	s.once.Do(func() {
+		s.err = err
+		s.registry.removeSubscription(s)
+		close(s.done)
+	})
+}
+
+// publish delivers a sample to the subscription if it matches, without
+// blocking
+func (s *Subscription) publish(metric *Metric) {
+	if !MatchesAll(s.matchers, metric.Name, metric.Labels) {
+		return
+	}
+	select {
+	case <-s.done:
+	case s.ch <- metric:
+	default:
+		s.end(ErrSlowConsumer)
+	}
+}
+
+func (r *MetricRegistry) removeSubscription(sub *Subscription) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, s := range r.subscriptions {
+		if s == sub {
+			// Copy so slices handed to running publishes stay intact
+			r.subscriptions = append(r.subscriptions[:i:i], r.subscriptions[i+1:]...)
+			return
+		}
+	}
+}
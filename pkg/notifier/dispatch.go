@@ -0,0 +1,291 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+)
+
+const (
+	// groupByAll groups alerts by all of their labels
+	groupByAll = "..."
+
+	// dispatchTick is how often groups are checked for due notifications
+	dispatchTick = time.Second
+	// notifyTimeout bounds the time a single integration may take
+	notifyTimeout = 30 * time.Second
+)
+
+// resolved reports whether the alert has ended at ts
+func (a *Alert) resolved(ts time.Time) bool {
+	return !a.EndsAt.IsZero() && !a.EndsAt.After(ts)
+}
+
+// fingerprint returns a stable identifier of a label set
+func fingerprint(labels map[string]string) string {
+	h := fnv.New64a()
+	h.Write([]byte(query.LabelsString(labels)))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// aggrGroup collects the alerts sharing the same values of the group_by
+// labels and tracks what was last notified about them
+type aggrGroup struct {
+	key    string
+	labels map[string]string
+	alerts map[string]*Alert
+
+	nextFlush time.Time
+	// notified holds the fingerprints of the firing alerts in the last
+	// notification
+	notified map[string]bool
+	lastSent time.Time
+}
+
+// Dispatcher is a built-in replacement for Alertmanager for small
+// deployments. It groups alerts by labels, waits before notifying, drops
+// silenced alerts, only notifies about changes or after the repeat
+// interval, and sends notifications to webhook and email receivers.
+type Dispatcher struct {
+	cfg          config.RoutingConfig
+	receiver     string
+	integrations []integration
+	silences     []*silence
+
+	mu     sync.Mutex
+	groups map[string]*aggrGroup
+}
+
+// NewDispatcher creates a dispatcher from the routing configuration
+func NewDispatcher(cfg config.RoutingConfig) (*Dispatcher, error) {
+	d := &Dispatcher{
+		cfg:      cfg,
+		receiver: cfg.Receiver,
+		groups:   make(map[string]*aggrGroup),
+	}
+
+	var receiver *config.ReceiverConfig
+	for i := range cfg.Receivers {
+		if cfg.Receivers[i].Name == cfg.Receiver {
+			receiver = &cfg.Receivers[i]
+		}
+	}
+	if receiver == nil {
+		return nil, fmt.Errorf("receiver %q does not exist", cfg.Receiver)
+	}
+
+	client := &http.Client{}
+	for _, wc := range receiver.WebhookConfigs {
+		if wc.URL == "" {
+			return nil, fmt.Errorf("receiver %q: webhook url must be set", receiver.Name)
+		}
+		d.integrations = append(d.integrations, &webhook{cfg: wc, client: client})
+	}
+	for _, ec := range receiver.EmailConfigs {
+		if ec.To == "" || ec.From == "" || ec.Smarthost == "" {
+			return nil, fmt.Errorf("receiver %q: email to, from and smarthost must be set", receiver.Name)
+		}
+		d.integrations = append(d.integrations, &email{cfg: ec})
+	}
+
+	for _, sc := range cfg.Silences {
+		s, err := newSilence(sc)
+		if err != nil {
+			return nil, err
+		}
+		d.silences = append(d.silences, s)
+	}
+
+	return d, nil
+}
+
+// groupLabels returns the labels of an alert that determine its group
+func (d *Dispatcher) groupLabels(labels map[string]string) map[string]string {
+	group := map[string]string{}
+	for _, name := range d.cfg.GroupBy {
+		if name == groupByAll {
+			for k, v := range labels {
+				group[k] = v
+			}
+			return group
+		}
+		if v, ok := labels[name]; ok {
+			group[name] = v
+		}
+	}
+	return group
+}
+
+// Send adds or updates alerts. Resolved alerts that do not belong to a
+// known group were never notified and are ignored.
+func (d *Dispatcher) Send(alerts ...*Alert) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for _, a := range alerts {
+		labels := d.groupLabels(a.Labels)
+		key := query.LabelsString(labels)
+
+		g, ok := d.groups[key]
+		if !ok {
+			if a.resolved(now) {
+				continue
+			}
+			g = &aggrGroup{
+				key:       key,
+				labels:    labels,
+				alerts:    make(map[string]*Alert),
+				nextFlush: now.Add(d.cfg.GroupWait),
+				notified:  make(map[string]bool),
+			}
+			d.groups[key] = g
+		}
+		g.alerts[fingerprint(a.Labels)] = a
+	}
+}
+
+// silenced reports whether any active silence mutes the labels at ts
+func (d *Dispatcher) silenced(labels map[string]string, ts time.Time) bool {
+	for _, s := range d.silences {
+		if s.mutes(labels, ts) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run flushes due groups until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(dispatchTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			d.flush(ctx, now)
+		}
+	}
+}
+
+// flush notifies every group whose next flush is due at now
+func (d *Dispatcher) flush(ctx context.Context, now time.Time) {
+	d.mu.Lock()
+	var due []*Notification
+	for key, g := range d.groups {
+		if now.Before(g.nextFlush) {
+			continue
+		}
+		g.nextFlush = now.Add(d.cfg.GroupInterval)
+
+		if n := d.prepare(g, now); n != nil {
+			due = append(due, n)
+		}
+		if len(g.alerts) == 0 {
+			delete(d.groups, key)
+		}
+	}
+	d.mu.Unlock()
+
+	sort.Slice(due, func(i, j int) bool { return due[i].GroupKey < due[j].GroupKey })
+	for _, n := range due {
+		d.notify(ctx, n, now)
+	}
+}
+
+// prepare returns the notification due for a group, or nil if nothing
+// changed since the last notification and the repeat interval has not
+// passed. Resolved alerts are removed from the group. Must be called with
+// d.mu held.
+func (d *Dispatcher) prepare(g *aggrGroup, now time.Time) *Notification {
+	var firing, resolved []*Alert
+	for fp, a := range g.alerts {
+		if a.resolved(now) {
+			// Only resolutions of alerts that were notified as firing matter
+			if g.notified[fp] {
+				resolved = append(resolved, a)
+			}
+			delete(g.alerts, fp)
+			continue
+		}
+		if d.silenced(a.Labels, now) {
+			continue
+		}
+		firing = append(firing, a)
+	}
+
+	current := make(map[string]bool, len(firing))
+	for _, a := range firing {
+		current[fingerprint(a.Labels)] = true
+	}
+
+	changed := len(resolved) > 0 || len(current) != len(g.notified)
+	for fp := range current {
+		if !g.notified[fp] {
+			changed = true
+		}
+	}
+	repeat := len(firing) > 0 && now.Sub(g.lastSent) >= d.cfg.RepeatInterval
+	if !changed && !repeat {
+		return nil
+	}
+
+	g.notified = current
+	g.lastSent = now
+	if len(firing) == 0 && len(resolved) == 0 {
+		return nil
+	}
+
+	alerts := append(firing, resolved...)
+	sort.Slice(alerts, func(i, j int) bool {
+		return query.LabelsString(alerts[i].Labels) < query.LabelsString(alerts[j].Labels)
+	})
+	return &Notification{
+		Receiver:    d.receiver,
+		GroupKey:    g.key,
+		GroupLabels: g.labels,
+		Alerts:      alerts,
+	}
+}
+
+// notify sends a notification to every integration of the receiver.
+// Integrations that do not send resolved alerts only get firing ones.
+func (d *Dispatcher) notify(ctx context.Context, n *Notification, now time.Time) {
+	for _, i := range d.integrations {
+		notification := n
+		if !i.SendResolved() {
+			var firing []*Alert
+			for _, a := range n.Alerts {
+				if !a.resolved(now) {
+					firing = append(firing, a)
+				}
+			}
+			if len(firing) == 0 {
+				continue
+			}
+			notification = &Notification{
+				Receiver:    n.Receiver,
+				GroupKey:    n.GroupKey,
+				GroupLabels: n.GroupLabels,
+				Alerts:      firing,
+			}
+		}
+
+		nctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		err := i.Notify(nctx, notification)
+		cancel()
+		if err != nil {
+			slog.Error("Error notifying receiver", "integration", i.String(), "group", n.GroupKey, "err", err)
+		}
+	}
+}
@@ -0,0 +1,395 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+)
+
+// fakeWebhook records the notifications posted to it
+type fakeWebhook struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	messages []webhookMessage
+}
+
+func newFakeWebhook(t *testing.T) *fakeWebhook {
+	wh := &fakeWebhook{}
+	wh.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg webhookMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		wh.mu.Lock()
+		wh.messages = append(wh.messages, msg)
+		wh.mu.Unlock()
+	}))
+	t.Cleanup(wh.Close)
+	return wh
+}
+
+func (wh *fakeWebhook) received() []webhookMessage {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	return append([]webhookMessage(nil), wh.messages...)
+}
+
+// fakeSMTP is a minimal SMTP server recording the messages it receives
+type fakeSMTP struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTP{listener: l}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { l.Close() })
+	return s
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		conn.Write([]byte(line + "\r\n"))
+	}
+
+	reply("220 fake.smtp ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 fake.smtp")
+		case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *fakeSMTP) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.messages...)
+}
+
+// firingAlert creates an alert that is firing until well after now
+func firingAlert(name, instance string) *Alert {
+	return &Alert{
+		Labels:      map[string]string{"alertname": name, "instance": instance},
+		Annotations: map[string]string{"summary": instance + " is down"},
+		StartsAt:    time.Now().Add(-time.Minute),
+		EndsAt:      time.Now().Add(time.Hour),
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+
+	newDispatcher := func(t *testing.T, wh *fakeWebhook, silences ...config.SilenceConfig) *Dispatcher {
+		t.Helper()
+		d, err := NewDispatcher(config.RoutingConfig{
+			Receiver:       "team",
+			GroupBy:        []string{"alertname"},
+			GroupWait:      30 * time.Second,
+			GroupInterval:  5 * time.Minute,
+			RepeatInterval: time.Hour,
+			Receivers: []config.ReceiverConfig{{
+				Name:           "team",
+				WebhookConfigs: []config.WebhookConfig{{URL: wh.URL, SendResolved: true}},
+			}},
+			Silences: silences,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	t.Run("Groups alerts and waits for group_wait", func(t *testing.T) {
+		wh := newFakeWebhook(t)
+		d := newDispatcher(t, wh)
+
+		now := time.Now()
+		d.Send(firingAlert("InstanceDown", "a"), firingAlert("InstanceDown", "b"), firingAlert("HighLatency", "a"))
+
+		d.flush(ctx, now.Add(10*time.Second))
+		if n := len(wh.received()); n != 0 {
+			t.Fatalf("Expected no notification before group_wait, got %d", n)
+		}
+
+		d.flush(ctx, now.Add(31*time.Second))
+		messages := wh.received()
+		if len(messages) != 2 {
+			t.Fatalf("Expected 2 grouped notifications, got %d", len(messages))
+		}
+
+		msg := messages[1]
+		if msg.GroupLabels["alertname"] != "InstanceDown" || len(msg.Alerts) != 2 {
+			t.Errorf("Expected InstanceDown group with 2 alerts, got %+v", msg)
+		}
+		if msg.Status != "firing" || msg.Receiver != "team" || msg.CommonLabels["alertname"] != "InstanceDown" {
+			t.Errorf("Notification not built correctly: %+v", msg)
+		}
+		if _, ok := msg.CommonLabels["instance"]; ok {
+			t.Error("Expected differing instance label to be excluded from common labels")
+		}
+	})
+
+	t.Run("Deduplicates unchanged groups", func(t *testing.T) {
+		wh := newFakeWebhook(t)
+		d := newDispatcher(t, wh)
+
+		now := time.Now()
+		d.Send(firingAlert("InstanceDown", "a"))
+		d.flush(ctx, now.Add(time.Minute))
+
+		// Resent by the rule manager without changes
+		d.Send(firingAlert("InstanceDown", "a"))
+		d.flush(ctx, now.Add(7*time.Minute))
+		if n := len(wh.received()); n != 1 {
+			t.Fatalf("Expected unchanged group not to be notified again, got %d notifications", n)
+		}
+
+		// A new alert in the group is notified at the next group interval
+		d.Send(firingAlert("InstanceDown", "b"))
+		d.flush(ctx, now.Add(9*time.Minute))
+		if n := len(wh.received()); n != 1 {
+			t.Fatalf("Expected to wait for group_interval, got %d notifications", n)
+		}
+		d.flush(ctx, now.Add(13*time.Minute))
+		if n := len(wh.received()); n != 2 {
+			t.Fatalf("Expected changed group to be notified, got %d notifications", n)
+		}
+
+		// Unchanged groups are repeated after repeat_interval
+		d.flush(ctx, now.Add(74*time.Minute))
+		if n := len(wh.received()); n != 3 {
+			t.Fatalf("Expected repeated notification, got %d notifications", n)
+		}
+	})
+
+	t.Run("Sends resolved alerts", func(t *testing.T) {
+		wh := newFakeWebhook(t)
+		d := newDispatcher(t, wh)
+
+		now := time.Now()
+		alert := firingAlert("InstanceDown", "a")
+		d.Send(alert)
+		d.flush(ctx, now.Add(time.Minute))
+
+		resolved := *alert
+		resolved.EndsAt = now
+		d.Send(&resolved)
+		d.flush(ctx, now.Add(7*time.Minute))
+
+		messages := wh.received()
+		if len(messages) != 2 {
+			t.Fatalf("Expected 2 notifications, got %d", len(messages))
+		}
+		if messages[1].Status != "resolved" || messages[1].Alerts[0].Status != "resolved" {
+			t.Errorf("Expected resolved notification, got %+v", messages[1])
+		}
+		if len(d.groups) != 0 {
+			t.Errorf("Expected empty group to be removed, got %d groups", len(d.groups))
+		}
+	})
+
+	t.Run("Applies silences", func(t *testing.T) {
+		wh := newFakeWebhook(t)
+		d := newDispatcher(t, wh,
+			config.SilenceConfig{Matchers: []string{`alertname="InstanceDown"`, `instance=~"a|b"`}},
+			config.SilenceConfig{Matchers: []string{`alertname="HighLatency"`}, EndsAt: time.Now().Add(-time.Minute)},
+		)
+
+		now := time.Now()
+		d.Send(firingAlert("InstanceDown", "a"), firingAlert("InstanceDown", "c"), firingAlert("HighLatency", "a"))
+		d.flush(ctx, now.Add(time.Minute))
+
+		messages := wh.received()
+		if len(messages) != 2 {
+			t.Fatalf("Expected 2 notifications, got %d", len(messages))
+		}
+		for _, msg := range messages {
+			for _, a := range msg.Alerts {
+				if a.Labels["alertname"] == "InstanceDown" && a.Labels["instance"] == "a" {
+					t.Error("Expected silenced alert not to be notified")
+				}
+			}
+		}
+	})
+
+	t.Run("Invalid configuration", func(t *testing.T) {
+		if _, err := NewDispatcher(config.RoutingConfig{Receiver: "missing"}); err == nil {
+			t.Error("Expected error for missing receiver")
+		}
+
+		_, err := NewDispatcher(config.RoutingConfig{
+			Receiver:  "team",
+			Receivers: []config.ReceiverConfig{{Name: "team"}},
+			Silences:  []config.SilenceConfig{{Matchers: []string{`alertname=~"("`}}},
+		})
+		if err == nil {
+			t.Error("Expected error for invalid silence matcher")
+		}
+	})
+}
+
+func TestEmailReceiver(t *testing.T) {
+	smtpServer := newFakeSMTP(t)
+
+	d, err := NewDispatcher(config.RoutingConfig{
+		Receiver: "ops",
+		GroupBy:  []string{"alertname"},
+		Receivers: []config.ReceiverConfig{{
+			Name: "ops",
+			EmailConfigs: []config.EmailConfig{{
+				To:        "ops@example.com",
+				From:      "promenitheus@example.com",
+				Smarthost: smtpServer.listener.Addr().String(),
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.Send(firingAlert("InstanceDown", "a"), firingAlert("InstanceDown", "b"))
+	d.flush(context.Background(), time.Now())
+
+	messages := smtpServer.received()
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 email, got %d", len(messages))
+	}
+
+	msg := messages[0]
+	for _, want := range []string{
+		"To: ops@example.com",
+		"Subject: [FIRING:2] InstanceDown",
+		`{alertname="InstanceDown", instance="a"}`,
+		"summary: b is down",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Expected email to contain %q, got:\n%s", want, msg)
+		}
+	}
+}
+
+func TestEmailReceiverHeaders(t *testing.T) {
+	smtpServer := newFakeSMTP(t)
+	e := &email{cfg: config.EmailConfig{
+		To:        "ops@example.com",
+		From:      "promenitheus@example.com",
+		Smarthost: smtpServer.listener.Addr().String(),
+	}}
+
+	err := e.Notify(context.Background(), &Notification{
+		GroupLabels: map[string]string{"alertname": "Down\r\nBcc: attacker@example.com"},
+		Alerts:      []*Alert{firingAlert("Down", "a")},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	messages := smtpServer.received()
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 email, got %d", len(messages))
+	}
+	headers, _, _ := strings.Cut(messages[0], "\r\n\r\n")
+	if strings.Contains(headers, "\r\nBcc:") {
+		t.Errorf("Expected label value not to add headers, got:\n%s", headers)
+	}
+	if !strings.Contains(headers, "Subject: [FIRING:1] Down Bcc: attacker@example.com") {
+		t.Errorf("Expected line breaks in subject to be replaced, got:\n%s", headers)
+	}
+}
+
+func TestEmailReceiverTimeout(t *testing.T) {
+	// A smarthost accepting connections without ever replying
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	e := &email{cfg: config.EmailConfig{To: "ops@example.com", From: "promenitheus@example.com", Smarthost: l.Addr().String()}}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- e.Notify(ctx, &Notification{Alerts: []*Alert{firingAlert("Down", "a")}})
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Notify to give up when the context is done")
+	}
+}
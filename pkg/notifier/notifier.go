@@ -0,0 +1,306 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+)
+
+const (
+	// MaxBatchSize is the maximum number of alerts sent in one request
+	MaxBatchSize = 64
+
+	// DefaultQueueCapacity is used when no queue capacity is configured
+	DefaultQueueCapacity = 10000
+
+	// maxRetries is how many times a failed request is retried
+	maxRetries = 3
+	// defaultRetryBackoff is the delay before the first retry. It doubles
+	// after every attempt.
+	defaultRetryBackoff = 500 * time.Millisecond
+
+	alertsEndpoint = "/api/v2/alerts"
+)
+
+// Alert is an alert in the format of the Alertmanager v2 API
+type Alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt,omitzero"`
+	EndsAt       time.Time         `json:"endsAt,omitzero"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// Sender accepts alerts for delivery
+type Sender interface {
+	Send(alerts ...*Alert)
+}
+
+// Stats counts the outcome of notifications
+type Stats struct {
+	// Sent is the number of alerts sent, counted once per Alertmanager
+	Sent int64
+	// Errors is the number of alerts that failed to reach an Alertmanager
+	Errors int64
+	// Dropped is the number of alerts dropped because the queue was full or
+	// no Alertmanager accepted them
+	Dropped int64
+	// QueueLength is the number of alerts waiting to be sent
+	QueueLength int
+}
+
+// alertmanager is a single Alertmanager endpoint
+type alertmanager struct {
+	url     string
+	timeout time.Duration
+}
+
+// Manager queues alerts and sends them in batches to all configured
+// Alertmanagers
+type Manager struct {
+	client        *http.Client
+	alertmanagers []alertmanager
+	capacity      int
+	retryBackoff  time.Duration
+
+	mu    sync.Mutex
+	queue []*Alert
+	stats Stats
+	more  chan struct{}
+}
+
+// NewManager creates a notifier sending to the Alertmanagers in cfg
+func NewManager(cfg config.AlertingConfig) (*Manager, error) {
+	capacity := cfg.QueueCapacity
+	if capacity <= 0 {
+		capacity = DefaultQueueCapacity
+	}
+
+	m := &Manager{
+		client:       &http.Client{},
+		capacity:     capacity,
+		retryBackoff: defaultRetryBackoff,
+		more:         make(chan struct{}, 1),
+	}
+
+	for _, amCfg := range cfg.Alertmanagers {
+		scheme := amCfg.Scheme
+		if scheme == "" {
+			scheme = "http"
+		}
+		if scheme != "http" && scheme != "https" {
+			return nil, fmt.Errorf("invalid alertmanager scheme %q", scheme)
+		}
+		for _, sc := range amCfg.StaticConfigs {
+			for _, target := range sc.Targets {
+				u := &url.URL{
+					Scheme: scheme,
+					Host:   target,
+					Path:   path.Join("/", amCfg.PathPrefix, alertsEndpoint),
+				}
+				m.alertmanagers = append(m.alertmanagers, alertmanager{url: u.String(), timeout: amCfg.Timeout})
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// Alertmanagers returns the URLs alerts are sent to
+func (m *Manager) Alertmanagers() []string {
+	urls := make([]string, 0, len(m.alertmanagers))
+	for _, am := range m.alertmanagers {
+		urls = append(urls, am.url)
+	}
+	return urls
+}
+
+// Stats returns the notification counters
+func (m *Manager) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.stats
+	stats.QueueLength = len(m.queue)
+	return stats
+}
+
+// Send queues alerts for delivery. If the queue would exceed its capacity
+// the oldest alerts are dropped.
+func (m *Manager) Send(alerts ...*Alert) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Alerts that could never fit are dropped right away
+	if d := len(alerts) - m.capacity; d > 0 {
+		alerts = alerts[d:]
+		m.stats.Dropped += int64(d)
+		slog.Warn("Alert batch larger than queue capacity, dropping alerts", "count", d)
+	}
+
+	if d := len(m.queue) + len(alerts) - m.capacity; d > 0 {
+		m.queue = m.queue[d:]
+		m.stats.Dropped += int64(d)
+		slog.Warn("Alert notification queue full, dropping alerts", "count", d)
+	}
+	m.queue = append(m.queue, alerts...)
+
+	select {
+	case m.more <- struct{}{}:
+	default:
+	}
+}
+
+// nextBatch removes and returns up to MaxBatchSize alerts from the queue
+func (m *Manager) nextBatch() []*Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := min(len(m.queue), MaxBatchSize)
+	batch := append([]*Alert(nil), m.queue[:n]...)
+	m.queue = m.queue[n:]
+	return batch
+}
+
+// Run sends queued alerts until ctx is cancelled
+func (m *Manager) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.more:
+		}
+
+		for {
+			batch := m.nextBatch()
+			if len(batch) == 0 {
+				break
+			}
+			m.sendAll(ctx, batch)
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}
+}
+
+// sendAll sends a batch to every Alertmanager concurrently. The batch is
+// counted as dropped if no Alertmanager accepted it.
+func (m *Manager) sendAll(ctx context.Context, alerts []*Alert) {
+	if len(m.alertmanagers) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		slog.Error("Error encoding alerts", "err", err)
+		return
+	}
+
+	var (
+		wg      sync.WaitGroup
+		success bool
+	)
+	for _, am := range m.alertmanagers {
+		wg.Add(1)
+		go func(am alertmanager) {
+			defer wg.Done()
+
+			err := m.sendWithRetries(ctx, am, body)
+
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if err != nil {
+				m.stats.Errors += int64(len(alerts))
+				slog.Error("Error sending alerts", "alertmanager", am.url, "count", len(alerts), "err", err)
+				return
+			}
+			m.stats.Sent += int64(len(alerts))
+			success = true
+		}(am)
+	}
+	wg.Wait()
+
+	if !success {
+		m.mu.Lock()
+		m.stats.Dropped += int64(len(alerts))
+		m.mu.Unlock()
+	}
+}
+
+// retryableError marks failures worth retrying: network errors and 5xx
+// responses
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
+
+// sendWithRetries posts a batch to one Alertmanager, retrying retryable
+// failures with exponential backoff
+func (m *Manager) sendWithRetries(ctx context.Context, am alertmanager, body []byte) error {
+	backoff := m.retryBackoff
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		err = m.send(ctx, am, body)
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(retryableError); !ok {
+			return err
+		}
+	}
+	return err
+}
+
+// send posts a batch to one Alertmanager
+func (m *Manager) send(ctx context.Context, am alertmanager, body []byte) error {
+	if am.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, am.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, am.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return retryableError{err}
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		err := fmt.Errorf("bad response status %s", resp.Status)
+		if resp.StatusCode/100 == 5 {
+			return retryableError{err}
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+)
+
+// fakeAlertmanager records the alerts posted to its v2 API. The first
+// failures requests are answered with status.
+type fakeAlertmanager struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	batches  [][]Alert
+	requests int
+	failures int
+	status   int
+}
+
+func newFakeAlertmanager(t *testing.T) *fakeAlertmanager {
+	am := &fakeAlertmanager{}
+	am.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v2/alerts" {
+			http.NotFound(w, r)
+			return
+		}
+
+		am.mu.Lock()
+		defer am.mu.Unlock()
+
+		am.requests++
+		if am.requests <= am.failures {
+			w.WriteHeader(am.status)
+			return
+		}
+
+		var alerts []Alert
+		if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		am.batches = append(am.batches, alerts)
+	}))
+	t.Cleanup(am.Close)
+	return am
+}
+
+// received returns the number of alerts received and the number of requests
+func (am *fakeAlertmanager) received() (int, int) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	n := 0
+	for _, b := range am.batches {
+		n += len(b)
+	}
+	return n, am.requests
+}
+
+// newTestManager creates a notifier sending to the given fake Alertmanagers
+func newTestManager(t *testing.T, capacity int, ams ...*fakeAlertmanager) *Manager {
+	t.Helper()
+
+	var targets []string
+	for _, am := range ams {
+		targets = append(targets, strings.TrimPrefix(am.URL, "http://"))
+	}
+	m, err := NewManager(config.AlertingConfig{
+		QueueCapacity: capacity,
+		Alertmanagers: []config.AlertmanagerConfig{{
+			Timeout:       time.Second,
+			StaticConfigs: []config.StaticConfig{{Targets: targets}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.retryBackoff = 10 * time.Millisecond
+	return m
+}
+
+// makeAlerts creates n alerts with distinct names
+func makeAlerts(n int) []*Alert {
+	alerts := make([]*Alert, 0, n)
+	for i := 0; i < n; i++ {
+		alerts = append(alerts, &Alert{
+			Labels:   map[string]string{"alertname": fmt.Sprintf("alert%d", i)},
+			StartsAt: time.Unix(1700000000, 0),
+		})
+	}
+	return alerts
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	t.Run("Sends alerts in batches", func(t *testing.T) {
+		am := newFakeAlertmanager(t)
+		m := newTestManager(t, 1000, am)
+		m.Send(makeAlerts(100)...)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go m.Run(ctx)
+
+		// Sent is counted once the responses arrive, after the alerts
+		waitFor(t, func() bool {
+			n, _ := am.received()
+			return n == 100 && m.Stats().Sent == 100
+		})
+
+		_, requests := am.received()
+		if requests != 2 {
+			t.Errorf("Expected 2 batches, got %d", requests)
+		}
+
+		am.mu.Lock()
+		first := am.batches[0]
+		am.mu.Unlock()
+		if len(first) != MaxBatchSize {
+			t.Errorf("Expected first batch of %d alerts, got %d", MaxBatchSize, len(first))
+		}
+		if first[0].Labels["alertname"] != "alert0" || !first[0].StartsAt.Equal(time.Unix(1700000000, 0)) {
+			t.Errorf("Alert not encoded correctly: %+v", first[0])
+		}
+
+		if stats := m.Stats(); stats.Dropped != 0 {
+			t.Errorf("Expected none dropped, got %+v", stats)
+		}
+	})
+
+	t.Run("Retries server errors", func(t *testing.T) {
+		am := newFakeAlertmanager(t)
+		am.failures = 2
+		am.status = http.StatusServiceUnavailable
+		m := newTestManager(t, 1000, am)
+
+		m.sendAll(context.Background(), makeAlerts(3))
+
+		n, requests := am.received()
+		if n != 3 || requests != 3 {
+			t.Errorf("Expected 3 alerts after 3 requests, got %d alerts in %d requests", n, requests)
+		}
+		if stats := m.Stats(); stats.Sent != 3 || stats.Errors != 0 {
+			t.Errorf("Expected 3 sent and no errors, got %+v", stats)
+		}
+	})
+
+	t.Run("Does not retry client errors", func(t *testing.T) {
+		am := newFakeAlertmanager(t)
+		am.failures = 1
+		am.status = http.StatusBadRequest
+		m := newTestManager(t, 1000, am)
+
+		m.sendAll(context.Background(), makeAlerts(3))
+
+		if _, requests := am.received(); requests != 1 {
+			t.Errorf("Expected 1 request, got %d", requests)
+		}
+		if stats := m.Stats(); stats.Errors != 3 || stats.Dropped != 3 {
+			t.Errorf("Expected 3 errors and 3 dropped, got %+v", stats)
+		}
+	})
+
+	t.Run("Delivered if any Alertmanager accepts", func(t *testing.T) {
+		good := newFakeAlertmanager(t)
+		bad := newFakeAlertmanager(t)
+		bad.failures = 100
+		bad.status = http.StatusInternalServerError
+		m := newTestManager(t, 1000, good, bad)
+
+		m.sendAll(context.Background(), makeAlerts(2))
+
+		if stats := m.Stats(); stats.Sent != 2 || stats.Errors != 2 || stats.Dropped != 0 {
+			t.Errorf("Expected 2 sent, 2 errors and none dropped, got %+v", stats)
+		}
+		if _, requests := bad.received(); requests != maxRetries+1 {
+			t.Errorf("Expected %d attempts, got %d", maxRetries+1, requests)
+		}
+	})
+
+	t.Run("Drops oldest alerts when queue is full", func(t *testing.T) {
+		m := newTestManager(t, 5)
+		m.Send(makeAlerts(3)...)
+		m.Send(makeAlerts(4)...)
+
+		stats := m.Stats()
+		if stats.Dropped != 2 || stats.QueueLength != 5 {
+			t.Errorf("Expected 2 dropped and 5 queued, got %+v", stats)
+		}
+
+		batch := m.nextBatch()
+		if batch[0].Labels["alertname"] != "alert2" {
+			t.Errorf("Expected oldest alerts to be dropped, first queued is %s", batch[0].Labels["alertname"])
+		}
+
+		m.Send(makeAlerts(8)...)
+		if stats := m.Stats(); stats.Dropped != 5 || stats.QueueLength != 5 {
+			t.Errorf("Expected 5 dropped and 5 queued, got %+v", stats)
+		}
+	})
+}
+
+func TestNewManager(t *testing.T) {
+	m, err := NewManager(config.AlertingConfig{
+		Alertmanagers: []config.AlertmanagerConfig{{
+			Scheme:        "https",
+			PathPrefix:    "/alertmanager",
+			StaticConfigs: []config.StaticConfig{{Targets: []string{"am1:9093", "am2:9093"}}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	urls := m.Alertmanagers()
+	if len(urls) != 2 || urls[0] != "https://am1:9093/alertmanager/api/v2/alerts" {
+		t.Errorf("Unexpected alertmanager URLs: %v", urls)
+	}
+	if m.capacity != DefaultQueueCapacity {
+		t.Errorf("Expected default queue capacity %d, got %d", DefaultQueueCapacity, m.capacity)
+	}
+
+	if _, err := NewManager(config.AlertingConfig{
+		Alertmanagers: []config.AlertmanagerConfig{{Scheme: "ftp"}},
+	}); err == nil {
+		t.Error("Expected error for invalid scheme")
+	}
+}
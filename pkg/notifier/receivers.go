@@ -0,0 +1,299 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+)
+
+// Notification is the content of a notification about one group of alerts
+type Notification struct {
+	Receiver    string
+	GroupKey    string
+	GroupLabels map[string]string
+	Alerts      []*Alert
+}
+
+// status returns "firing" if any alert of the notification is firing and
+// "resolved" otherwise
+func (n *Notification) status(ts time.Time) string {
+	for _, a := range n.Alerts {
+		if !a.resolved(ts) {
+			return "firing"
+		}
+	}
+	return "resolved"
+}
+
+// commonLabels returns the labels shared by all alerts of the notification
+func commonLabels(sets []map[string]string) map[string]string {
+	common := map[string]string{}
+	if len(sets) == 0 {
+		return common
+	}
+	for k, v := range sets[0] {
+		common[k] = v
+	}
+	for _, set := range sets[1:] {
+		for k, v := range common {
+			if set[k] != v {
+				delete(common, k)
+			}
+		}
+	}
+	return common
+}
+
+// integration delivers notifications to one destination
+type integration interface {
+	// Notify sends a notification
+	Notify(ctx context.Context, n *Notification) error
+	// SendResolved reports whether resolved alerts are sent
+	SendResolved() bool
+	// String describes the destination for logs
+	String() string
+}
+
+// webhookMessage is the JSON body sent to webhooks, compatible with the
+// Alertmanager webhook format
+type webhookMessage struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	Alerts            []webhookAlert    `json:"alerts"`
+}
+
+type webhookAlert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt,omitzero"`
+	Fingerprint string            `json:"fingerprint"`
+}
+
+// webhook posts notifications as JSON to a URL
+type webhook struct {
+	cfg    config.WebhookConfig
+	client *http.Client
+}
+
+func (w *webhook) SendResolved() bool { return w.cfg.SendResolved }
+func (w *webhook) String() string     { return "webhook " + w.cfg.URL }
+
+// Notify posts the notification to the webhook URL
+func (w *webhook) Notify(ctx context.Context, n *Notification) error {
+	now := time.Now()
+	msg := webhookMessage{
+		Version:     "4",
+		GroupKey:    n.GroupKey,
+		Status:      n.status(now),
+		Receiver:    n.Receiver,
+		GroupLabels: n.GroupLabels,
+	}
+
+	labelSets := make([]map[string]string, 0, len(n.Alerts))
+	annotationSets := make([]map[string]string, 0, len(n.Alerts))
+	for _, a := range n.Alerts {
+		status := "firing"
+		if a.resolved(now) {
+			status = "resolved"
+		}
+		annotations := a.Annotations
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		msg.Alerts = append(msg.Alerts, webhookAlert{
+			Status:      status,
+			Labels:      a.Labels,
+			Annotations: annotations,
+			StartsAt:    a.StartsAt,
+			EndsAt:      a.EndsAt,
+			Fingerprint: fingerprint(a.Labels),
+		})
+		labelSets = append(labelSets, a.Labels)
+		annotationSets = append(annotationSets, annotations)
+	}
+	msg.CommonLabels = commonLabels(labelSets)
+	msg.CommonAnnotations = commonLabels(annotationSets)
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// email sends notifications through an SMTP server
+type email struct {
+	cfg config.EmailConfig
+}
+
+func (e *email) SendResolved() bool { return e.cfg.SendResolved }
+func (e *email) String() string     { return "email " + e.cfg.To }
+
+// Notify sends the notification as a plain-text email
+func (e *email) Notify(ctx context.Context, n *Notification) error {
+	now := time.Now()
+
+	var firing, resolved int
+	for _, a := range n.Alerts {
+		if a.resolved(now) {
+			resolved++
+		} else {
+			firing++
+		}
+	}
+
+	subject := fmt.Sprintf("[%s:%d] %s", strings.ToUpper(n.status(now)), firing, labelValues(n.GroupLabels))
+	if firing == 0 {
+		subject = fmt.Sprintf("[RESOLVED] %s", labelValues(n.GroupLabels))
+	}
+	// Label values must not end the header and start new ones
+	subject = headerLineBreaks.Replace(subject)
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "To: %s\r\n", e.cfg.To)
+	fmt.Fprintf(&body, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&body, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&body, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&body, "Content-Type: text/plain; charset=UTF-8\r\n")
+	fmt.Fprintf(&body, "\r\n")
+	for _, section := range []struct {
+		title    string
+		resolved bool
+		count    int
+	}{{"Firing", false, firing}, {"Resolved", true, resolved}} {
+		if section.count == 0 {
+			continue
+		}
+		fmt.Fprintf(&body, "%s alerts:\r\n", section.title)
+		for _, a := range n.Alerts {
+			if a.resolved(now) != section.resolved {
+				continue
+			}
+			fmt.Fprintf(&body, "\r\n%s\r\n", query.LabelsString(a.Labels))
+			keys := make([]string, 0, len(a.Annotations))
+			for k := range a.Annotations {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Fprintf(&body, "  %s: %s\r\n", k, a.Annotations[k])
+			}
+			fmt.Fprintf(&body, "  Started: %s\r\n", a.StartsAt.Format(time.RFC3339))
+		}
+		fmt.Fprintf(&body, "\r\n")
+	}
+
+	return e.send(ctx, []byte(body.String()))
+}
+
+// headerLineBreaks replaces line breaks in header values with spaces
+var headerLineBreaks = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+// send delivers a message like smtp.SendMail, but gives up once ctx is done
+func (e *email) send(ctx context.Context, msg []byte) (err error) {
+	host, _, err := net.SplitHostPort(e.cfg.Smarthost)
+	if err != nil {
+		return fmt.Errorf("invalid smarthost %q: %w", e.cfg.Smarthost, err)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", e.cfg.Smarthost)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Unblock reads and writes to a smarthost that stopped responding
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+	defer func() {
+		if err != nil && ctx.Err() != nil {
+			err = fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+	}()
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if e.cfg.AuthUsername != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.cfg.AuthUsername, e.cfg.AuthPassword, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.cfg.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(e.cfg.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// labelValues formats the values of a label set ordered by label name
+func labelValues(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	values := make([]string, 0, len(names))
+	for _, k := range names {
+		values = append(values, labels[k])
+	}
+	return strings.Join(values, " ")
+}
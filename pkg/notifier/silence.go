@@ -0,0 +1,59 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+)
+
+// silence mutes alerts matching all of its matchers while it is active
+type silence struct {
+	matchers []*metrics.Matcher
+	startsAt time.Time
+	endsAt   time.Time
+}
+
+// newSilence parses the matchers of a configured silence
+func newSilence(cfg config.SilenceConfig) (*silence, error) {
+	if len(cfg.Matchers) == 0 {
+		return nil, fmt.Errorf("silence must have at least one matcher")
+	}
+
+	s := &silence{startsAt: cfg.StartsAt, endsAt: cfg.EndsAt}
+	for _, m := range cfg.Matchers {
+		matchers, err := query.ParseSelector("{" + strings.TrimSuffix(strings.TrimPrefix(m, "{"), "}") + "}")
+		if err != nil {
+			return nil, fmt.Errorf("invalid silence matcher %q: %w", m, err)
+		}
+		s.matchers = append(s.matchers, matchers...)
+	}
+	return s, nil
+}
+
+// active reports whether the silence applies at ts
+func (s *silence) active(ts time.Time) bool {
+	if !s.startsAt.IsZero() && ts.Before(s.startsAt) {
+		return false
+	}
+	if !s.endsAt.IsZero() && !ts.Before(s.endsAt) {
+		return false
+	}
+	return true
+}
+
+// mutes reports whether the silence mutes an alert with the given labels at ts
+func (s *silence) mutes(labels map[string]string, ts time.Time) bool {
+	if !s.active(ts) {
+		return false
+	}
+	for _, m := range s.matchers {
+		if !m.Matches(labels[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,299 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/scraper"
+)
+
+const (
+	// PathPrefix is the path under which metrics are pushed
+	PathPrefix = "/metrics/"
+
+	// pushTimeMetric records when a group was last pushed
+	pushTimeMetric = "push_time_seconds"
+
+	// maxPushSize limits the size of a pushed body
+	maxPushSize = 16 << 20
+)
+
+// group is the set of metrics pushed under one grouping key
+type group struct {
+	labels   map[string]string
+	metrics  map[string][]*metrics.Metric
+	pushTime time.Time
+}
+
+// Store keeps pushed metric groups and writes them into a registry, in the
+// manner of the Prometheus Pushgateway. Groups are identified by the job
+// and grouping labels of the push URL.
+type Store struct {
+	registry *metrics.MetricRegistry
+
+	mu     sync.Mutex
+	groups map[string]*group
+
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewStore creates a push store writing into registry
+func NewStore(registry *metrics.MetricRegistry) *Store {
+	return &Store{
+		registry: registry,
+		groups:   make(map[string]*group),
+		now:      time.Now,
+	}
+}
+
+// Run writes the metrics of every group into the registry again every
+// interval, as if a Pushgateway was scraped, so pushed values do not go
+// stale in queries until the group is deleted
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refresh()
+		}
+	}
+}
+
+func (s *Store) refresh() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ts := s.now()
+	for _, g := range s.groups {
+		for _, ms := range g.metrics {
+			for _, m := range ms {
+				s.register(m, ts)
+			}
+		}
+		s.registerPushTime(g, ts)
+	}
+}
+
+// ServeHTTP handles PUT, POST and DELETE requests to
+// /metrics/job/<job>{/<label>/<value>}. PUT replaces all metrics of the
+// group, POST only those with the same names as the pushed metrics and
+// DELETE removes the group. Label values may be base64url encoded by
+// appending @base64 to the label name, e.g. /metrics/job@base64/<job>.
+func (s *Store) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	labels, err := parseGroupingKey(r.URL.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut, http.MethodPost:
+		pushed, err := parsePush(r.Body, labels)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.push(labels, pushed, r.Method == http.MethodPut)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		s.delete(labels)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.Header().Set("Allow", "PUT, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// push stores pushed metrics in a group. With replace, metrics of the group
+// that were not pushed again are removed; otherwise only metrics with a
+// pushed name are replaced.
+func (s *Store) push(labels map[string]string, pushed map[string][]*metrics.Metric, replace bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := groupKey(labels)
+	g, ok := s.groups[key]
+	if !ok {
+		g = &group{labels: labels, metrics: make(map[string][]*metrics.Metric)}
+		s.groups[key] = g
+	}
+
+	for name, ms := range g.metrics {
+		if _, ok := pushed[name]; !ok && !replace {
+			continue
+		}
+		for _, m := range ms {
+			s.registry.Delete(m.Name, m.Labels)
+		}
+		delete(g.metrics, name)
+	}
+
+	ts := s.now()
+	for name, ms := range pushed {
+		for _, m := range ms {
+			s.register(m, ts)
+		}
+		g.metrics[name] = ms
+	}
+	g.pushTime = ts
+	s.registerPushTime(g, ts)
+}
+
+// delete removes a group and all its series
+func (s *Store) delete(labels map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := groupKey(labels)
+	g, ok := s.groups[key]
+	if !ok {
+		return
+	}
+	for _, ms := range g.metrics {
+		for _, m := range ms {
+			s.registry.Delete(m.Name, m.Labels)
+		}
+	}
+	s.registry.Delete(pushTimeMetric, g.labels)
+	delete(s.groups, key)
+}
+
+// register writes a copy of a stored metric stamped with ts. Metrics in the
+// registry must not be modified, so the stored metric is never registered
+// itself.
+func (s *Store) register(m *metrics.Metric, ts time.Time) {
+	s.registry.Register(&metrics.Metric{
+		Name:      m.Name,
+		Type:      m.Type,
+		Value:     m.Value,
+		Labels:    m.Labels,
+		Timestamp: ts,
+	})
+}
+
+func (s *Store) registerPushTime(g *group, ts time.Time) {
+	s.registry.Register(&metrics.Metric{
+		Name:      pushTimeMetric,
+		Type:      metrics.MetricTypeGauge,
+		Value:     float64(g.pushTime.UnixNano()) / 1e9,
+		Labels:    g.labels,
+		Timestamp: ts,
+	})
+}
+
+// parseGroupingKey parses the labels of a push path such as
+// /metrics/job/backup/instance/db-1
+func parseGroupingKey(path string) (map[string]string, error) {
+	rest, ok := strings.CutPrefix(path, PathPrefix)
+	if !ok {
+		return nil, fmt.Errorf("push path must start with %s", PathPrefix)
+	}
+	parts := strings.Split(strings.TrimSuffix(rest, "/"), "/")
+	if len(parts)%2 != 0 {
+		return nil, fmt.Errorf("push path must consist of label name and value pairs")
+	}
+
+	labels := make(map[string]string, len(parts)/2)
+	seen := make(map[string]bool, len(parts)/2)
+	for i := 0; i < len(parts); i += 2 {
+		name, value := parts[i], parts[i+1]
+		if encoded, ok := strings.CutSuffix(name, "@base64"); ok {
+			decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+			if err != nil {
+				return nil, fmt.Errorf("invalid base64 value for label %q: %v", encoded, err)
+			}
+			name, value = encoded, string(decoded)
+		}
+
+		if i == 0 && name != "job" {
+			return nil, fmt.Errorf("push path must start with %sjob/<job>", PathPrefix)
+		}
+		if !metrics.IsValidLabelName(name) {
+			return nil, fmt.Errorf("invalid label name %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate label name %q", name)
+		}
+		seen[name] = true
+		// Empty values are the same as a missing label
+		if value != "" {
+			labels[name] = value
+		}
+	}
+
+	if labels["job"] == "" {
+		return nil, fmt.Errorf("job name must not be empty")
+	}
+	return labels, nil
+}
+
+// parsePush parses a pushed body in text format into metrics by name, with
+// the grouping labels added
+func parsePush(body io.Reader, grouping map[string]string) (map[string][]*metrics.Metric, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxPushSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %v", err)
+	}
+	if len(data) > maxPushSize {
+		return nil, fmt.Errorf("body exceeds limit of %d bytes", maxPushSize)
+	}
+
+	parsed, err := scraper.ParseMetrics(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metrics: %v", err)
+	}
+
+	result := make(map[string][]*metrics.Metric)
+	for _, m := range parsed {
+		if !metrics.IsValidMetricName(m.Name) {
+			return nil, fmt.Errorf("invalid metric name %q", m.Name)
+		}
+		if m.Name == pushTimeMetric {
+			return nil, fmt.Errorf("metric name %s is reserved", pushTimeMetric)
+		}
+		if !m.Timestamp.IsZero() {
+			return nil, fmt.Errorf("pushed metric %s must not have a timestamp", m.Name)
+		}
+
+		for name, value := range grouping {
+			if v, ok := m.Labels[name]; ok && v != value {
+				return nil, fmt.Errorf("pushed metric %s has label %s=%q conflicting with grouping key %s=%q", m.Name, name, v, name, value)
+			}
+			m.Labels[name] = value
+		}
+		for name, value := range m.Labels {
+			if !metrics.IsValidLabelName(name) {
+				return nil, fmt.Errorf("pushed metric %s has invalid label name %q", m.Name, name)
+			}
+			if value == "" {
+				delete(m.Labels, name)
+			}
+		}
+		result[m.Name] = append(result[m.Name], m)
+	}
+	return result, nil
+}
+
+// groupKey identifies a group by its labels
+func groupKey(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"\xff"+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\xfe")
+}
@@ -0,0 +1,170 @@
+package push
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+func newTestStore() (*Store, *metrics.MetricRegistry) {
+	registry := metrics.NewMetricRegistry()
+	store := NewStore(registry)
+	store.now = func() time.Time { return time.Unix(1700000000, 0) }
+	return store, registry
+}
+
+func pushMetrics(t *testing.T, s *Store, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStore(t *testing.T) {
+	group := map[string]string{"job": "backup", "instance": "db-1"}
+	withGroup := func(labels map[string]string) map[string]string {
+		res := map[string]string{"job": "backup", "instance": "db-1"}
+		for k, v := range labels {
+			res[k] = v
+		}
+		return res
+	}
+
+	t.Run("PUT replaces the group", func(t *testing.T) {
+		s, registry := newTestStore()
+
+		rec := pushMetrics(t, s, http.MethodPut, "/metrics/job/backup/instance/db-1", `# TYPE backup_bytes counter
+backup_bytes{disk="a"} 10
+backup_bytes{disk="b"} 20
+backup_duration_seconds 3.5
+`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+
+		m, ok := registry.Get("backup_bytes", withGroup(map[string]string{"disk": "a"}))
+		if !ok || m.Value != 10 || m.Type != metrics.MetricTypeCounter {
+			t.Errorf("Expected pushed counter with grouping labels, got %+v", m)
+		}
+		if m, ok := registry.Get("push_time_seconds", group); !ok || m.Value != 1700000000 {
+			t.Errorf("Expected push_time_seconds 1700000000, got %+v", m)
+		}
+
+		pushMetrics(t, s, http.MethodPut, "/metrics/job/backup/instance/db-1", "backup_bytes{disk=\"a\"} 15\n")
+		if m, _ := registry.Get("backup_bytes", withGroup(map[string]string{"disk": "a"})); m.Value != 15 {
+			t.Errorf("Expected value 15, got %v", m.Value)
+		}
+		if _, ok := registry.Get("backup_bytes", withGroup(map[string]string{"disk": "b"})); ok {
+			t.Error("Expected series missing from PUT to be removed")
+		}
+		if _, ok := registry.Get("backup_duration_seconds", group); ok {
+			t.Error("Expected metric missing from PUT to be removed")
+		}
+	})
+
+	t.Run("POST replaces metrics with the same name", func(t *testing.T) {
+		s, registry := newTestStore()
+
+		pushMetrics(t, s, http.MethodPut, "/metrics/job/backup/instance/db-1", "backup_bytes{disk=\"a\"} 10\nbackup_bytes{disk=\"b\"} 20\nbackup_duration_seconds 3.5\n")
+		rec := pushMetrics(t, s, http.MethodPost, "/metrics/job/backup/instance/db-1", "backup_bytes{disk=\"c\"} 30\n")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+
+		if _, ok := registry.Get("backup_bytes", withGroup(map[string]string{"disk": "a"})); ok {
+			t.Error("Expected series of the pushed name to be replaced")
+		}
+		if _, ok := registry.Get("backup_bytes", withGroup(map[string]string{"disk": "c"})); !ok {
+			t.Error("Expected pushed series to be stored")
+		}
+		if _, ok := registry.Get("backup_duration_seconds", group); !ok {
+			t.Error("Expected other metrics of the group to be kept")
+		}
+	})
+
+	t.Run("DELETE removes the group", func(t *testing.T) {
+		s, registry := newTestStore()
+
+		pushMetrics(t, s, http.MethodPut, "/metrics/job/backup/instance/db-1", "backup_bytes 10\n")
+		pushMetrics(t, s, http.MethodPut, "/metrics/job/backup/instance/db-2", "backup_bytes 20\n")
+		if rec := pushMetrics(t, s, http.MethodDelete, "/metrics/job/backup/instance/db-1", ""); rec.Code != http.StatusAccepted {
+			t.Fatalf("Expected status 202, got %d", rec.Code)
+		}
+
+		if _, ok := registry.Get("backup_bytes", group); ok {
+			t.Error("Expected metrics of the group to be removed")
+		}
+		if _, ok := registry.Get("push_time_seconds", group); ok {
+			t.Error("Expected push_time_seconds of the group to be removed")
+		}
+		if _, ok := registry.Get("backup_bytes", map[string]string{"job": "backup", "instance": "db-2"}); !ok {
+			t.Error("Expected other groups to be kept")
+		}
+	})
+
+	t.Run("Decodes base64 label values", func(t *testing.T) {
+		s, registry := newTestStore()
+
+		// "a/b" and "" encoded
+		rec := pushMetrics(t, s, http.MethodPut, "/metrics/job/backup/path@base64/YS9i/env@base64/=", "backup_bytes 1\n")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if _, ok := registry.Get("backup_bytes", map[string]string{"job": "backup", "path": "a/b"}); !ok {
+			t.Error("Expected decoded grouping label")
+		}
+	})
+
+	t.Run("Refresh restamps pushed metrics", func(t *testing.T) {
+		s, registry := newTestStore()
+
+		pushMetrics(t, s, http.MethodPut, "/metrics/job/backup/instance/db-1", "backup_bytes 10\n")
+		later := time.Unix(1700000060, 0)
+		s.now = func() time.Time { return later }
+		s.refresh()
+
+		if m, _ := registry.Get("backup_bytes", group); !m.Timestamp.Equal(later) || m.Value != 10 {
+			t.Errorf("Expected sample at %v, got %+v", later, m)
+		}
+		if m, _ := registry.Get("push_time_seconds", group); m.Value != 1700000000 {
+			t.Errorf("Expected push time to be kept, got %v", m.Value)
+		}
+	})
+
+	t.Run("Rejects invalid pushes", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			method string
+			path   string
+			body   string
+			code   int
+		}{
+			{"Missing job", http.MethodPut, "/metrics/instance/db-1", "up 1\n", http.StatusBadRequest},
+			{"Empty job", http.MethodPut, "/metrics/job/", "up 1\n", http.StatusBadRequest},
+			{"Odd path", http.MethodPut, "/metrics/job/backup/instance", "up 1\n", http.StatusBadRequest},
+			{"Invalid label name", http.MethodPut, "/metrics/job/backup/in-stance/db-1", "up 1\n", http.StatusBadRequest},
+			{"Invalid base64", http.MethodPut, "/metrics/job@base64/!!", "up 1\n", http.StatusBadRequest},
+			{"Timestamp", http.MethodPut, "/metrics/job/backup", "up 1 1700000000000\n", http.StatusBadRequest},
+			{"Conflicting label", http.MethodPost, "/metrics/job/backup", "up{job=\"other\"} 1\n", http.StatusBadRequest},
+			{"Reserved name", http.MethodPut, "/metrics/job/backup", "push_time_seconds 1\n", http.StatusBadRequest},
+			{"Unsupported method", http.MethodGet, "/metrics/job/backup", "", http.StatusMethodNotAllowed},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				s, registry := newTestStore()
+				if rec := pushMetrics(t, s, tt.method, tt.path, tt.body); rec.Code != tt.code {
+					t.Errorf("Expected status %d, got %d", tt.code, rec.Code)
+				}
+				if n := len(registry.GetAll()); n != 0 {
+					t.Errorf("Expected nothing to be stored, got %d metrics", n)
+				}
+			})
+		}
+	})
+}
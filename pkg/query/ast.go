@@ -0,0 +1,154 @@
+package query
+
+import (
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// ValueType is the type an expression evaluates to
+type ValueType string
+
+const (
+	ValueTypeScalar ValueType = "scalar"
+	ValueTypeVector ValueType = "vector"
+	ValueTypeMatrix ValueType = "matrix"
+	ValueTypeString ValueType = "string"
+)
+
+// Expr is a node of a parsed query expression
+type Expr interface {
+	// Type returns the type the expression evaluates to
+	Type() ValueType
+}
+
+// NumberLiteral is a scalar constant such as 42 or 1e3
+type NumberLiteral struct {
+	Val float64
+}
+
+// StringLiteral is a quoted string constant
+type StringLiteral struct {
+	Val string
+}
+
+// ParenExpr is an expression wrapped in parentheses
+type ParenExpr struct {
+	Expr Expr
+}
+
+// UnaryExpr negates its operand
+type UnaryExpr struct {
+	Op   itemType
+	Expr Expr
+}
+
+// VectorSelector selects the latest sample of every matching series
+type VectorSelector struct {
+	Name     string
+	Matchers []*metrics.Matcher
+	Offset   time.Duration
+}
+
+// MatrixSelector selects the samples within a time range of every matching
+// series
+type MatrixSelector struct {
+	VectorSelector *VectorSelector
+	Range          time.Duration
+}
+
+// Call is a function call
+type Call struct {
+	Func *Function
+	Args []Expr
+}
+
+// AggregateExpr aggregates a vector, optionally grouped by labels
+type AggregateExpr struct {
+	Op       string
+	Expr     Expr
+	Param    Expr
+	Grouping []string
+	Without  bool
+}
+
+// VectorMatchCardinality describes how samples of two vectors are paired
+type VectorMatchCardinality int
+
+const (
+	CardOneToOne VectorMatchCardinality = iota
+	CardManyToOne
+	CardOneToMany
+	CardManyToMany
+)
+
+// VectorMatching describes how the samples of the two sides of a binary
+// operation between vectors are matched
+type VectorMatching struct {
+	Card           VectorMatchCardinality
+	MatchingLabels []string
+	On             bool
+	Include        []string
+}
+
+// BinaryExpr applies an operator to two expressions
+type BinaryExpr struct {
+	Op         itemType
+	LHS, RHS   Expr
+	ReturnBool bool
+	Matching   *VectorMatching
+}
+
+func (*NumberLiteral) Type() ValueType  { return ValueTypeScalar }
+func (*StringLiteral) Type() ValueType  { return ValueTypeString }
+func (e *ParenExpr) Type() ValueType    { return e.Expr.Type() }
+func (e *UnaryExpr) Type() ValueType    { return e.Expr.Type() }
+func (*VectorSelector) Type() ValueType { return ValueTypeVector }
+func (*MatrixSelector) Type() ValueType { return ValueTypeMatrix }
+func (e *Call) Type() ValueType         { return e.Func.ReturnType }
+func (*AggregateExpr) Type() ValueType  { return ValueTypeVector }
+
+func (e *BinaryExpr) Type() ValueType {
+	if e.LHS.Type() == ValueTypeScalar && e.RHS.Type() == ValueTypeScalar {
+		return ValueTypeScalar
+	}
+	return ValueTypeVector
+}
+
+// unwrapParenExpr returns the expression inside any parentheses
+func unwrapParenExpr(expr Expr) Expr {
+	for {
+		p, ok := expr.(*ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = p.Expr
+	}
+}
+
+// Inspect calls f for every node of the expression tree in depth-first order
+func Inspect(expr Expr, f func(Expr)) {
+	if expr == nil {
+		return
+	}
+	f(expr)
+
+	switch e := expr.(type) {
+	case *ParenExpr:
+		Inspect(e.Expr, f)
+	case *UnaryExpr:
+		Inspect(e.Expr, f)
+	case *MatrixSelector:
+		Inspect(e.VectorSelector, f)
+	case *Call:
+		for _, arg := range e.Args {
+			Inspect(arg, f)
+		}
+	case *AggregateExpr:
+		Inspect(e.Param, f)
+		Inspect(e.Expr, f)
+	case *BinaryExpr:
+		Inspect(e.LHS, f)
+		Inspect(e.RHS, f)
+	}
+}
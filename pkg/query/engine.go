@@ -0,0 +1,660 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/instrument"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// DefaultLookbackDelta is how far back an instant selector looks for the
+// latest sample of a series
+const DefaultLookbackDelta = 5 * time.Minute
+
+// MaxRangePoints is the most steps a range query may evaluate, the same
+// limit on points per series as Prometheus
+const MaxRangePoints = 11000
+
+// Queryable provides the series an expression is evaluated against. ctx is
+// the context of the query, done when the query is cancelled.
+type Queryable interface {
+	Select(ctx context.Context, matchers []*metrics.Matcher, start, end time.Time) []*metrics.Series
+}
+
+// Engine evaluates query expressions against a Queryable
+type Engine struct {
+	queryable     Queryable
+	lookbackDelta time.Duration
+	queryLog      *queryLog
+	queryDuration *instrument.HistogramVec
+}
+
+// NewEngine creates a query engine reading from the given storage
+func NewEngine(queryable Queryable) *Engine {
+	return &Engine{
+		queryable:     queryable,
+		lookbackDelta: DefaultLookbackDelta,
+		queryDuration: instrument.NewHistogramVec("promenitheus_engine_query_duration_seconds",
+			"Duration of instant and range queries.", instrument.DefBuckets, "type"),
+	}
+}
+
+// RegisterMetrics adds the engine's own metrics to a registry
+func (e *Engine) RegisterMetrics(reg *instrument.Registry) {
+	reg.MustRegister(e.queryDuration)
+}
+
+// Instant parses and evaluates an expression at a single point in time
+func (e *Engine) Instant(ctx context.Context, qs string, ts time.Time) (val Value, err error) {
+	began := time.Now()
+	defer func() {
+		e.queryDuration.Observe(time.Since(began).Seconds(), "instant")
+		e.logQuery(qs, ts, ts, 0, began, err)
+	}()
+
+	expr, err := ParseExpr(qs)
+	if err != nil {
+		return nil, err
+	}
+	return e.EvalInstant(ctx, expr, ts)
+}
+
+// EvalInstant evaluates a parsed expression at a single point in time
+func (e *Engine) EvalInstant(ctx context.Context, expr Expr, ts time.Time) (Value, error) {
+	ev := &evaluator{ctx: ctx, engine: e, ts: ts}
+	return ev.eval(expr)
+}
+
+// Range evaluates an expression at every step between start and end,
+// inclusive, and returns the results as one series per label set. It stops
+// with the context's error when ctx is done.
+func (e *Engine) Range(ctx context.Context, qs string, start, end time.Time, step time.Duration) (res Matrix, err error) {
+	began := time.Now()
+	defer func() {
+		e.queryDuration.Observe(time.Since(began).Seconds(), "range")
+		e.logQuery(qs, start, end, step, began, err)
+	}()
+
+	if step <= 0 {
+		return nil, fmt.Errorf("zero or negative query resolution step widths are not accepted")
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("end timestamp must not be before start time")
+	}
+	if end.Sub(start)/step >= MaxRangePoints {
+		return nil, fmt.Errorf("exceeded maximum resolution of %d points per series, try increasing the query resolution step", MaxRangePoints)
+	}
+
+	expr, err := ParseExpr(qs)
+	if err != nil {
+		return nil, err
+	}
+	if t := expr.Type(); t != ValueTypeScalar && t != ValueTypeVector {
+		return nil, fmt.Errorf("invalid expression type %q for range query, must be scalar or instant vector", t)
+	}
+
+	series := map[string]*Series{}
+	for ts := start; !ts.After(end); ts = ts.Add(step) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		val, err := e.EvalInstant(ctx, expr, ts)
+		if err != nil {
+			return nil, err
+		}
+
+		var vec Vector
+		switch v := val.(type) {
+		case Scalar:
+			vec = Vector{{Labels: map[string]string{}, T: ts, V: v.V}}
+		case Vector:
+			vec = v
+		}
+
+		for _, s := range vec {
+			key := LabelsString(s.Labels)
+			ss, ok := series[key]
+			if !ok {
+				ss = &Series{Labels: s.Labels}
+				series[key] = ss
+			}
+			ss.Points = append(ss.Points, Point{T: ts, V: s.V})
+		}
+	}
+
+	keys := make([]string, 0, len(series))
+	for k := range series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make(Matrix, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, *series[k])
+	}
+	return result, nil
+}
+
+// evaluator evaluates an expression at a single timestamp
+type evaluator struct {
+	ctx    context.Context
+	engine *Engine
+	ts     time.Time
+}
+
+func (ev *evaluator) eval(expr Expr) (Value, error) {
+	switch e := expr.(type) {
+	case *NumberLiteral:
+		return Scalar{T: ev.ts, V: e.Val}, nil
+
+	case *StringLiteral:
+		return String{T: ev.ts, V: e.Val}, nil
+
+	case *ParenExpr:
+		return ev.eval(e.Expr)
+
+	case *UnaryExpr:
+		val, err := ev.eval(e.Expr)
+		if err != nil {
+			return nil, err
+		}
+		switch v := val.(type) {
+		case Scalar:
+			return Scalar{T: v.T, V: -v.V}, nil
+		case Vector:
+			out := make(Vector, 0, len(v))
+			for _, s := range v {
+				out = append(out, Sample{Labels: dropMetricName(s.Labels), T: s.T, V: -s.V})
+			}
+			return out, nil
+		}
+		return nil, fmt.Errorf("unexpected operand type %s for unary expression", val.Type())
+
+	case *VectorSelector:
+		return ev.evalVectorSelector(e), nil
+
+	case *MatrixSelector:
+		return ev.evalMatrixSelector(e), nil
+
+	case *Call:
+		args := make([]Value, 0, len(e.Args))
+		for _, arg := range e.Args {
+			val, err := ev.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, val)
+		}
+		return e.Func.call(ev, args, e)
+
+	case *AggregateExpr:
+		return ev.evalAggregate(e)
+
+	case *BinaryExpr:
+		return ev.evalBinary(e)
+	}
+
+	return nil, fmt.Errorf("unhandled expression of type %T", expr)
+}
+
+// evalVectorSelector returns the latest sample within the lookback window of
+// every matching series
+func (ev *evaluator) evalVectorSelector(vs *VectorSelector) Vector {
+	end := ev.ts.Add(-vs.Offset)
+	start := end.Add(-ev.engine.lookbackDelta)
+
+	var out Vector
+	for _, series := range ev.engine.queryable.Select(ev.ctx, vs.Matchers, start, end) {
+		if len(series.Samples) == 0 {
+			continue
+		}
+		last := series.Samples[len(series.Samples)-1]
+		if !last.Timestamp.After(start) {
+			continue
+		}
+		out = append(out, Sample{Labels: seriesLabels(series), T: ev.ts, V: last.Value})
+	}
+	return out
+}
+
+// evalMatrixSelector returns the samples within the range of every matching
+// series. The range excludes its start.
+func (ev *evaluator) evalMatrixSelector(ms *MatrixSelector) Matrix {
+	end := ev.ts.Add(-ms.VectorSelector.Offset)
+	start := end.Add(-ms.Range)
+
+	var out Matrix
+	for _, series := range ev.engine.queryable.Select(ev.ctx, ms.VectorSelector.Matchers, start, end) {
+		var points []Point
+		for _, s := range series.Samples {
+			if s.Timestamp.After(start) {
+				points = append(points, Point{T: s.Timestamp, V: s.Value})
+			}
+		}
+		if len(points) > 0 {
+			out = append(out, Series{Labels: seriesLabels(series), Points: points})
+		}
+	}
+	return out
+}
+
+// evalAggregate evaluates an aggregation over the groups of a vector
+func (ev *evaluator) evalAggregate(e *AggregateExpr) (Value, error) {
+	val, err := ev.eval(e.Expr)
+	if err != nil {
+		return nil, err
+	}
+	vec := val.(Vector)
+
+	var param float64
+	if e.Param != nil {
+		p, err := ev.eval(e.Param)
+		if err != nil {
+			return nil, err
+		}
+		param = p.(Scalar).V
+	}
+	if (e.Op == "topk" || e.Op == "bottomk") && math.IsNaN(param) {
+		return nil, fmt.Errorf("parameter value of %s is NaN", e.Op)
+	}
+
+	type group struct {
+		labels  map[string]string
+		samples []Sample
+	}
+	groups := map[string]*group{}
+	var order []string
+
+	for _, s := range vec {
+		var labels map[string]string
+		if e.Without {
+			labels = dropMetricName(s.Labels)
+			for _, name := range e.Grouping {
+				delete(labels, name)
+			}
+		} else {
+			labels = map[string]string{}
+			for _, name := range e.Grouping {
+				if v, ok := s.Labels[name]; ok {
+					labels[name] = v
+				}
+			}
+		}
+
+		key := LabelsString(labels)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{labels: labels}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.samples = append(g.samples, s)
+	}
+
+	out := Vector{}
+	for _, key := range order {
+		g := groups[key]
+
+		switch e.Op {
+		case "topk", "bottomk":
+			out = append(out, selectK(g.samples, param, e.Op == "topk")...)
+			continue
+		}
+
+		var v float64
+		switch e.Op {
+		case "sum":
+			for _, s := range g.samples {
+				v += s.V
+			}
+		case "avg":
+			for _, s := range g.samples {
+				v += s.V
+			}
+			v /= float64(len(g.samples))
+		case "count":
+			v = float64(len(g.samples))
+		case "group":
+			v = 1
+		case "min":
+			v = g.samples[0].V
+			for _, s := range g.samples[1:] {
+				if s.V < v || math.IsNaN(v) {
+					v = s.V
+				}
+			}
+		case "max":
+			v = g.samples[0].V
+			for _, s := range g.samples[1:] {
+				if s.V > v || math.IsNaN(v) {
+					v = s.V
+				}
+			}
+		case "stddev", "stdvar":
+			var mean, m2 float64
+			for i, s := range g.samples {
+				delta := s.V - mean
+				mean += delta / float64(i+1)
+				m2 += delta * (s.V - mean)
+			}
+			v = m2 / float64(len(g.samples))
+			if e.Op == "stddev" {
+				v = math.Sqrt(v)
+			}
+		case "quantile":
+			values := make([]float64, 0, len(g.samples))
+			for _, s := range g.samples {
+				values = append(values, s.V)
+			}
+			v = quantile(param, values)
+		default:
+			return nil, fmt.Errorf("unsupported aggregation %q", e.Op)
+		}
+		out = append(out, Sample{Labels: g.labels, T: ev.ts, V: v})
+	}
+	return out, nil
+}
+
+// selectK returns the k largest (top) or smallest samples, keeping their labels
+func selectK(samples []Sample, k float64, top bool) []Sample {
+	if k < 1 {
+		return nil
+	}
+	// Clamp before converting, as a huge k would overflow int
+	n := len(samples)
+	if k < float64(n) {
+		n = int(k)
+	}
+	sorted := append([]Sample(nil), samples...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if top {
+			return sorted[i].V > sorted[j].V
+		}
+		return sorted[i].V < sorted[j].V
+	})
+	return sorted[:n]
+}
+
+// quantile calculates the φ-quantile of values using linear interpolation
+func quantile(q float64, values []float64) float64 {
+	if len(values) == 0 || math.IsNaN(q) {
+		return math.NaN()
+	}
+	if q < 0 {
+		return math.Inf(-1)
+	}
+	if q > 1 {
+		return math.Inf(1)
+	}
+	sort.Float64s(values)
+
+	rank := q * float64(len(values)-1)
+	lower := math.Floor(rank)
+	upper := math.Ceil(rank)
+	weight := rank - lower
+	return values[int(lower)]*(1-weight) + values[int(upper)]*weight
+}
+
+// evalBinary evaluates a binary operation between scalars and vectors
+func (ev *evaluator) evalBinary(e *BinaryExpr) (Value, error) {
+	lhs, err := ev.eval(e.LHS)
+	if err != nil {
+		return nil, err
+	}
+	rhs, err := ev.eval(e.RHS)
+	if err != nil {
+		return nil, err
+	}
+
+	switch l := lhs.(type) {
+	case Scalar:
+		switch r := rhs.(type) {
+		case Scalar:
+			v, keep := binop(e.Op, l.V, r.V)
+			if e.Op.isComparison() {
+				v = boolValue(keep)
+			}
+			return Scalar{T: ev.ts, V: v}, nil
+		case Vector:
+			return vectorScalarBinop(e.Op, r, l.V, true, e.ReturnBool), nil
+		}
+	case Vector:
+		switch r := rhs.(type) {
+		case Scalar:
+			return vectorScalarBinop(e.Op, l, r.V, false, e.ReturnBool), nil
+		case Vector:
+			switch e.Op {
+			case itemAnd, itemOr, itemUnless:
+				return vectorSetOp(e.Op, l, r, e.Matching), nil
+			}
+			return vectorBinop(e.Op, l, r, e.Matching, e.ReturnBool)
+		}
+	}
+	return nil, fmt.Errorf("unsupported operand types %s and %s for %s", lhs.Type(), rhs.Type(), e.Op)
+}
+
+// binop applies an arithmetic or comparison operator. For comparisons the
+// returned value is lhs and keep reports whether the comparison holds.
+func binop(op itemType, lhs, rhs float64) (float64, bool) {
+	switch op {
+	case itemAdd:
+		return lhs + rhs, true
+	case itemSub:
+		return lhs - rhs, true
+	case itemMul:
+		return lhs * rhs, true
+	case itemDiv:
+		return lhs / rhs, true
+	case itemMod:
+		return math.Mod(lhs, rhs), true
+	case itemPow:
+		return math.Pow(lhs, rhs), true
+	case itemEql:
+		return lhs, lhs == rhs
+	case itemNeq:
+		return lhs, lhs != rhs
+	case itemGtr:
+		return lhs, lhs > rhs
+	case itemLss:
+		return lhs, lhs < rhs
+	case itemGte:
+		return lhs, lhs >= rhs
+	case itemLte:
+		return lhs, lhs <= rhs
+	}
+	return math.NaN(), false
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// vectorScalarBinop applies an operator between every sample of a vector and
+// a scalar. swap is set when the scalar is the left operand.
+func vectorScalarBinop(op itemType, vec Vector, scalar float64, swap, returnBool bool) Vector {
+	out := Vector{}
+	for _, s := range vec {
+		lv, rv := s.V, scalar
+		if swap {
+			lv, rv = scalar, s.V
+		}
+		v, keep := binop(op, lv, rv)
+
+		if op.isComparison() {
+			// Filters keep the vector's value even if it is the right operand
+			if swap {
+				v = rv
+			}
+			if returnBool {
+				v, keep = boolValue(keep), true
+			}
+		}
+		if !keep {
+			continue
+		}
+
+		labels := s.Labels
+		if !op.isComparison() || returnBool {
+			labels = dropMetricName(labels)
+		}
+		out = append(out, Sample{Labels: labels, T: s.T, V: v})
+	}
+	return out
+}
+
+// matchingSignature returns a function computing the key two samples must
+// share to be matched
+func matchingSignature(matching *VectorMatching) func(map[string]string) string {
+	if matching.On {
+		return func(labels map[string]string) string {
+			return signature(labels, matching.MatchingLabels, true)
+		}
+	}
+	excluded := append([]string{metrics.MetricNameLabel}, matching.MatchingLabels...)
+	return func(labels map[string]string) string {
+		return signature(labels, excluded, false)
+	}
+}
+
+// vectorSetOp implements and, or and unless
+func vectorSetOp(op itemType, lhs, rhs Vector, matching *VectorMatching) Vector {
+	sig := matchingSignature(matching)
+
+	rhsSigs := map[string]bool{}
+	for _, s := range rhs {
+		rhsSigs[sig(s.Labels)] = true
+	}
+
+	out := Vector{}
+	switch op {
+	case itemAnd:
+		for _, s := range lhs {
+			if rhsSigs[sig(s.Labels)] {
+				out = append(out, s)
+			}
+		}
+	case itemUnless:
+		for _, s := range lhs {
+			if !rhsSigs[sig(s.Labels)] {
+				out = append(out, s)
+			}
+		}
+	case itemOr:
+		lhsSigs := map[string]bool{}
+		for _, s := range lhs {
+			lhsSigs[sig(s.Labels)] = true
+			out = append(out, s)
+		}
+		for _, s := range rhs {
+			if !lhsSigs[sig(s.Labels)] {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// vectorBinop applies an arithmetic or comparison operator between matching
+// samples of two vectors
+func vectorBinop(op itemType, lhs, rhs Vector, matching *VectorMatching, returnBool bool) (Vector, error) {
+	sig := matchingSignature(matching)
+
+	// The "one" side of the match is looked up by signature
+	if matching.Card == CardOneToMany {
+		lhs, rhs = rhs, lhs
+	}
+	oneSide := map[string]Sample{}
+	for _, s := range rhs {
+		key := sig(s.Labels)
+		if _, dup := oneSide[key]; dup {
+			side := "right"
+			if matching.Card == CardOneToMany {
+				side = "left"
+			}
+			return nil, fmt.Errorf("found duplicate series for the match group %s on the %s hand-side of the operation; many-to-many matching not allowed", key, side)
+		}
+		oneSide[key] = s
+	}
+
+	matched := map[string]bool{}
+	outputs := map[string]bool{}
+	out := Vector{}
+	for _, ls := range lhs {
+		key := sig(ls.Labels)
+		rs, ok := oneSide[key]
+		if !ok {
+			continue
+		}
+		if matching.Card == CardOneToOne {
+			if matched[key] {
+				return nil, fmt.Errorf("multiple matches for labels %s: many-to-one matching must be explicit (group_left/group_right)", key)
+			}
+			matched[key] = true
+		}
+
+		lv, rv := ls.V, rs.V
+		if matching.Card == CardOneToMany {
+			lv, rv = rv, lv
+		}
+		v, keep := binop(op, lv, rv)
+		if returnBool {
+			v, keep = boolValue(keep), true
+		}
+		if !keep {
+			continue
+		}
+
+		labels := resultLabels(op, ls.Labels, rs.Labels, matching, returnBool)
+		outKey := LabelsString(labels)
+		if outputs[outKey] {
+			return nil, fmt.Errorf("multiple matches for labels %s: grouping labels must ensure unique matches", outKey)
+		}
+		outputs[outKey] = true
+		out = append(out, Sample{Labels: labels, T: ls.T, V: v})
+	}
+	return out, nil
+}
+
+// resultLabels builds the labels of the result of a vector binary operation
+// from the labels of the "many" side and the matched "one" side
+func resultLabels(op itemType, many, one map[string]string, matching *VectorMatching, returnBool bool) map[string]string {
+	labels := copyLabels(many)
+	if !op.isComparison() || returnBool {
+		delete(labels, metrics.MetricNameLabel)
+	}
+
+	if matching.Card == CardOneToOne {
+		if matching.On {
+			keep := map[string]bool{}
+			for _, name := range matching.MatchingLabels {
+				keep[name] = true
+			}
+			for name := range labels {
+				if !keep[name] {
+					delete(labels, name)
+				}
+			}
+		} else {
+			for _, name := range matching.MatchingLabels {
+				delete(labels, name)
+			}
+		}
+	}
+
+	for _, name := range matching.Include {
+		if v := one[name]; v != "" {
+			labels[name] = v
+		} else {
+			delete(labels, name)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,326 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// loadSeries registers one sample per step for a series, starting at start
+func loadSeries(registry *metrics.MetricRegistry, name string, labels map[string]string, start time.Time, step time.Duration, values ...float64) {
+	for i, v := range values {
+		registry.Register(&metrics.Metric{
+			Name:      name,
+			Type:      metrics.MetricTypeCounter,
+			Value:     v,
+			Labels:    labels,
+			Timestamp: start.Add(time.Duration(i) * step),
+		})
+	}
+}
+
+// findSample returns the sample of a vector whose labels match exactly
+func findSample(vec Vector, labels map[string]string) (Sample, bool) {
+	want := LabelsString(labels)
+	for _, s := range vec {
+		if LabelsString(s.Labels) == want {
+			return s, true
+		}
+	}
+	return Sample{}, false
+}
+
+func TestEngineInstant(t *testing.T) {
+	start := time.Unix(1000, 0)
+	registry := metrics.NewMetricRegistry()
+
+	// Counters increasing by 10 every 15s
+	loadSeries(registry, "http_requests_total", map[string]string{"job": "api", "instance": "a"}, start, 15*time.Second, 0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110, 120, 130, 140, 150, 160, 170, 180, 190, 200)
+	loadSeries(registry, "http_requests_total", map[string]string{"job": "api", "instance": "b"}, start, 15*time.Second, 0, 20, 40, 60, 80, 100, 120, 140, 160, 180, 200, 220, 240, 260, 280, 300, 320, 340, 360, 380, 400)
+	loadSeries(registry, "http_requests_total", map[string]string{"job": "web", "instance": "c"}, start, 15*time.Second, 0, 5, 10, 15, 20, 25, 30, 35, 40, 45, 50, 55, 60, 65, 70, 75, 80, 85, 90, 95, 100)
+	loadSeries(registry, "job_env", map[string]string{"job": "api", "env": "prod"}, start.Add(4*time.Minute), 15*time.Second, 1)
+
+	engine := NewEngine(registry)
+	ts := start.Add(300 * time.Second)
+
+	instant := func(t *testing.T, qs string) Value {
+		t.Helper()
+		val, err := engine.Instant(context.Background(), qs, ts)
+		if err != nil {
+			t.Fatalf("Unexpected error evaluating %q: %v", qs, err)
+		}
+		return val
+	}
+
+	t.Run("Vector selector", func(t *testing.T) {
+		vec := instant(t, `http_requests_total{job="api"}`).(Vector)
+		if len(vec) != 2 {
+			t.Fatalf("Expected 2 samples, got %d", len(vec))
+		}
+		s, ok := findSample(vec, map[string]string{"__name__": "http_requests_total", "job": "api", "instance": "a"})
+		if !ok || s.V != 200 {
+			t.Errorf("Expected instance a to be 200, got %v (found %v)", s.V, ok)
+		}
+	})
+
+	t.Run("Lookback delta", func(t *testing.T) {
+		val, err := engine.Instant(context.Background(), `http_requests_total`, ts.Add(10*time.Minute))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(val.(Vector)) != 0 {
+			t.Errorf("Expected stale series to be dropped, got %d samples", len(val.(Vector)))
+		}
+	})
+
+	t.Run("Offset", func(t *testing.T) {
+		vec := instant(t, `http_requests_total{instance="a"} offset 1m`).(Vector)
+		if len(vec) != 1 || vec[0].V != 160 {
+			t.Errorf("Expected 160, got %v", vec)
+		}
+	})
+
+	t.Run("Rate", func(t *testing.T) {
+		vec := instant(t, `rate(http_requests_total{instance="a"}[5m])`).(Vector)
+		if len(vec) != 1 {
+			t.Fatalf("Expected 1 sample, got %d", len(vec))
+		}
+		if math.Abs(vec[0].V-10.0/15) > 1e-9 {
+			t.Errorf("Expected rate %v, got %v", 10.0/15, vec[0].V)
+		}
+		if _, ok := vec[0].Labels["__name__"]; ok {
+			t.Error("Expected metric name to be dropped")
+		}
+	})
+
+	t.Run("Parenthesized range", func(t *testing.T) {
+		vec := instant(t, `rate(((http_requests_total{instance="a"}[5m])))`).(Vector)
+		if len(vec) != 1 || math.Abs(vec[0].V-10.0/15) > 1e-9 {
+			t.Errorf("Expected rate %v, got %v", 10.0/15, vec)
+		}
+		vec = instant(t, `absent_over_time((nonexistent{job="x"}[5m]))`).(Vector)
+		if len(vec) != 1 || vec[0].Labels["job"] != "x" {
+			t.Errorf("Expected absent sample with job=x, got %v", vec)
+		}
+	})
+
+	t.Run("Increase", func(t *testing.T) {
+		vec := instant(t, `increase(http_requests_total{instance="a"}[1m])`).(Vector)
+		if len(vec) != 1 || math.Abs(vec[0].V-40) > 1e-9 {
+			t.Errorf("Expected increase of 40, got %v", vec)
+		}
+	})
+
+	t.Run("Counter reset", func(t *testing.T) {
+		r := metrics.NewMetricRegistry()
+		loadSeries(r, "c", nil, start, 15*time.Second, 10, 20, 5, 15)
+		val, err := NewEngine(r).Instant(context.Background(), `resets(c[1m])`, start.Add(45*time.Second))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if vec := val.(Vector); len(vec) != 1 || vec[0].V != 1 {
+			t.Errorf("Expected 1 reset, got %v", vec)
+		}
+	})
+
+	t.Run("Sum by", func(t *testing.T) {
+		vec := instant(t, `sum by (job) (http_requests_total)`).(Vector)
+		if len(vec) != 2 {
+			t.Fatalf("Expected 2 groups, got %d", len(vec))
+		}
+		s, ok := findSample(vec, map[string]string{"job": "api"})
+		if !ok || s.V != 600 {
+			t.Errorf("Expected api sum 600, got %v", s.V)
+		}
+	})
+
+	t.Run("Aggregation without", func(t *testing.T) {
+		vec := instant(t, `max without (instance) (http_requests_total)`).(Vector)
+		s, ok := findSample(vec, map[string]string{"job": "api"})
+		if !ok || s.V != 400 {
+			t.Errorf("Expected api max 400, got %v", vec)
+		}
+	})
+
+	t.Run("Topk keeps labels", func(t *testing.T) {
+		vec := instant(t, `topk(1, http_requests_total)`).(Vector)
+		if len(vec) != 1 || vec[0].Labels["instance"] != "b" {
+			t.Errorf("Expected instance b, got %v", vec)
+		}
+	})
+
+	t.Run("Topk parameter bounds", func(t *testing.T) {
+		if vec := instant(t, `topk(1e20, http_requests_total)`).(Vector); len(vec) != 3 {
+			t.Errorf("Expected all 3 series for huge k, got %v", vec)
+		}
+		if vec := instant(t, `bottomk(2.9, http_requests_total)`).(Vector); len(vec) != 2 {
+			t.Errorf("Expected 2 series for k 2.9, got %v", vec)
+		}
+		for _, qs := range []string{`topk(-1, http_requests_total)`, `bottomk(0.5, http_requests_total)`, `topk(-1e20, http_requests_total)`} {
+			if vec := instant(t, qs).(Vector); len(vec) != 0 {
+				t.Errorf("Expected empty result for %s, got %v", qs, vec)
+			}
+		}
+		if _, err := engine.Instant(context.Background(), `topk(NaN, http_requests_total)`, ts); err == nil || !strings.Contains(err.Error(), "NaN") {
+			t.Errorf("Expected NaN parameter error, got %v", err)
+		}
+	})
+
+	t.Run("Scalar arithmetic", func(t *testing.T) {
+		s := instant(t, `1 + 2 * 3 ^ 2`).(Scalar)
+		if s.V != 19 {
+			t.Errorf("Expected 19, got %v", s.V)
+		}
+	})
+
+	t.Run("Vector scalar comparison filters", func(t *testing.T) {
+		vec := instant(t, `http_requests_total > 150`).(Vector)
+		if len(vec) != 2 {
+			t.Errorf("Expected 2 samples above 150, got %d", len(vec))
+		}
+		vec = instant(t, `http_requests_total > bool 150`).(Vector)
+		if len(vec) != 3 {
+			t.Errorf("Expected 3 samples with bool modifier, got %d", len(vec))
+		}
+	})
+
+	t.Run("Vector matching", func(t *testing.T) {
+		vec := instant(t, `http_requests_total{instance="a"} / ignoring(instance) http_requests_total{instance="b"}`).(Vector)
+		if len(vec) != 1 || vec[0].V != 0.5 {
+			t.Errorf("Expected 0.5, got %v", vec)
+		}
+	})
+
+	t.Run("Group left", func(t *testing.T) {
+		vec := instant(t, `http_requests_total * on(job) group_left(env) job_env`).(Vector)
+		if len(vec) != 2 {
+			t.Fatalf("Expected 2 samples, got %d", len(vec))
+		}
+		for _, s := range vec {
+			if s.Labels["env"] != "prod" {
+				t.Errorf("Expected env label to be copied, got %v", s.Labels)
+			}
+		}
+	})
+
+	t.Run("Many to many is rejected", func(t *testing.T) {
+		if _, err := engine.Instant(context.Background(), `http_requests_total + on(job) http_requests_total`, ts); err == nil {
+			t.Error("Expected error for duplicate matches")
+		}
+	})
+
+	t.Run("Set operators", func(t *testing.T) {
+		vec := instant(t, `http_requests_total unless on(job) job_env`).(Vector)
+		if len(vec) != 1 || vec[0].Labels["job"] != "web" {
+			t.Errorf("Expected only web, got %v", vec)
+		}
+	})
+
+	t.Run("Absent", func(t *testing.T) {
+		vec := instant(t, `absent(nonexistent{job="x"})`).(Vector)
+		if len(vec) != 1 || vec[0].Labels["job"] != "x" {
+			t.Errorf("Expected absent sample with job=x, got %v", vec)
+		}
+		vec = instant(t, `absent(http_requests_total)`).(Vector)
+		if len(vec) != 0 {
+			t.Errorf("Expected empty result, got %v", vec)
+		}
+	})
+}
+
+func TestEngineRange(t *testing.T) {
+	start := time.Unix(1000, 0)
+	registry := metrics.NewMetricRegistry()
+	loadSeries(registry, "queue_size", map[string]string{"job": "worker"}, start, time.Minute, 1, 2, 3, 4)
+
+	engine := NewEngine(registry)
+	matrix, err := engine.Range(context.Background(), `queue_size * 2`, start, start.Add(3*time.Minute), time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(matrix) != 1 {
+		t.Fatalf("Expected 1 series, got %d", len(matrix))
+	}
+	if len(matrix[0].Points) != 4 {
+		t.Fatalf("Expected 4 points, got %d", len(matrix[0].Points))
+	}
+	for i, p := range matrix[0].Points {
+		if p.V != float64(2*(i+1)) {
+			t.Errorf("Expected point %d to be %d, got %v", i, 2*(i+1), p.V)
+		}
+	}
+
+	if _, err := engine.Range(context.Background(), `queue_size[5m]`, start, start, time.Minute); err == nil {
+		t.Error("Expected error for range vector in range query")
+	}
+
+	t.Run("Reject too many points", func(t *testing.T) {
+		_, err := engine.Range(context.Background(), `queue_size`, start, start.Add(365*24*time.Hour), time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "exceeded maximum resolution") {
+			t.Errorf("Expected maximum resolution error, got %v", err)
+		}
+
+		end := start.Add((MaxRangePoints - 1) * time.Second)
+		if _, err := engine.Range(context.Background(), `queue_size`, start, end, time.Second); err != nil {
+			t.Errorf("Expected %d points to be accepted, got %v", MaxRangePoints, err)
+		}
+	})
+
+	t.Run("Stop when context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := engine.Range(ctx, `queue_size`, start, start.Add(3*time.Minute), time.Minute); err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestQueryLog(t *testing.T) {
+	start := time.Unix(1000, 0)
+	registry := metrics.NewMetricRegistry()
+	loadSeries(registry, "up", map[string]string{"job": "api"}, start, time.Minute, 1, 1)
+
+	var buf bytes.Buffer
+	engine := NewEngine(registry)
+	engine.SetQueryLog(&buf)
+
+	if _, err := engine.Instant(context.Background(), `up`, start); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := engine.Range(context.Background(), `up`, start, start.Add(time.Minute), 30*time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := engine.Instant(context.Background(), `sum(`, start); err == nil {
+		t.Fatal("Expected parse error")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 log lines, got %d:\n%s", len(lines), buf.String())
+	}
+
+	var entries []queryLogEntry
+	for _, line := range lines {
+		var entry queryLogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Invalid log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if p := entries[0].Params; p.Query != "up" || !p.Start.Equal(start) || p.Step != 0 || entries[0].Error != "" {
+		t.Errorf("Unexpected instant query entry: %+v", entries[0])
+	}
+	if p := entries[1].Params; !p.End.Equal(start.Add(time.Minute)) || p.Step != 30 {
+		t.Errorf("Unexpected range query entry: %+v", entries[1])
+	}
+	if entries[2].Params.Query != "sum(" || entries[2].Error == "" {
+		t.Errorf("Expected failed query to be logged with its error, got %+v", entries[2])
+	}
+}
@@ -0,0 +1,469 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// Function describes a query function and its signature
+type Function struct {
+	Name         string
+	ArgTypes     []ValueType
+	OptionalArgs int
+	ReturnType   ValueType
+
+	call func(ev *evaluator, args []Value, call *Call) (Value, error)
+}
+
+// functions lists the supported query functions by name
+var functions = map[string]*Function{}
+
+func init() {
+	register := func(name string, argTypes []ValueType, optional int, returnType ValueType, call func(*evaluator, []Value, *Call) (Value, error)) {
+		functions[name] = &Function{Name: name, ArgTypes: argTypes, OptionalArgs: optional, ReturnType: returnType, call: call}
+	}
+	matrixArg := []ValueType{ValueTypeMatrix}
+	vectorArg := []ValueType{ValueTypeVector}
+
+	// Counter and gauge range functions
+	register("rate", matrixArg, 0, ValueTypeVector, rangeFunc(func(points []Point, start, end time.Time) (float64, bool) {
+		return extrapolatedRate(points, start, end, true, true)
+	}))
+	register("increase", matrixArg, 0, ValueTypeVector, rangeFunc(func(points []Point, start, end time.Time) (float64, bool) {
+		return extrapolatedRate(points, start, end, true, false)
+	}))
+	register("delta", matrixArg, 0, ValueTypeVector, rangeFunc(func(points []Point, start, end time.Time) (float64, bool) {
+		return extrapolatedRate(points, start, end, false, false)
+	}))
+	register("irate", matrixArg, 0, ValueTypeVector, rangeFunc(func(points []Point, _, _ time.Time) (float64, bool) {
+		return instantValue(points, true)
+	}))
+	register("idelta", matrixArg, 0, ValueTypeVector, rangeFunc(func(points []Point, _, _ time.Time) (float64, bool) {
+		return instantValue(points, false)
+	}))
+	register("changes", matrixArg, 0, ValueTypeVector, rangeFunc(func(points []Point, _, _ time.Time) (float64, bool) {
+		changes := 0
+		for i := 1; i < len(points); i++ {
+			if points[i].V != points[i-1].V && !(math.IsNaN(points[i].V) && math.IsNaN(points[i-1].V)) {
+				changes++
+			}
+		}
+		return float64(changes), true
+	}))
+	register("resets", matrixArg, 0, ValueTypeVector, rangeFunc(func(points []Point, _, _ time.Time) (float64, bool) {
+		resets := 0
+		for i := 1; i < len(points); i++ {
+			if points[i].V < points[i-1].V {
+				resets++
+			}
+		}
+		return float64(resets), true
+	}))
+
+	// Aggregations over time
+	register("avg_over_time", matrixArg, 0, ValueTypeVector, overTimeFunc(func(points []Point) float64 {
+		sum := 0.0
+		for _, p := range points {
+			sum += p.V
+		}
+		return sum / float64(len(points))
+	}))
+	register("sum_over_time", matrixArg, 0, ValueTypeVector, overTimeFunc(func(points []Point) float64 {
+		sum := 0.0
+		for _, p := range points {
+			sum += p.V
+		}
+		return sum
+	}))
+	register("min_over_time", matrixArg, 0, ValueTypeVector, overTimeFunc(func(points []Point) float64 {
+		min := points[0].V
+		for _, p := range points[1:] {
+			if p.V < min || math.IsNaN(min) {
+				min = p.V
+			}
+		}
+		return min
+	}))
+	register("max_over_time", matrixArg, 0, ValueTypeVector, overTimeFunc(func(points []Point) float64 {
+		max := points[0].V
+		for _, p := range points[1:] {
+			if p.V > max || math.IsNaN(max) {
+				max = p.V
+			}
+		}
+		return max
+	}))
+	register("count_over_time", matrixArg, 0, ValueTypeVector, overTimeFunc(func(points []Point) float64 {
+		return float64(len(points))
+	}))
+	register("last_over_time", matrixArg, 0, ValueTypeVector, lastOverTime)
+	register("absent_over_time", matrixArg, 0, ValueTypeVector, absent)
+
+	// Per-sample math
+	register("abs", vectorArg, 0, ValueTypeVector, mathFunc(math.Abs))
+	register("ceil", vectorArg, 0, ValueTypeVector, mathFunc(math.Ceil))
+	register("floor", vectorArg, 0, ValueTypeVector, mathFunc(math.Floor))
+	register("exp", vectorArg, 0, ValueTypeVector, mathFunc(math.Exp))
+	register("sqrt", vectorArg, 0, ValueTypeVector, mathFunc(math.Sqrt))
+	register("ln", vectorArg, 0, ValueTypeVector, mathFunc(math.Log))
+	register("log2", vectorArg, 0, ValueTypeVector, mathFunc(math.Log2))
+	register("log10", vectorArg, 0, ValueTypeVector, mathFunc(math.Log10))
+	register("round", []ValueType{ValueTypeVector, ValueTypeScalar}, 1, ValueTypeVector, round)
+	register("clamp", []ValueType{ValueTypeVector, ValueTypeScalar, ValueTypeScalar}, 0, ValueTypeVector, clamp)
+	register("clamp_min", []ValueType{ValueTypeVector, ValueTypeScalar}, 0, ValueTypeVector, clamp)
+	register("clamp_max", []ValueType{ValueTypeVector, ValueTypeScalar}, 0, ValueTypeVector, clamp)
+
+	// Type conversions and metadata
+	register("time", nil, 0, ValueTypeScalar, func(ev *evaluator, _ []Value, _ *Call) (Value, error) {
+		return Scalar{T: ev.ts, V: float64(ev.ts.UnixNano()) / 1e9}, nil
+	})
+	register("vector", []ValueType{ValueTypeScalar}, 0, ValueTypeVector, func(ev *evaluator, args []Value, _ *Call) (Value, error) {
+		return Vector{{Labels: map[string]string{}, T: ev.ts, V: args[0].(Scalar).V}}, nil
+	})
+	register("scalar", vectorArg, 0, ValueTypeScalar, func(ev *evaluator, args []Value, _ *Call) (Value, error) {
+		v := args[0].(Vector)
+		if len(v) != 1 {
+			return Scalar{T: ev.ts, V: math.NaN()}, nil
+		}
+		return Scalar{T: ev.ts, V: v[0].V}, nil
+	})
+	register("timestamp", vectorArg, 0, ValueTypeVector, func(ev *evaluator, args []Value, _ *Call) (Value, error) {
+		var out Vector
+		for _, s := range args[0].(Vector) {
+			out = append(out, Sample{Labels: dropMetricName(s.Labels), T: ev.ts, V: float64(s.T.UnixNano()) / 1e9})
+		}
+		return out, nil
+	})
+	register("absent", vectorArg, 0, ValueTypeVector, absent)
+	register("label_replace", []ValueType{ValueTypeVector, ValueTypeString, ValueTypeString, ValueTypeString, ValueTypeString}, 0, ValueTypeVector, labelReplace)
+	register("histogram_quantile", []ValueType{ValueTypeScalar, ValueTypeVector}, 0, ValueTypeVector, histogramQuantile)
+}
+
+// rangeFunc adapts a function over the points of each series in a range to a
+// query function. The metric name is dropped from the results.
+func rangeFunc(f func(points []Point, start, end time.Time) (float64, bool)) func(*evaluator, []Value, *Call) (Value, error) {
+	return func(ev *evaluator, args []Value, call *Call) (Value, error) {
+		ms := unwrapParenExpr(call.Args[0]).(*MatrixSelector)
+		end := ev.ts.Add(-ms.VectorSelector.Offset)
+		start := end.Add(-ms.Range)
+
+		var out Vector
+		for _, series := range args[0].(Matrix) {
+			if v, ok := f(series.Points, start, end); ok {
+				out = append(out, Sample{Labels: dropMetricName(series.Labels), T: ev.ts, V: v})
+			}
+		}
+		return out, nil
+	}
+}
+
+// overTimeFunc adapts an aggregation over the points of each series in a
+// range to a query function
+func overTimeFunc(f func(points []Point) float64) func(*evaluator, []Value, *Call) (Value, error) {
+	return rangeFunc(func(points []Point, _, _ time.Time) (float64, bool) {
+		if len(points) == 0 {
+			return 0, false
+		}
+		return f(points), true
+	})
+}
+
+// lastOverTime returns the most recent point of each series, keeping the
+// metric name
+func lastOverTime(ev *evaluator, args []Value, _ *Call) (Value, error) {
+	var out Vector
+	for _, series := range args[0].(Matrix) {
+		if len(series.Points) > 0 {
+			out = append(out, Sample{Labels: series.Labels, T: ev.ts, V: series.Points[len(series.Points)-1].V})
+		}
+	}
+	return out, nil
+}
+
+// extrapolatedRate calculates the increase of a series over a range,
+// extrapolating to the range boundaries the same way Prometheus does
+func extrapolatedRate(points []Point, rangeStart, rangeEnd time.Time, isCounter, isRate bool) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+	first, last := points[0], points[len(points)-1]
+
+	result := last.V - first.V
+	if isCounter {
+		// Add back the value lost at every counter reset
+		prev := first.V
+		for _, p := range points[1:] {
+			if p.V < prev {
+				result += prev
+			}
+			prev = p.V
+		}
+	}
+
+	durationToStart := first.T.Sub(rangeStart).Seconds()
+	durationToEnd := rangeEnd.Sub(last.T).Seconds()
+	sampledInterval := last.T.Sub(first.T).Seconds()
+	averageDurationBetweenSamples := sampledInterval / float64(len(points)-1)
+
+	// Counters cannot extrapolate below zero
+	if isCounter && result > 0 && first.V >= 0 {
+		durationToZero := sampledInterval * (first.V / result)
+		if durationToZero < durationToStart {
+			durationToStart = durationToZero
+		}
+	}
+
+	// Extrapolate to the range boundaries if the samples end close to them,
+	// otherwise only by half the average sample interval
+	extrapolationThreshold := averageDurationBetweenSamples * 1.1
+	extrapolateToInterval := sampledInterval
+	if durationToStart < extrapolationThreshold {
+		extrapolateToInterval += durationToStart
+	} else {
+		extrapolateToInterval += averageDurationBetweenSamples / 2
+	}
+	if durationToEnd < extrapolationThreshold {
+		extrapolateToInterval += durationToEnd
+	} else {
+		extrapolateToInterval += averageDurationBetweenSamples / 2
+	}
+
+	result *= extrapolateToInterval / sampledInterval
+	if isRate {
+		result /= rangeEnd.Sub(rangeStart).Seconds()
+	}
+	return result, true
+}
+
+// instantValue calculates the rate or delta between the last two points
+func instantValue(points []Point, isRate bool) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+	prev, last := points[len(points)-2], points[len(points)-1]
+
+	result := last.V - prev.V
+	if isRate {
+		if last.V < prev.V {
+			// Counter reset
+			result = last.V
+		}
+		interval := last.T.Sub(prev.T).Seconds()
+		if interval == 0 {
+			return 0, false
+		}
+		result /= interval
+	}
+	return result, true
+}
+
+// mathFunc applies f to every sample of a vector
+func mathFunc(f func(float64) float64) func(*evaluator, []Value, *Call) (Value, error) {
+	return func(ev *evaluator, args []Value, _ *Call) (Value, error) {
+		var out Vector
+		for _, s := range args[0].(Vector) {
+			out = append(out, Sample{Labels: dropMetricName(s.Labels), T: ev.ts, V: f(s.V)})
+		}
+		return out, nil
+	}
+}
+
+// round rounds samples to the nearest multiple of the optional second argument
+func round(ev *evaluator, args []Value, _ *Call) (Value, error) {
+	toNearest := 1.0
+	if len(args) == 2 {
+		toNearest = args[1].(Scalar).V
+	}
+	toNearestInverse := 1.0 / toNearest
+
+	var out Vector
+	for _, s := range args[0].(Vector) {
+		v := math.Floor(s.V*toNearestInverse+0.5) / toNearestInverse
+		out = append(out, Sample{Labels: dropMetricName(s.Labels), T: ev.ts, V: v})
+	}
+	return out, nil
+}
+
+// clamp implements clamp, clamp_min and clamp_max
+func clamp(ev *evaluator, args []Value, call *Call) (Value, error) {
+	min, max := math.Inf(-1), math.Inf(1)
+	switch call.Func.Name {
+	case "clamp":
+		min, max = args[1].(Scalar).V, args[2].(Scalar).V
+		if max < min {
+			return Vector{}, nil
+		}
+	case "clamp_min":
+		min = args[1].(Scalar).V
+	case "clamp_max":
+		max = args[1].(Scalar).V
+	}
+
+	var out Vector
+	for _, s := range args[0].(Vector) {
+		out = append(out, Sample{Labels: dropMetricName(s.Labels), T: ev.ts, V: math.Max(min, math.Min(max, s.V))})
+	}
+	return out, nil
+}
+
+// absent returns a single sample with value 1 if its argument has no samples.
+// The labels of the result are taken from the equality matchers of the
+// selector, so absent(up{job="api"}) yields {job="api"}.
+func absent(ev *evaluator, args []Value, call *Call) (Value, error) {
+	switch v := args[0].(type) {
+	case Vector:
+		if len(v) > 0 {
+			return Vector{}, nil
+		}
+	case Matrix:
+		if len(v) > 0 {
+			return Vector{}, nil
+		}
+	}
+
+	labels := map[string]string{}
+	var vs *VectorSelector
+	switch arg := unwrapParenExpr(call.Args[0]).(type) {
+	case *VectorSelector:
+		vs = arg
+	case *MatrixSelector:
+		vs = arg.VectorSelector
+	}
+	if vs != nil {
+		seen := map[string]bool{}
+		for _, m := range vs.Matchers {
+			if m.Name == metrics.MetricNameLabel {
+				continue
+			}
+			if m.Type == metrics.MatchEqual && !seen[m.Name] {
+				labels[m.Name] = m.Value
+				seen[m.Name] = true
+			} else {
+				delete(labels, m.Name)
+			}
+		}
+	}
+	return Vector{{Labels: labels, T: ev.ts, V: 1}}, nil
+}
+
+// labelReplace implements label_replace(v, dst, replacement, src, regex)
+func labelReplace(ev *evaluator, args []Value, _ *Call) (Value, error) {
+	dst := args[1].(String).V
+	replacement := args[2].(String).V
+	src := args[3].(String).V
+	re, err := regexp.Compile("^(?:" + args[4].(String).V + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression in label_replace(): %s", args[4].(String).V)
+	}
+
+	var out Vector
+	for _, s := range args[0].(Vector) {
+		labels := copyLabels(s.Labels)
+		srcVal := labels[src]
+		if idx := re.FindStringSubmatchIndex(srcVal); idx != nil {
+			res := string(re.ExpandString(nil, replacement, srcVal, idx))
+			if res == "" {
+				delete(labels, dst)
+			} else {
+				labels[dst] = res
+			}
+		}
+		out = append(out, Sample{Labels: labels, T: s.T, V: s.V})
+	}
+	return out, nil
+}
+
+// histogramQuantile calculates quantiles from classic histogram buckets,
+// which are series with an le label holding cumulative counts
+func histogramQuantile(ev *evaluator, args []Value, _ *Call) (Value, error) {
+	q := args[0].(Scalar).V
+
+	type bucket struct {
+		upperBound float64
+		count      float64
+	}
+	type histogram struct {
+		labels  map[string]string
+		buckets []bucket
+	}
+
+	histograms := map[string]*histogram{}
+	var order []string
+	for _, s := range args[1].(Vector) {
+		le, err := strconv.ParseFloat(s.Labels["le"], 64)
+		if err != nil {
+			continue
+		}
+		labels := dropMetricName(s.Labels)
+		delete(labels, "le")
+		key := LabelsString(labels)
+		h, ok := histograms[key]
+		if !ok {
+			h = &histogram{labels: labels}
+			histograms[key] = h
+			order = append(order, key)
+		}
+		h.buckets = append(h.buckets, bucket{upperBound: le, count: s.V})
+	}
+
+	var out Vector
+	for _, key := range order {
+		h := histograms[key]
+		sort.Slice(h.buckets, func(i, j int) bool { return h.buckets[i].upperBound < h.buckets[j].upperBound })
+
+		var v float64
+		switch {
+		case q < 0:
+			v = math.Inf(-1)
+		case q > 1:
+			v = math.Inf(1)
+		case len(h.buckets) < 2 || !math.IsInf(h.buckets[len(h.buckets)-1].upperBound, 1):
+			v = math.NaN()
+		default:
+			v = bucketQuantile(q, h.buckets[len(h.buckets)-1].count, func(i int) (float64, float64) {
+				return h.buckets[i].upperBound, h.buckets[i].count
+			}, len(h.buckets))
+		}
+		out = append(out, Sample{Labels: h.labels, T: ev.ts, V: v})
+	}
+	return out, nil
+}
+
+// bucketQuantile interpolates a quantile linearly within the bucket it falls in
+func bucketQuantile(q, total float64, bucketAt func(int) (float64, float64), n int) float64 {
+	if total == 0 {
+		return math.NaN()
+	}
+	rank := q * total
+
+	i := 0
+	for i < n-1 {
+		_, count := bucketAt(i)
+		if count >= rank {
+			break
+		}
+		i++
+	}
+
+	upper, count := bucketAt(i)
+	if i == n-1 {
+		// Quantile falls in the +Inf bucket; return the largest finite bound
+		upper, _ = bucketAt(n - 2)
+		return upper
+	}
+	if i == 0 && upper <= 0 {
+		return upper
+	}
+
+	lower, prevCount := 0.0, 0.0
+	if i > 0 {
+		lower, prevCount = bucketAt(i - 1)
+	}
+	return lower + (upper-lower)*((rank-prevCount)/(count-prevCount))
+}
@@ -0,0 +1,362 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// itemType identifies the type of a lexical token
+type itemType int
+
+const (
+	itemEOF itemType = iota
+	itemIdentifier
+	itemNumber
+	itemDuration
+	itemString
+	itemLeftParen
+	itemRightParen
+	itemLeftBrace
+	itemRightBrace
+	itemLeftBracket
+	itemRightBracket
+	itemComma
+	itemColon
+
+	// Label matching operators
+	itemAssign    // =
+	itemRegexp    // =~
+	itemNotRegexp // !~
+
+	// Binary operators
+	itemAdd
+	itemSub
+	itemMul
+	itemDiv
+	itemMod
+	itemPow
+	itemEql // ==
+	itemNeq // !=
+	itemLss
+	itemGtr
+	itemLte
+	itemGte
+	itemAnd
+	itemOr
+	itemUnless
+)
+
+var itemNames = map[itemType]string{
+	itemEOF:          "end of input",
+	itemIdentifier:   "identifier",
+	itemNumber:       "number",
+	itemDuration:     "duration",
+	itemString:       "string",
+	itemLeftParen:    "(",
+	itemRightParen:   ")",
+	itemLeftBrace:    "{",
+	itemRightBrace:   "}",
+	itemLeftBracket:  "[",
+	itemRightBracket: "]",
+	itemComma:        ",",
+	itemColon:        ":",
+	itemAssign:       "=",
+	itemRegexp:       "=~",
+	itemNotRegexp:    "!~",
+	itemAdd:          "+",
+	itemSub:          "-",
+	itemMul:          "*",
+	itemDiv:          "/",
+	itemMod:          "%",
+	itemPow:          "^",
+	itemEql:          "==",
+	itemNeq:          "!=",
+	itemLss:          "<",
+	itemGtr:          ">",
+	itemLte:          "<=",
+	itemGte:          ">=",
+	itemAnd:          "and",
+	itemOr:           "or",
+	itemUnless:       "unless",
+}
+
+// String returns the operator or a description of the token type
+func (t itemType) String() string {
+	if name, ok := itemNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("item(%d)", int(t))
+}
+
+// isComparison reports whether the token is a comparison operator
+func (t itemType) isComparison() bool {
+	switch t {
+	case itemEql, itemNeq, itemLss, itemGtr, itemLte, itemGte:
+		return true
+	}
+	return false
+}
+
+// isSetOperator reports whether the token is a set operator
+func (t itemType) isSetOperator() bool {
+	return t == itemAnd || t == itemOr || t == itemUnless
+}
+
+// precedence returns the binding strength of a binary operator, or 0 if the
+// token is not a binary operator
+func (t itemType) precedence() int {
+	switch t {
+	case itemOr:
+		return 1
+	case itemAnd, itemUnless:
+		return 2
+	case itemEql, itemNeq, itemLss, itemGtr, itemLte, itemGte:
+		return 3
+	case itemAdd, itemSub:
+		return 4
+	case itemMul, itemDiv, itemMod:
+		return 5
+	case itemPow:
+		return 6
+	}
+	return 0
+}
+
+var twoCharOperators = map[string]itemType{
+	"==": itemEql,
+	"!=": itemNeq,
+	"<=": itemLte,
+	">=": itemGte,
+	"=~": itemRegexp,
+	"!~": itemNotRegexp,
+}
+
+// item is a lexical token
+type item struct {
+	typ itemType
+	pos int
+	val string
+}
+
+// lex splits an expression into tokens
+func lex(input string) ([]item, error) {
+	var items []item
+	pos := 0
+
+	for pos < len(input) {
+		r, width := utf8.DecodeRuneInString(input[pos:])
+
+		switch {
+		case unicode.IsSpace(r):
+			pos += width
+			continue
+
+		case r == '#':
+			// Comment until end of line
+			if idx := strings.IndexByte(input[pos:], '\n'); idx >= 0 {
+				pos += idx
+			} else {
+				pos = len(input)
+			}
+			continue
+
+		case r == '"' || r == '\'' || r == '`':
+			end, err := scanString(input, pos)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item{itemString, pos, input[pos:end]})
+			pos = end
+			continue
+
+		case isDigit(r) || (r == '.' && pos+1 < len(input) && isDigit(rune(input[pos+1]))):
+			typ, end := scanNumber(input, pos)
+			items = append(items, item{typ, pos, input[pos:end]})
+			pos = end
+			continue
+
+		case isIdentStart(r):
+			end := pos
+			for end < len(input) {
+				c, w := utf8.DecodeRuneInString(input[end:])
+				if !isIdentChar(c) {
+					break
+				}
+				end += w
+			}
+			word := input[pos:end]
+			typ := itemIdentifier
+			switch strings.ToLower(word) {
+			case "and":
+				typ = itemAnd
+			case "or":
+				typ = itemOr
+			case "unless":
+				typ = itemUnless
+			}
+			items = append(items, item{typ, pos, word})
+			pos = end
+			continue
+		}
+
+		// Operators and punctuation
+		if pos+2 <= len(input) {
+			if typ, ok := twoCharOperators[input[pos:pos+2]]; ok {
+				items = append(items, item{typ, pos, input[pos : pos+2]})
+				pos += 2
+				continue
+			}
+		}
+
+		var typ itemType
+		switch r {
+		case '(':
+			typ = itemLeftParen
+		case ')':
+			typ = itemRightParen
+		case '{':
+			typ = itemLeftBrace
+		case '}':
+			typ = itemRightBrace
+		case '[':
+			typ = itemLeftBracket
+		case ']':
+			typ = itemRightBracket
+		case ',':
+			typ = itemComma
+		case ':':
+			typ = itemColon
+		case '=':
+			typ = itemAssign
+		case '+':
+			typ = itemAdd
+		case '-':
+			typ = itemSub
+		case '*':
+			typ = itemMul
+		case '/':
+			typ = itemDiv
+		case '%':
+			typ = itemMod
+		case '^':
+			typ = itemPow
+		case '<':
+			typ = itemLss
+		case '>':
+			typ = itemGtr
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, pos)
+		}
+		items = append(items, item{typ, pos, string(r)})
+		pos += width
+	}
+
+	items = append(items, item{itemEOF, pos, ""})
+	return items, nil
+}
+
+// scanString returns the end offset of the quoted string starting at pos
+func scanString(input string, pos int) (int, error) {
+	quote := input[pos]
+	for i := pos + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at position %d", pos)
+}
+
+// scanNumber scans a number or a duration such as 5m or 1h30m starting at pos
+func scanNumber(input string, pos int) (itemType, int) {
+	end := pos
+	for end < len(input) && isDigit(rune(input[end])) {
+		end++
+	}
+
+	// A run of digits directly followed by a duration unit is a duration
+	if isDurationUnitStart(input, end) {
+		for end < len(input) {
+			unitEnd := scanDurationUnit(input, end)
+			if unitEnd == end {
+				break
+			}
+			end = unitEnd
+			digitsEnd := end
+			for digitsEnd < len(input) && isDigit(rune(input[digitsEnd])) {
+				digitsEnd++
+			}
+			if digitsEnd == end || !isDurationUnitStart(input, digitsEnd) {
+				break
+			}
+			end = digitsEnd
+		}
+		return itemDuration, end
+	}
+
+	if end < len(input) && input[end] == '.' {
+		end++
+		for end < len(input) && isDigit(rune(input[end])) {
+			end++
+		}
+	}
+	if end < len(input) && (input[end] == 'e' || input[end] == 'E') {
+		exp := end + 1
+		if exp < len(input) && (input[exp] == '+' || input[exp] == '-') {
+			exp++
+		}
+		if exp < len(input) && isDigit(rune(input[exp])) {
+			end = exp
+			for end < len(input) && isDigit(rune(input[end])) {
+				end++
+			}
+		}
+	}
+	return itemNumber, end
+}
+
+// scanDurationUnit returns the end offset of the duration unit at pos, or pos
+// if there is none
+func scanDurationUnit(input string, pos int) int {
+	if strings.HasPrefix(input[pos:], "ms") {
+		return pos + 2
+	}
+	if pos < len(input) && strings.IndexByte("smhdwy", input[pos]) >= 0 {
+		return pos + 1
+	}
+	return pos
+}
+
+// isDurationUnitStart reports whether a duration unit starts at pos and is
+// not part of a longer identifier
+func isDurationUnitStart(input string, pos int) bool {
+	end := scanDurationUnit(input, pos)
+	if end == pos {
+		return false
+	}
+	if end < len(input) {
+		r, _ := utf8.DecodeRuneInString(input[end:])
+		if isIdentChar(r) && !isDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || (r < utf8.RuneSelf && unicode.IsLetter(r))
+}
+
+func isIdentChar(r rune) bool {
+	return r == '_' || r == ':' || (r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)))
+}
@@ -0,0 +1,666 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// aggregators lists the supported aggregation operators and whether they take
+// a parameter
+var aggregators = map[string]bool{
+	"sum":      false,
+	"avg":      false,
+	"min":      false,
+	"max":      false,
+	"count":    false,
+	"group":    false,
+	"stddev":   false,
+	"stdvar":   false,
+	"topk":     true,
+	"bottomk":  true,
+	"quantile": true,
+}
+
+// parser is a recursive descent parser for query expressions
+type parser struct {
+	input string
+	items []item
+	pos   int
+}
+
+// ParseExpr parses a query expression such as
+// sum by (job) (rate(http_requests_total[5m]))
+func ParseExpr(input string) (Expr, error) {
+	items, err := lex(input)
+	if err != nil {
+		return nil, fmt.Errorf("parse error: %w", err)
+	}
+
+	p := &parser{input: input, items: items}
+	expr, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.typ != itemEOF {
+		return nil, p.errorf(tok, "unexpected %s", describe(tok))
+	}
+	return expr, nil
+}
+
+// ParseSelector parses a series selector such as
+// http_requests_total{job="api",method=~"GET|POST"} into label matchers
+func ParseSelector(input string) ([]*metrics.Matcher, error) {
+	expr, err := ParseExpr(input)
+	if err != nil {
+		return nil, err
+	}
+	vs, ok := expr.(*VectorSelector)
+	if !ok || vs.Offset != 0 {
+		return nil, fmt.Errorf("parse error: %q is not a series selector", input)
+	}
+	return vs.Matchers, nil
+}
+
+func (p *parser) peek() item {
+	return p.items[p.pos]
+}
+
+func (p *parser) next() item {
+	tok := p.items[p.pos]
+	if tok.typ != itemEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// peekKeyword reports whether the next token is the given keyword
+func (p *parser) peekKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.typ == itemIdentifier && strings.EqualFold(tok.val, keyword)
+}
+
+func (p *parser) expect(typ itemType, context string) (item, error) {
+	tok := p.next()
+	if tok.typ != typ {
+		return tok, p.errorf(tok, "unexpected %s in %s, expected %s", describe(tok), context, typ)
+	}
+	return tok, nil
+}
+
+func (p *parser) errorf(tok item, format string, args ...interface{}) error {
+	return fmt.Errorf("parse error at char %d: %s", tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+// describe returns a description of a token for error messages
+func describe(tok item) string {
+	switch tok.typ {
+	case itemEOF:
+		return "end of input"
+	case itemIdentifier, itemNumber, itemDuration, itemString:
+		return fmt.Sprintf("%s %q", tok.typ, tok.val)
+	}
+	return fmt.Sprintf("%q", tok.val)
+}
+
+// parseExpr parses binary expressions whose operators bind at least as
+// tightly as minPrec
+func (p *parser) parseExpr(minPrec int) (Expr, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		opTok := p.peek()
+		prec := opTok.typ.precedence()
+		if prec == 0 || prec < minPrec {
+			return lhs, nil
+		}
+		p.next()
+
+		expr := &BinaryExpr{Op: opTok.typ}
+		if p.peekKeyword("bool") {
+			if !opTok.typ.isComparison() {
+				return nil, p.errorf(p.peek(), "bool modifier can only be used on comparison operators")
+			}
+			p.next()
+			expr.ReturnBool = true
+		}
+		if err := p.parseVectorMatching(expr); err != nil {
+			return nil, err
+		}
+
+		// ^ is right-associative, all other operators are left-associative
+		nextPrec := prec + 1
+		if opTok.typ == itemPow {
+			nextPrec = prec
+		}
+		rhs, err := p.parseExpr(nextPrec)
+		if err != nil {
+			return nil, err
+		}
+
+		expr.LHS, expr.RHS = lhs, rhs
+		if err := p.checkBinaryExpr(opTok, expr); err != nil {
+			return nil, err
+		}
+		lhs = expr
+	}
+}
+
+// parseVectorMatching parses the optional on/ignoring and group_left/
+// group_right modifiers of a binary operator
+func (p *parser) parseVectorMatching(expr *BinaryExpr) error {
+	if !p.peekKeyword("on") && !p.peekKeyword("ignoring") {
+		return nil
+	}
+
+	matching := &VectorMatching{Card: CardOneToOne, On: p.peekKeyword("on")}
+	p.next()
+	labels, err := p.parseLabelList()
+	if err != nil {
+		return err
+	}
+	matching.MatchingLabels = labels
+
+	if p.peekKeyword("group_left") || p.peekKeyword("group_right") {
+		matching.Card = CardManyToOne
+		if p.peekKeyword("group_right") {
+			matching.Card = CardOneToMany
+		}
+		p.next()
+		if p.peek().typ == itemLeftParen {
+			include, err := p.parseLabelList()
+			if err != nil {
+				return err
+			}
+			matching.Include = include
+		}
+	}
+
+	expr.Matching = matching
+	return nil
+}
+
+// checkBinaryExpr validates operand types and fills in default vector matching
+func (p *parser) checkBinaryExpr(opTok item, expr *BinaryExpr) error {
+	lt, rt := expr.LHS.Type(), expr.RHS.Type()
+	if lt != ValueTypeScalar && lt != ValueTypeVector || rt != ValueTypeScalar && rt != ValueTypeVector {
+		return p.errorf(opTok, "binary expression must contain only scalar and instant vector types")
+	}
+
+	bothVectors := lt == ValueTypeVector && rt == ValueTypeVector
+	if expr.Op.isSetOperator() {
+		if !bothVectors {
+			return p.errorf(opTok, "set operator %q not allowed in binary scalar expression", expr.Op)
+		}
+		if expr.Matching == nil {
+			expr.Matching = &VectorMatching{}
+		}
+		if expr.Matching.Card != CardOneToOne {
+			return p.errorf(opTok, "no grouping allowed for %q operation", expr.Op)
+		}
+		expr.Matching.Card = CardManyToMany
+		return nil
+	}
+
+	if expr.Op.isComparison() && !expr.ReturnBool && lt == ValueTypeScalar && rt == ValueTypeScalar {
+		return p.errorf(opTok, "comparisons between scalars must use BOOL modifier")
+	}
+
+	if !bothVectors {
+		if expr.Matching != nil {
+			return p.errorf(opTok, "vector matching only allowed between instant vectors")
+		}
+		return nil
+	}
+
+	if expr.Matching == nil {
+		expr.Matching = &VectorMatching{Card: CardOneToOne}
+	}
+	return nil
+}
+
+// parseUnary parses an optionally negated operand
+func (p *parser) parseUnary() (Expr, error) {
+	tok := p.peek()
+	if tok.typ != itemAdd && tok.typ != itemSub {
+		return p.parsePrimary()
+	}
+	p.next()
+
+	// Unary operators bind more loosely than ^ so -2^2 is -(2^2)
+	operand, err := p.parseExpr(itemPow.precedence())
+	if err != nil {
+		return nil, err
+	}
+	if t := operand.Type(); t != ValueTypeScalar && t != ValueTypeVector {
+		return nil, p.errorf(tok, "unary expression only allowed on expressions of type scalar or instant vector, got %s", t)
+	}
+
+	if tok.typ == itemAdd {
+		return operand, nil
+	}
+	if num, ok := operand.(*NumberLiteral); ok {
+		return &NumberLiteral{Val: -num.Val}, nil
+	}
+	return &UnaryExpr{Op: itemSub, Expr: operand}, nil
+}
+
+// parsePrimary parses a literal, selector, call, aggregation or parenthesized
+// expression along with any range and offset modifiers
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.next()
+
+	var expr Expr
+	switch tok.typ {
+	case itemNumber:
+		val, err := strconv.ParseFloat(tok.val, 64)
+		if err != nil {
+			return nil, p.errorf(tok, "invalid number %q", tok.val)
+		}
+		expr = &NumberLiteral{Val: val}
+
+	case itemString:
+		val, err := unquote(tok.val)
+		if err != nil {
+			return nil, p.errorf(tok, "invalid string %s", tok.val)
+		}
+		expr = &StringLiteral{Val: val}
+
+	case itemLeftParen:
+		inner, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(itemRightParen, "parenthesized expression"); err != nil {
+			return nil, err
+		}
+		expr = &ParenExpr{Expr: inner}
+
+	case itemLeftBrace:
+		p.pos--
+		vs, err := p.parseVectorSelector(tok, "")
+		if err != nil {
+			return nil, err
+		}
+		expr = vs
+
+	case itemIdentifier:
+		var err error
+		expr, err = p.parseIdentifier(tok)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, p.errorf(tok, "unexpected %s", describe(tok))
+	}
+
+	return p.parsePostfix(expr)
+}
+
+// parseIdentifier parses an expression starting with an identifier: an
+// aggregation, a function call, Inf/NaN or a metric name
+func (p *parser) parseIdentifier(tok item) (Expr, error) {
+	name := tok.val
+	next := p.peek()
+
+	if _, ok := aggregators[strings.ToLower(name)]; ok &&
+		(next.typ == itemLeftParen || p.peekKeyword("by") || p.peekKeyword("without")) {
+		return p.parseAggregate(tok)
+	}
+
+	if fn, ok := functions[name]; ok && next.typ == itemLeftParen {
+		return p.parseCall(tok, fn)
+	}
+
+	switch strings.ToLower(name) {
+	case "inf":
+		return &NumberLiteral{Val: math.Inf(1)}, nil
+	case "nan":
+		return &NumberLiteral{Val: math.NaN()}, nil
+	}
+
+	if next.typ == itemLeftParen {
+		return nil, p.errorf(tok, "unknown function with name %q", name)
+	}
+	return p.parseVectorSelector(tok, name)
+}
+
+// parsePostfix parses [range] and offset modifiers following an expression
+func (p *parser) parsePostfix(expr Expr) (Expr, error) {
+	for {
+		tok := p.peek()
+		switch {
+		case tok.typ == itemLeftBracket:
+			p.next()
+			vs, ok := expr.(*VectorSelector)
+			if !ok {
+				return nil, p.errorf(tok, "ranges only allowed for vector selectors")
+			}
+			durTok, err := p.expect(itemDuration, "range")
+			if err != nil {
+				return nil, err
+			}
+			rng, err := parseDuration(durTok.val)
+			if err != nil || rng <= 0 {
+				return nil, p.errorf(durTok, "invalid range %q", durTok.val)
+			}
+			if p.peek().typ == itemColon {
+				return nil, p.errorf(p.peek(), "subqueries are not supported")
+			}
+			if _, err := p.expect(itemRightBracket, "range"); err != nil {
+				return nil, err
+			}
+			expr = &MatrixSelector{VectorSelector: vs, Range: rng}
+
+		case p.peekKeyword("offset"):
+			p.next()
+			var vs *VectorSelector
+			switch e := expr.(type) {
+			case *VectorSelector:
+				vs = e
+			case *MatrixSelector:
+				vs = e.VectorSelector
+			default:
+				return nil, p.errorf(tok, "offset modifier must be preceded by an instant or range selector")
+			}
+			if vs.Offset != 0 {
+				return nil, p.errorf(tok, "offset may not be set multiple times")
+			}
+			durTok, err := p.expect(itemDuration, "offset")
+			if err != nil {
+				return nil, err
+			}
+			offset, err := parseDuration(durTok.val)
+			if err != nil {
+				return nil, p.errorf(durTok, "invalid offset %q", durTok.val)
+			}
+			vs.Offset = offset
+
+		default:
+			return expr, nil
+		}
+	}
+}
+
+// parseVectorSelector parses an optional {label matchers} block following a
+// metric name
+func (p *parser) parseVectorSelector(tok item, name string) (*VectorSelector, error) {
+	vs := &VectorSelector{Name: name}
+	if name != "" {
+		m, err := metrics.NewMatcher(metrics.MatchEqual, metrics.MetricNameLabel, name)
+		if err != nil {
+			return nil, err
+		}
+		vs.Matchers = append(vs.Matchers, m)
+	}
+
+	if p.peek().typ == itemLeftBrace {
+		p.next()
+		for p.peek().typ != itemRightBrace {
+			m, err := p.parseLabelMatcher()
+			if err != nil {
+				return nil, err
+			}
+			if m.Name == metrics.MetricNameLabel && name != "" {
+				return nil, p.errorf(tok, "metric name must not be set twice: %q", name)
+			}
+			if m.Name == metrics.MetricNameLabel && m.Type == metrics.MatchEqual {
+				vs.Name = m.Value
+			}
+			vs.Matchers = append(vs.Matchers, m)
+
+			if p.peek().typ != itemComma {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect(itemRightBrace, "label matching"); err != nil {
+			return nil, err
+		}
+	}
+
+	// Selectors that match every series are almost always a mistake
+	for _, m := range vs.Matchers {
+		if !m.Matches("") {
+			return vs, nil
+		}
+	}
+	return nil, p.errorf(tok, "vector selector must contain at least one non-empty matcher")
+}
+
+// parseLabelMatcher parses name op "value"
+func (p *parser) parseLabelMatcher() (*metrics.Matcher, error) {
+	nameTok := p.next()
+	if nameTok.typ != itemIdentifier && !nameTok.typ.isSetOperator() {
+		return nil, p.errorf(nameTok, "unexpected %s in label matching, expected label name", describe(nameTok))
+	}
+
+	opTok := p.next()
+	var matchType metrics.MatchType
+	switch opTok.typ {
+	case itemAssign:
+		matchType = metrics.MatchEqual
+	case itemNeq:
+		matchType = metrics.MatchNotEqual
+	case itemRegexp:
+		matchType = metrics.MatchRegexp
+	case itemNotRegexp:
+		matchType = metrics.MatchNotRegexp
+	default:
+		return nil, p.errorf(opTok, "unexpected %s in label matching, expected one of =, !=, =~, !~", describe(opTok))
+	}
+
+	valTok, err := p.expect(itemString, "label matching")
+	if err != nil {
+		return nil, err
+	}
+	val, err := unquote(valTok.val)
+	if err != nil {
+		return nil, p.errorf(valTok, "invalid string %s", valTok.val)
+	}
+
+	m, err := metrics.NewMatcher(matchType, nameTok.val, val)
+	if err != nil {
+		return nil, p.errorf(valTok, "%v", err)
+	}
+	return m, nil
+}
+
+// parseLabelList parses a parenthesized, comma separated list of label names
+func (p *parser) parseLabelList() ([]string, error) {
+	if _, err := p.expect(itemLeftParen, "grouping"); err != nil {
+		return nil, err
+	}
+
+	labels := []string{}
+	for p.peek().typ != itemRightParen {
+		tok := p.next()
+		if tok.typ != itemIdentifier && !tok.typ.isSetOperator() {
+			return nil, p.errorf(tok, "unexpected %s in grouping, expected label name", describe(tok))
+		}
+		labels = append(labels, tok.val)
+
+		if p.peek().typ != itemComma {
+			break
+		}
+		p.next()
+	}
+
+	if _, err := p.expect(itemRightParen, "grouping"); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// parseAggregate parses op [by|without (labels)] ([param,] expr) [by|without (labels)]
+func (p *parser) parseAggregate(tok item) (Expr, error) {
+	agg := &AggregateExpr{Op: strings.ToLower(tok.val)}
+
+	parseGrouping := func() error {
+		if !p.peekKeyword("by") && !p.peekKeyword("without") {
+			return nil
+		}
+		if agg.Grouping != nil {
+			return p.errorf(p.peek(), "grouping may only be specified once")
+		}
+		agg.Without = p.peekKeyword("without")
+		p.next()
+		labels, err := p.parseLabelList()
+		if err != nil {
+			return err
+		}
+		agg.Grouping = labels
+		return nil
+	}
+
+	if err := parseGrouping(); err != nil {
+		return nil, err
+	}
+
+	if _, err := p.expect(itemLeftParen, "aggregation"); err != nil {
+		return nil, err
+	}
+
+	var args []Expr
+	for p.peek().typ != itemRightParen {
+		arg, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.peek().typ != itemComma {
+			break
+		}
+		p.next()
+	}
+	if _, err := p.expect(itemRightParen, "aggregation"); err != nil {
+		return nil, err
+	}
+
+	if err := parseGrouping(); err != nil {
+		return nil, err
+	}
+
+	wantArgs := 1
+	if aggregators[agg.Op] {
+		wantArgs = 2
+	}
+	if len(args) != wantArgs {
+		return nil, p.errorf(tok, "wrong number of arguments for aggregate expression provided, expected %d, got %d", wantArgs, len(args))
+	}
+
+	agg.Expr = args[len(args)-1]
+	if wantArgs == 2 {
+		agg.Param = args[0]
+		if agg.Param.Type() != ValueTypeScalar {
+			return nil, p.errorf(tok, "expected type scalar in aggregation parameter, got %s", agg.Param.Type())
+		}
+	}
+	if agg.Expr.Type() != ValueTypeVector {
+		return nil, p.errorf(tok, "expected type instant vector in aggregation expression, got %s", agg.Expr.Type())
+	}
+	return agg, nil
+}
+
+// parseCall parses the arguments of a function call and checks their types
+func (p *parser) parseCall(tok item, fn *Function) (Expr, error) {
+	p.next() // (
+
+	var args []Expr
+	for p.peek().typ != itemRightParen {
+		arg, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.peek().typ != itemComma {
+			break
+		}
+		p.next()
+	}
+	if _, err := p.expect(itemRightParen, "function call"); err != nil {
+		return nil, err
+	}
+
+	minArgs := len(fn.ArgTypes) - fn.OptionalArgs
+	if len(args) < minArgs || len(args) > len(fn.ArgTypes) {
+		if fn.OptionalArgs == 0 {
+			return nil, p.errorf(tok, "expected %d argument(s) in call to %q, got %d", len(fn.ArgTypes), fn.Name, len(args))
+		}
+		return nil, p.errorf(tok, "expected %d to %d argument(s) in call to %q, got %d", minArgs, len(fn.ArgTypes), fn.Name, len(args))
+	}
+
+	for i, arg := range args {
+		if arg.Type() != fn.ArgTypes[i] {
+			return nil, p.errorf(tok, "expected type %s in call to function %q, got %s", fn.ArgTypes[i], fn.Name, arg.Type())
+		}
+	}
+
+	return &Call{Func: fn, Args: args}, nil
+}
+
+// unquote removes the quotes from a string literal and resolves escapes
+func unquote(s string) (string, error) {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		// Go only allows single characters in single quotes
+		inner := strings.ReplaceAll(s[1:len(s)-1], `\'`, `'`)
+		inner = strings.ReplaceAll(inner, `"`, `\"`)
+		return strconv.Unquote(`"` + inner + `"`)
+	}
+	return strconv.Unquote(s)
+}
+
+// parseDuration parses a duration such as 30s, 5m, 1h30m, 2d or 1w
+func parseDuration(s string) (time.Duration, error) {
+	units := []struct {
+		suffix string
+		unit   time.Duration
+	}{
+		{"ms", time.Millisecond},
+		{"y", 365 * 24 * time.Hour},
+		{"w", 7 * 24 * time.Hour},
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+
+	var total time.Duration
+	rest := s
+	for rest != "" {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		n, err := strconv.ParseInt(rest[:i], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		rest = rest[i:]
+
+		matched := false
+		for _, u := range units {
+			if strings.HasPrefix(rest, u.suffix) {
+				total += time.Duration(n) * u.unit
+				rest = rest[len(u.suffix):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,117 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExpr(t *testing.T) {
+	t.Run("Valid expressions", func(t *testing.T) {
+		valid := []string{
+			`up`,
+			`up{job="api"}`,
+			`{__name__=~"http_.*", job!="api"}`,
+			`http_requests_total[5m]`,
+			`http_requests_total offset 1h`,
+			`rate(http_requests_total[5m])`,
+			`sum by (job) (rate(http_requests_total[5m]))`,
+			`sum(rate(http_requests_total[5m])) without (instance)`,
+			`topk(3, up)`,
+			`histogram_quantile(0.9, sum by (le) (rate(request_duration_bucket[5m])))`,
+			`a / on(job) group_left(env) b`,
+			`up == bool 1`,
+			`-up + 2 * 3 ^ 2`,
+			`up and on(job) down or other unless ignoring(instance) last`,
+			`job:http_requests:rate5m > 10`,
+			`label_replace(up, "host", "$1", "instance", "(.*):.*")`,
+		}
+		for _, input := range valid {
+			if _, err := ParseExpr(input); err != nil {
+				t.Errorf("Expected %q to parse, got %v", input, err)
+			}
+		}
+	})
+
+	t.Run("Invalid expressions", func(t *testing.T) {
+		invalid := []string{
+			``,
+			`{}`,
+			`{job=""}`,
+			`rate(up)`,
+			`sum(up[5m])`,
+			`up[5m] + 1`,
+			`unknown_func(up)`,
+			`up{job="api"`,
+			`1 and up`,
+			`up + bool 1`,
+			`topk(up)`,
+			`"foo" + 1`,
+		}
+		for _, input := range invalid {
+			if _, err := ParseExpr(input); err == nil {
+				t.Errorf("Expected %q to fail to parse", input)
+			}
+		}
+	})
+
+	t.Run("Operator precedence", func(t *testing.T) {
+		expr, err := ParseExpr(`1 + 2 * 3`)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		b, ok := expr.(*BinaryExpr)
+		if !ok || b.Op != itemAdd {
+			t.Fatalf("Expected top-level addition, got %#v", expr)
+		}
+		if _, ok := b.RHS.(*BinaryExpr); !ok {
+			t.Errorf("Expected multiplication to bind tighter, got %#v", b.RHS)
+		}
+	})
+
+	t.Run("Power is right associative", func(t *testing.T) {
+		expr, err := ParseExpr(`2 ^ 3 ^ 2`)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, ok := expr.(*BinaryExpr).RHS.(*BinaryExpr); !ok {
+			t.Errorf("Expected 2 ^ (3 ^ 2)")
+		}
+	})
+
+	t.Run("Selector with range and offset", func(t *testing.T) {
+		expr, err := ParseExpr(`http_requests_total{job="api"}[1h30m] offset 5m`)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		ms, ok := expr.(*MatrixSelector)
+		if !ok {
+			t.Fatalf("Expected matrix selector, got %T", expr)
+		}
+		if ms.Range != 90*time.Minute {
+			t.Errorf("Expected range 1h30m, got %v", ms.Range)
+		}
+		if ms.VectorSelector.Offset != 5*time.Minute {
+			t.Errorf("Expected offset 5m, got %v", ms.VectorSelector.Offset)
+		}
+		if len(ms.VectorSelector.Matchers) != 2 {
+			t.Errorf("Expected 2 matchers, got %d", len(ms.VectorSelector.Matchers))
+		}
+	})
+}
+
+func TestParseSelector(t *testing.T) {
+	matchers, err := ParseSelector(`up{job=~"api|web"}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(matchers) != 2 {
+		t.Fatalf("Expected 2 matchers, got %d", len(matchers))
+	}
+	if !matchers[1].Matches("web") || matchers[1].Matches("db") {
+		t.Errorf("Expected regex matcher to match web but not db")
+	}
+
+	if _, err := ParseSelector(`rate(up[5m])`); err == nil {
+		t.Error("Expected error for non-selector expression")
+	}
+}
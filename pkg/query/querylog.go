@@ -0,0 +1,63 @@
+package query
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// queryLogEntry is a line of the query log
+type queryLogEntry struct {
+	Params queryLogParams `json:"params"`
+	Stats  queryLogStats  `json:"stats"`
+	Error  string         `json:"error,omitempty"`
+	TS     time.Time      `json:"ts"`
+}
+
+type queryLogParams struct {
+	Query string    `json:"query"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Step is in seconds, 0 for instant queries
+	Step float64 `json:"step"`
+}
+
+type queryLogStats struct {
+	Timings struct {
+		// EvalTotalTime is in seconds
+		EvalTotalTime float64 `json:"evalTotalTime"`
+	} `json:"timings"`
+}
+
+// queryLog writes query log entries as JSON lines
+type queryLog struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// SetQueryLog writes every query run through Instant or Range to w as a
+// JSON line with its parameters, evaluation time and error, if any
+func (e *Engine) SetQueryLog(w io.Writer) {
+	e.queryLog = &queryLog{enc: json.NewEncoder(w)}
+}
+
+// logQuery records a query that started at began if a query log is set
+func (e *Engine) logQuery(qs string, start, end time.Time, step time.Duration, began time.Time, err error) {
+	if e.queryLog == nil {
+		return
+	}
+
+	entry := queryLogEntry{
+		Params: queryLogParams{Query: qs, Start: start, End: end, Step: step.Seconds()},
+		TS:     began,
+	}
+	entry.Stats.Timings.EvalTotalTime = time.Since(began).Seconds()
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	e.queryLog.mu.Lock()
+	defer e.queryLog.mu.Unlock()
+	e.queryLog.enc.Encode(entry)
+}
@@ -0,0 +1,147 @@
+package query
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// Value is the result of evaluating an expression
+type Value interface {
+	Type() ValueType
+}
+
+// Scalar is a single number
+type Scalar struct {
+	T time.Time
+	V float64
+}
+
+// String is a single string
+type String struct {
+	T time.Time
+	V string
+}
+
+// Sample is a value of a series at a point in time. Labels include the
+// metric name as __name__ unless an operation removed it.
+type Sample struct {
+	Labels map[string]string
+	T      time.Time
+	V      float64
+}
+
+// Vector is a set of samples sharing the same timestamp
+type Vector []Sample
+
+// Point is a single value at a point in time
+type Point struct {
+	T time.Time
+	V float64
+}
+
+// Series is a labelled list of points, oldest first
+type Series struct {
+	Labels map[string]string
+	Points []Point
+}
+
+// Matrix is a set of series
+type Matrix []Series
+
+func (Scalar) Type() ValueType { return ValueTypeScalar }
+func (String) Type() ValueType { return ValueTypeString }
+func (Vector) Type() ValueType { return ValueTypeVector }
+func (Matrix) Type() ValueType { return ValueTypeMatrix }
+
+// MetricName returns the __name__ label of a label set
+func MetricName(labels map[string]string) string {
+	return labels[metrics.MetricNameLabel]
+}
+
+// SplitLabels separates a label set into a metric name and the remaining
+// labels, the form used by metrics.Metric
+func SplitLabels(labels map[string]string) (string, map[string]string) {
+	rest := make(map[string]string, len(labels))
+	for k, v := range labels {
+		if k != metrics.MetricNameLabel {
+			rest[k] = v
+		}
+	}
+	return labels[metrics.MetricNameLabel], rest
+}
+
+// seriesLabels returns the label set of a stored series including __name__
+func seriesLabels(s *metrics.Series) map[string]string {
+	labels := make(map[string]string, len(s.Labels)+1)
+	for k, v := range s.Labels {
+		labels[k] = v
+	}
+	labels[metrics.MetricNameLabel] = s.Name
+	return labels
+}
+
+// copyLabels returns a copy of a label set
+func copyLabels(labels map[string]string) map[string]string {
+	c := make(map[string]string, len(labels))
+	for k, v := range labels {
+		c[k] = v
+	}
+	return c
+}
+
+// dropMetricName returns a copy of a label set without __name__
+func dropMetricName(labels map[string]string) map[string]string {
+	c := copyLabels(labels)
+	delete(c, metrics.MetricNameLabel)
+	return c
+}
+
+// LabelsString formats a label set as {a="1", b="2"} with names sorted
+func LabelsString(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range names {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(k)
+		b.WriteString(`="`)
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(labels[k], `\`, `\\`), `"`, `\"`))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// signature returns a key identifying a label set restricted to (on=true) or
+// excluding (on=false) the given names
+func signature(labels map[string]string, names []string, on bool) string {
+	subset := make(map[string]string)
+	if on {
+		for _, name := range names {
+			if v, ok := labels[name]; ok {
+				subset[name] = v
+			}
+		}
+	} else {
+		excluded := make(map[string]bool, len(names))
+		for _, name := range names {
+			excluded[name] = true
+		}
+		for k, v := range labels {
+			if !excluded[k] {
+				subset[k] = v
+			}
+		}
+	}
+	return LabelsString(subset)
+}
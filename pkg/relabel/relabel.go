@@ -0,0 +1,161 @@
+package relabel
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// Relabel actions
+const (
+	Replace   = "replace"
+	Keep      = "keep"
+	Drop      = "drop"
+	HashMod   = "hashmod"
+	LabelMap  = "labelmap"
+	LabelDrop = "labeldrop"
+	LabelKeep = "labelkeep"
+	Lowercase = "lowercase"
+	Uppercase = "uppercase"
+)
+
+// Rule is a validated relabel config with its compiled regular expression
+type Rule struct {
+	cfg   config.RelabelConfig
+	regex *regexp.Regexp
+}
+
+// NewRules validates relabel configs and compiles their regular expressions
+func NewRules(cfgs []config.RelabelConfig) ([]*Rule, error) {
+	rules := make([]*Rule, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		rule, err := newRule(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("relabel config %d: %w", i+1, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func newRule(cfg config.RelabelConfig) (*Rule, error) {
+	if cfg.Action == "" {
+		cfg.Action = Replace
+	}
+	cfg.Action = strings.ToLower(cfg.Action)
+
+	regex, err := regexp.Compile("^(?:" + cfg.Regex + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", cfg.Regex, err)
+	}
+
+	switch cfg.Action {
+	case Replace, HashMod, Lowercase, Uppercase:
+		if cfg.TargetLabel == "" {
+			return nil, fmt.Errorf("target_label is required for action %s", cfg.Action)
+		}
+		if cfg.Action == HashMod && cfg.Modulus == 0 {
+			return nil, fmt.Errorf("modulus is required for action %s", cfg.Action)
+		}
+	case Keep, Drop, LabelMap, LabelDrop, LabelKeep:
+	default:
+		return nil, fmt.Errorf("unknown action %q", cfg.Action)
+	}
+
+	return &Rule{cfg: cfg, regex: regex}, nil
+}
+
+// Process applies the rules in order to a copy of the label set. It returns
+// nil if the label set was dropped. Labels with an empty value are removed.
+func Process(labels map[string]string, rules []*Rule) map[string]string {
+	lb := make(map[string]string, len(labels))
+	for k, v := range labels {
+		lb[k] = v
+	}
+
+	for _, rule := range rules {
+		if !rule.apply(lb) {
+			return nil
+		}
+	}
+
+	for k, v := range lb {
+		if v == "" {
+			delete(lb, k)
+		}
+	}
+	return lb
+}
+
+// apply applies a single rule to lb in place. It returns false if the
+// label set is dropped.
+func (r *Rule) apply(lb map[string]string) bool {
+	values := make([]string, 0, len(r.cfg.SourceLabels))
+	for _, name := range r.cfg.SourceLabels {
+		values = append(values, lb[name])
+	}
+	val := strings.Join(values, r.cfg.Separator)
+
+	switch r.cfg.Action {
+	case Keep:
+		return r.regex.MatchString(val)
+	case Drop:
+		return !r.regex.MatchString(val)
+	case Replace:
+		indexes := r.regex.FindStringSubmatchIndex(val)
+		if indexes == nil {
+			return true
+		}
+		target := string(r.regex.ExpandString(nil, r.cfg.TargetLabel, val, indexes))
+		if !metrics.IsValidLabelName(target) {
+			return true
+		}
+		res := string(r.regex.ExpandString(nil, r.cfg.Replacement, val, indexes))
+		if res == "" {
+			delete(lb, target)
+		} else {
+			lb[target] = res
+		}
+	case Lowercase:
+		lb[r.cfg.TargetLabel] = strings.ToLower(val)
+	case Uppercase:
+		lb[r.cfg.TargetLabel] = strings.ToUpper(val)
+	case HashMod:
+		sum := md5.Sum([]byte(val))
+		mod := binary.BigEndian.Uint64(sum[8:]) % r.cfg.Modulus
+		lb[r.cfg.TargetLabel] = fmt.Sprintf("%d", mod)
+	case LabelMap:
+		for name, value := range copyLabels(lb) {
+			if r.regex.MatchString(name) {
+				lb[r.regex.ReplaceAllString(name, r.cfg.Replacement)] = value
+			}
+		}
+	case LabelDrop:
+		for name := range lb {
+			if r.regex.MatchString(name) {
+				delete(lb, name)
+			}
+		}
+	case LabelKeep:
+		for name := range lb {
+			if !r.regex.MatchString(name) {
+				delete(lb, name)
+			}
+		}
+	}
+	return true
+}
+
+// copyLabels returns a copy of a label set
+func copyLabels(labels map[string]string) map[string]string {
+	c := make(map[string]string, len(labels))
+	for k, v := range labels {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,182 @@
+package relabel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+)
+
+// rule returns a relabel config with the defaults applied by the config
+// file parser
+func rule(cfg config.RelabelConfig) config.RelabelConfig {
+	if cfg.Separator == "" {
+		cfg.Separator = ";"
+	}
+	if cfg.Regex == "" {
+		cfg.Regex = "(.*)"
+	}
+	if cfg.Replacement == "" {
+		cfg.Replacement = "$1"
+	}
+	return cfg
+}
+
+func TestProcess(t *testing.T) {
+	input := map[string]string{
+		"__name__": "http_requests_total",
+		"job":      "api",
+		"instance": "10.0.0.1:8080",
+	}
+
+	tests := []struct {
+		name     string
+		cfgs     []config.RelabelConfig
+		expected map[string]string
+	}{
+		{
+			name: "Replace with capture group",
+			cfgs: []config.RelabelConfig{rule(config.RelabelConfig{
+				SourceLabels: []string{"instance"},
+				Regex:        `([^:]+):\d+`,
+				TargetLabel:  "host",
+			})},
+			expected: map[string]string{"__name__": "http_requests_total", "job": "api", "instance": "10.0.0.1:8080", "host": "10.0.0.1"},
+		},
+		{
+			name: "Replace joins source labels",
+			cfgs: []config.RelabelConfig{rule(config.RelabelConfig{
+				SourceLabels: []string{"job", "instance"},
+				Separator:    "/",
+				TargetLabel:  "id",
+			})},
+			expected: map[string]string{"__name__": "http_requests_total", "job": "api", "instance": "10.0.0.1:8080", "id": "api/10.0.0.1:8080"},
+		},
+		{
+			name: "Replace with empty value removes label",
+			cfgs: []config.RelabelConfig{{
+				SourceLabels: []string{"missing"},
+				Separator:    ";",
+				Regex:        "(.*)",
+				TargetLabel:  "job",
+				Replacement:  "$1",
+			}},
+			expected: map[string]string{"__name__": "http_requests_total", "instance": "10.0.0.1:8080"},
+		},
+		{
+			name: "Keep matching",
+			cfgs: []config.RelabelConfig{rule(config.RelabelConfig{
+				SourceLabels: []string{"job"},
+				Regex:        "api|web",
+				Action:       Keep,
+			})},
+			expected: input,
+		},
+		{
+			name: "Keep not matching",
+			cfgs: []config.RelabelConfig{rule(config.RelabelConfig{
+				SourceLabels: []string{"job"},
+				Regex:        "web",
+				Action:       Keep,
+			})},
+			expected: nil,
+		},
+		{
+			name: "Drop matching",
+			cfgs: []config.RelabelConfig{rule(config.RelabelConfig{
+				SourceLabels: []string{"__name__"},
+				Regex:        "http_.*",
+				Action:       Drop,
+			})},
+			expected: nil,
+		},
+		{
+			name: "Labeldrop",
+			cfgs: []config.RelabelConfig{rule(config.RelabelConfig{
+				Regex:  "inst.*",
+				Action: LabelDrop,
+			})},
+			expected: map[string]string{"__name__": "http_requests_total", "job": "api"},
+		},
+		{
+			name: "Labelkeep",
+			cfgs: []config.RelabelConfig{rule(config.RelabelConfig{
+				Regex:  "__name__|job",
+				Action: LabelKeep,
+			})},
+			expected: map[string]string{"__name__": "http_requests_total", "job": "api"},
+		},
+		{
+			name: "Labelmap",
+			cfgs: []config.RelabelConfig{rule(config.RelabelConfig{
+				Regex:       "(job)",
+				Replacement: "original_$1",
+				Action:      LabelMap,
+			})},
+			expected: map[string]string{"__name__": "http_requests_total", "job": "api", "original_job": "api", "instance": "10.0.0.1:8080"},
+		},
+		{
+			name: "Uppercase",
+			cfgs: []config.RelabelConfig{rule(config.RelabelConfig{
+				SourceLabels: []string{"job"},
+				TargetLabel:  "job",
+				Action:       Uppercase,
+			})},
+			expected: map[string]string{"__name__": "http_requests_total", "job": "API", "instance": "10.0.0.1:8080"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := NewRules(tt.cfgs)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			result := Process(input, rules)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+
+	t.Run("Hashmod", func(t *testing.T) {
+		rules, err := NewRules([]config.RelabelConfig{rule(config.RelabelConfig{
+			SourceLabels: []string{"instance"},
+			Modulus:      4,
+			TargetLabel:  "shard",
+			Action:       HashMod,
+		})})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		first := Process(input, rules)["shard"]
+		if first == "" || first != Process(input, rules)["shard"] {
+			t.Errorf("Expected stable shard label, got %q", first)
+		}
+	})
+
+	t.Run("Does not modify input", func(t *testing.T) {
+		rules, _ := NewRules([]config.RelabelConfig{rule(config.RelabelConfig{Regex: "job", Action: LabelDrop})})
+		Process(input, rules)
+		if input["job"] != "api" {
+			t.Error("Expected input labels to be unchanged")
+		}
+	})
+}
+
+func TestNewRules(t *testing.T) {
+	invalid := []config.RelabelConfig{
+		rule(config.RelabelConfig{Action: "unknown"}),
+		rule(config.RelabelConfig{Action: Replace}),
+		rule(config.RelabelConfig{Action: HashMod, TargetLabel: "shard"}),
+		rule(config.RelabelConfig{Regex: "(", Action: Drop}),
+	}
+
+	for _, cfg := range invalid {
+		if _, err := NewRules([]config.RelabelConfig{cfg}); err == nil {
+			t.Errorf("Expected error for %+v", cfg)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package remote
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+)
+
+// maxSamplesPerChunk is the number of samples encoded in one chunk
+const maxSamplesPerChunk = 120
+
+// bstream is a stream of bits
+type bstream struct {
+	stream []byte
+	// count is the number of bits still free in the last byte
+	count uint8
+}
+
+func (b *bstream) writeBit(bit bool) {
+	if b.count == 0 {
+		b.stream = append(b.stream, 0)
+		b.count = 8
+	}
+	if bit {
+		b.stream[len(b.stream)-1] |= 1 << (b.count - 1)
+	}
+	b.count--
+}
+
+// writeBits writes the nbits lowest bits of u, most significant first
+func (b *bstream) writeBits(u uint64, nbits int) {
+	for i := nbits - 1; i >= 0; i-- {
+		b.writeBit(u>>uint(i)&1 == 1)
+	}
+}
+
+func (b *bstream) writeByte(byt byte) {
+	b.writeBits(uint64(byt), 8)
+}
+
+// bstreamReader reads a stream of bits
+type bstreamReader struct {
+	stream []byte
+	pos    int
+}
+
+func (r *bstreamReader) readBit() (bool, error) {
+	if r.pos >= len(r.stream)*8 {
+		return false, io.EOF
+	}
+	bit := r.stream[r.pos/8]>>(7-uint(r.pos%8))&1 == 1
+	r.pos++
+	return bit, nil
+}
+
+func (r *bstreamReader) readBits(nbits int) (uint64, error) {
+	var u uint64
+	for i := 0; i < nbits; i++ {
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+		u <<= 1
+		if bit {
+			u |= 1
+		}
+	}
+	return u, nil
+}
+
+// ReadByte implements io.ByteReader for reading varints
+func (r *bstreamReader) ReadByte() (byte, error) {
+	u, err := r.readBits(8)
+	return byte(u), err
+}
+
+// xorChunk encodes samples in the Prometheus XOR chunk format: timestamps
+// as delta-of-deltas and values XORed with the previous value
+type xorChunk struct {
+	b   bstream
+	num uint16
+
+	t        int64
+	v        float64
+	tDelta   uint64
+	leading  uint8
+	trailing uint8
+}
+
+func newXORChunk() *xorChunk {
+	c := &xorChunk{leading: 0xff}
+	// The first two bytes hold the number of samples
+	c.b.writeBits(0, 16)
+	return c
+}
+
+// Bytes returns the encoded chunk
+func (c *xorChunk) Bytes() []byte {
+	binary.BigEndian.PutUint16(c.b.stream, c.num)
+	return c.b.stream
+}
+
+// Append adds a sample with a timestamp in milliseconds
+func (c *xorChunk) Append(t int64, v float64) {
+	var tDelta uint64
+	switch c.num {
+	case 0:
+		buf := make([]byte, binary.MaxVarintLen64)
+		for _, byt := range buf[:binary.PutVarint(buf, t)] {
+			c.b.writeByte(byt)
+		}
+		c.b.writeBits(math.Float64bits(v), 64)
+	case 1:
+		tDelta = uint64(t - c.t)
+		buf := make([]byte, binary.MaxVarintLen64)
+		for _, byt := range buf[:binary.PutUvarint(buf, tDelta)] {
+			c.b.writeByte(byt)
+		}
+		c.writeVDelta(v)
+	default:
+		tDelta = uint64(t - c.t)
+		dod := int64(tDelta - c.tDelta)
+
+		switch {
+		case dod == 0:
+			c.b.writeBit(false)
+		case bitRange(dod, 14):
+			c.b.writeBits(0b10, 2)
+			c.b.writeBits(uint64(dod), 14)
+		case bitRange(dod, 17):
+			c.b.writeBits(0b110, 3)
+			c.b.writeBits(uint64(dod), 17)
+		case bitRange(dod, 20):
+			c.b.writeBits(0b1110, 4)
+			c.b.writeBits(uint64(dod), 20)
+		default:
+			c.b.writeBits(0b1111, 4)
+			c.b.writeBits(uint64(dod), 64)
+		}
+		c.writeVDelta(v)
+	}
+
+	c.t = t
+	c.v = v
+	c.tDelta = tDelta
+	c.num++
+}
+
+// bitRange reports whether x fits in nbits bits as encoded by the chunk
+func bitRange(x int64, nbits uint8) bool {
+	return -((1<<(nbits-1))-1) <= x && x <= 1<<(nbits-1)
+}
+
+func (c *xorChunk) writeVDelta(v float64) {
+	vDelta := math.Float64bits(v) ^ math.Float64bits(c.v)
+	if vDelta == 0 {
+		c.b.writeBit(false)
+		return
+	}
+	c.b.writeBit(true)
+
+	leading := uint8(bits.LeadingZeros64(vDelta))
+	trailing := uint8(bits.TrailingZeros64(vDelta))
+	// The number of leading zeros is stored in 5 bits
+	if leading >= 32 {
+		leading = 31
+	}
+
+	if c.leading != 0xff && leading >= c.leading && trailing >= c.trailing {
+		// Reuse the previous window of meaningful bits
+		c.b.writeBit(false)
+		c.b.writeBits(vDelta>>c.trailing, 64-int(c.leading)-int(c.trailing))
+		return
+	}
+
+	c.leading, c.trailing = leading, trailing
+	c.b.writeBit(true)
+	c.b.writeBits(uint64(leading), 5)
+	// 64 significant bits overflow to 0, which the decoder reads as 64
+	sigbits := 64 - leading - trailing
+	c.b.writeBits(uint64(sigbits), 6)
+	c.b.writeBits(vDelta>>trailing, int(sigbits))
+}
+
+// chunkSample is a decoded sample with a timestamp in milliseconds
+type chunkSample struct {
+	t int64
+	v float64
+}
+
+// decodeXORChunk decodes all samples of an XOR chunk
+func decodeXORChunk(data []byte) ([]chunkSample, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("chunk too short")
+	}
+	num := int(binary.BigEndian.Uint16(data))
+	r := &bstreamReader{stream: data, pos: 16}
+
+	samples := make([]chunkSample, 0, num)
+	var (
+		t                 int64
+		tDelta            uint64
+		vbits             uint64
+		leading, trailing uint8
+	)
+	for i := 0; i < num; i++ {
+		switch i {
+		case 0:
+			ts, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, fmt.Errorf("reading timestamp: %w", err)
+			}
+			v, err := r.readBits(64)
+			if err != nil {
+				return nil, fmt.Errorf("reading value: %w", err)
+			}
+			t, vbits = ts, v
+		case 1:
+			delta, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, fmt.Errorf("reading timestamp delta: %w", err)
+			}
+			tDelta = delta
+			t += int64(tDelta)
+			if err := readVDelta(r, &vbits, &leading, &trailing); err != nil {
+				return nil, err
+			}
+		default:
+			dod, err := readDod(r)
+			if err != nil {
+				return nil, err
+			}
+			tDelta = uint64(int64(tDelta) + dod)
+			t += int64(tDelta)
+			if err := readVDelta(r, &vbits, &leading, &trailing); err != nil {
+				return nil, err
+			}
+		}
+		samples = append(samples, chunkSample{t: t, v: math.Float64frombits(vbits)})
+	}
+	return samples, nil
+}
+
+// readDod reads a delta-of-delta timestamp encoded by Append
+func readDod(r *bstreamReader) (int64, error) {
+	var prefix uint8
+	for i := 0; i < 4; i++ {
+		prefix <<= 1
+		bit, err := r.readBit()
+		if err != nil {
+			return 0, fmt.Errorf("reading timestamp: %w", err)
+		}
+		if !bit {
+			break
+		}
+		prefix |= 1
+	}
+
+	var size int
+	switch prefix {
+	case 0b0:
+		return 0, nil
+	case 0b10:
+		size = 14
+	case 0b110:
+		size = 17
+	case 0b1110:
+		size = 20
+	case 0b1111:
+		u, err := r.readBits(64)
+		if err != nil {
+			return 0, fmt.Errorf("reading timestamp: %w", err)
+		}
+		return int64(u), nil
+	}
+
+	u, err := r.readBits(size)
+	if err != nil {
+		return 0, fmt.Errorf("reading timestamp: %w", err)
+	}
+	// Sign extend
+	if u > 1<<(size-1) {
+		return int64(u) - 1<<size, nil
+	}
+	return int64(u), nil
+}
+
+// readVDelta reads a value encoded by writeVDelta and applies it to vbits
+func readVDelta(r *bstreamReader, vbits *uint64, leading, trailing *uint8) error {
+	changed, err := r.readBit()
+	if err != nil {
+		return fmt.Errorf("reading value: %w", err)
+	}
+	if !changed {
+		return nil
+	}
+
+	newWindow, err := r.readBit()
+	if err != nil {
+		return fmt.Errorf("reading value: %w", err)
+	}
+	if newWindow {
+		l, err := r.readBits(5)
+		if err != nil {
+			return fmt.Errorf("reading value: %w", err)
+		}
+		sigbits, err := r.readBits(6)
+		if err != nil {
+			return fmt.Errorf("reading value: %w", err)
+		}
+		if sigbits == 0 {
+			sigbits = 64
+		}
+		*leading = uint8(l)
+		*trailing = 64 - uint8(l) - uint8(sigbits)
+	}
+
+	sigbits := 64 - int(*leading) - int(*trailing)
+	u, err := r.readBits(sigbits)
+	if err != nil {
+		return fmt.Errorf("reading value: %w", err)
+	}
+	*vbits ^= u << *trailing
+	return nil
+}
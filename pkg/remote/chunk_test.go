@@ -0,0 +1,60 @@
+package remote
+
+import (
+	"math"
+	"testing"
+)
+
+func TestXORChunk(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []chunkSample
+	}{
+		{"Single sample", []chunkSample{{t: 1700000000000, v: 1}}},
+		{"Regular interval", func() []chunkSample {
+			var s []chunkSample
+			for i := 0; i < 120; i++ {
+				s = append(s, chunkSample{t: 1700000000000 + int64(i)*15000, v: float64(i * i)})
+			}
+			return s
+		}()},
+		{"Irregular intervals", []chunkSample{
+			{t: 1000, v: 1.5}, {t: 2000, v: 1.5}, {t: 2001, v: -3.25},
+			{t: 10000, v: 1e10}, {t: 80000, v: 0}, {t: 1000000, v: math.Inf(1)},
+			{t: 1000001, v: 0.1}, {t: 1<<40 + 1, v: 0.2}, {t: 1<<40 + 2, v: 42},
+		}},
+		{"Negative timestamps", []chunkSample{{t: -5000, v: 3}, {t: -1000, v: 4}, {t: 0, v: 5}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newXORChunk()
+			for _, s := range tt.samples {
+				c.Append(s.t, s.v)
+			}
+
+			got, err := decodeXORChunk(c.Bytes())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(got) != len(tt.samples) {
+				t.Fatalf("Expected %d samples, got %d", len(tt.samples), len(got))
+			}
+			for i, s := range tt.samples {
+				if got[i] != s {
+					t.Errorf("Sample %d: expected %v, got %v", i, s, got[i])
+				}
+			}
+		})
+	}
+
+	t.Run("Truncated chunk", func(t *testing.T) {
+		c := newXORChunk()
+		c.Append(1000, 1)
+		c.Append(2000, 2)
+		data := c.Bytes()
+		if _, err := decodeXORChunk(data[:len(data)-2]); err == nil {
+			t.Error("Expected error for truncated chunk")
+		}
+	})
+}
@@ -0,0 +1,276 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+)
+
+const (
+	userAgent = "Promenitheus/1.0"
+
+	remoteWriteVersion = "0.1.0"
+	remoteReadVersion  = "0.1.0"
+
+	// maxErrorBodySize limits how much of an error response is logged
+	maxErrorBodySize = 512
+)
+
+// RecoverableError is a failed request that may succeed if retried, such as
+// a network error, a 5xx response or a 429 response
+type RecoverableError struct {
+	error
+}
+
+// Unwrap returns the underlying error
+func (e RecoverableError) Unwrap() error {
+	return e.error
+}
+
+// Client sends requests to a remote storage endpoint
+type Client struct {
+	name          string
+	url           string
+	headers       map[string]string
+	basicAuth     *config.BasicAuth
+	authorization *config.Authorization
+	client        *http.Client
+}
+
+// NewClient creates a client for a remote write endpoint
+func NewClient(cfg config.RemoteWriteConfig) (*Client, error) {
+	return newClient("remote write", cfg.URL, cfg.Name, cfg.RemoteTimeout, cfg.Headers, cfg.BasicAuth, cfg.Authorization)
+}
+
+// NewReadClient creates a client for a remote read endpoint
+func NewReadClient(cfg config.RemoteReadConfig) (*Client, error) {
+	return newClient("remote read", cfg.URL, cfg.Name, cfg.RemoteTimeout, cfg.Headers, cfg.BasicAuth, cfg.Authorization)
+}
+
+func newClient(kind, rawURL, name string, timeout time.Duration, headers map[string]string, basicAuth *config.BasicAuth, authorization *config.Authorization) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid %s url %q", kind, rawURL)
+	}
+
+	if name == "" {
+		name = rawURL
+	}
+
+	return &Client{
+		name:          name,
+		url:           rawURL,
+		headers:       headers,
+		basicAuth:     basicAuth,
+		authorization: authorization,
+		client:        &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Name identifies the endpoint in logs
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Store sends a snappy-compressed WriteRequest. Errors that may go away on
+// retry are returned as RecoverableError.
+func (c *Client) Store(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Prometheus-Remote-Write-Version", remoteWriteVersion)
+	if err := c.setAuth(req); err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return RecoverableError{err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	err = statusError(resp)
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+		return RecoverableError{err}
+	}
+	return err
+}
+
+// Read runs a query against a remote read endpoint. Streamed chunked
+// responses are preferred, but sampled responses are accepted as well.
+func (c *Client) Read(ctx context.Context, query *pb.Query) ([]*pb.TimeSeries, error) {
+	data, err := proto.Marshal(&pb.ReadRequest{
+		Queries: []*pb.Query{query},
+		AcceptedResponseTypes: []pb.ReadRequest_ResponseType{
+			pb.ReadRequest_STREAMED_XOR_CHUNKS,
+			pb.ReadRequest_SAMPLES,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(snappy.Encode(nil, data)))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Prometheus-Remote-Read-Version", remoteReadVersion)
+	if err := c.setAuth(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return nil, statusError(resp)
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), streamedContentType) {
+		return readChunkedResponse(resp.Body)
+	}
+	return readSampledResponse(resp.Body)
+}
+
+// readSampledResponse decodes a snappy-compressed ReadResponse
+func readSampledResponse(r io.Reader) ([]*pb.TimeSeries, error) {
+	compressed, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode snappy response: %w", err)
+	}
+	var resp pb.ReadResponse
+	if err := proto.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode read response: %w", err)
+	}
+	if len(resp.Results) == 0 {
+		return nil, nil
+	}
+	return resp.Results[0].Timeseries, nil
+}
+
+// readChunkedResponse decodes a stream of ChunkedReadResponse frames into
+// series of samples
+func readChunkedResponse(r io.Reader) ([]*pb.TimeSeries, error) {
+	br := bufio.NewReader(r)
+	var result []*pb.TimeSeries
+	for {
+		msg, err := readFrame(br)
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var resp pb.ChunkedReadResponse
+		if err := proto.Unmarshal(msg, &resp); err != nil {
+			return nil, fmt.Errorf("failed to decode chunked read response: %w", err)
+		}
+		for _, cs := range resp.ChunkedSeries {
+			ts := &pb.TimeSeries{Labels: cs.Labels}
+			for _, chk := range cs.Chunks {
+				if chk.Type != pb.Chunk_XOR {
+					return nil, fmt.Errorf("unsupported chunk encoding %v", chk.Type)
+				}
+				samples, err := decodeXORChunk(chk.Data)
+				if err != nil {
+					return nil, fmt.Errorf("failed to decode chunk: %w", err)
+				}
+				for _, s := range samples {
+					ts.Samples = append(ts.Samples, &pb.Sample{Timestamp: s.t, Value: s.v})
+				}
+			}
+			result = append(result, ts)
+		}
+	}
+}
+
+// statusError describes a non-2xx response including the start of its body
+func statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	return fmt.Errorf("server returned HTTP status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// setAuth adds the configured credentials to a request. Credential files
+// are read on every request so rotated secrets are picked up.
+func (c *Client) setAuth(req *http.Request) error {
+	if c.basicAuth != nil {
+		password, err := readSecret(c.basicAuth.Password, c.basicAuth.PasswordFile)
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(c.basicAuth.Username, password)
+	}
+	if c.authorization != nil {
+		credentials, err := readSecret(c.authorization.Credentials, c.authorization.CredentialsFile)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", c.authorization.Type+" "+credentials)
+	}
+	return nil
+}
+
+// readSecret returns the content of file if set, otherwise value
+func readSecret(value, file string) (string, error) {
+	if file == "" {
+		return value, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// sleep waits for d or until ctx is cancelled, reporting whether the full
+// duration passed
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,173 @@
+package remote
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// readEndpoint is a remote read client with the conditions under which it is
+// queried
+type readEndpoint struct {
+	client           *Client
+	requiredMatchers map[string]string
+	readRecent       bool
+}
+
+// Queryable merges series from a local registry with series read from
+// remote read endpoints. It implements query.Queryable.
+type Queryable struct {
+	local     *metrics.MetricRegistry
+	endpoints []*readEndpoint
+
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewQueryable creates a queryable reading the local registry and every
+// configured remote read endpoint
+func NewQueryable(local *metrics.MetricRegistry, cfgs []config.RemoteReadConfig) (*Queryable, error) {
+	q := &Queryable{local: local, now: time.Now}
+	for _, cfg := range cfgs {
+		client, err := NewReadClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		q.endpoints = append(q.endpoints, &readEndpoint{
+			client:           client,
+			requiredMatchers: cfg.RequiredMatchers,
+			readRecent:       cfg.ReadRecent,
+		})
+	}
+	return q, nil
+}
+
+// Select returns the local and remote series matching all matchers between
+// start and end. Series with the same labels are merged, preferring local
+// samples on equal timestamps. Failing endpoints are logged and skipped so
+// queries still see local data. Remote reads are cancelled with ctx.
+func (q *Queryable) Select(ctx context.Context, matchers []*metrics.Matcher, start, end time.Time) []*metrics.Series {
+	result := q.local.Select(ctx, matchers, start, end)
+
+	var query *pb.Query
+	for _, e := range q.endpoints {
+		if !e.shouldRead(matchers, start, q.now().Add(-q.local.Retention())) {
+			continue
+		}
+		if query == nil {
+			query = &pb.Query{
+				StartTimestampMs: start.UnixMilli(),
+				EndTimestampMs:   end.UnixMilli(),
+				Matchers:         matchersToProto(matchers),
+			}
+		}
+
+		series, err := e.client.Read(ctx, query)
+		if err != nil {
+			slog.Error("Error reading from remote read endpoint", "remote_name", e.client.Name(), "err", err)
+			continue
+		}
+		remote, err := protoToSeries(series, start, end)
+		if err != nil {
+			slog.Error("Error reading from remote read endpoint", "remote_name", e.client.Name(), "err", err)
+			continue
+		}
+		result = mergeSeries(result, remote)
+	}
+	return result
+}
+
+// shouldRead reports whether the endpoint is queried for a selection. Unless
+// read_recent is set, ranges fully covered by local storage are not read.
+func (e *readEndpoint) shouldRead(matchers []*metrics.Matcher, start, localStart time.Time) bool {
+	if !e.readRecent && !start.Before(localStart) {
+		return false
+	}
+	for name, value := range e.requiredMatchers {
+		found := false
+		for _, m := range matchers {
+			if m.Type == metrics.MatchEqual && m.Name == name && m.Value == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// protoToSeries converts protobuf series into registry series with the
+// samples between start and end, inclusive. Chunked responses hold whole
+// chunks, so samples outside the range are dropped, and so are series left
+// without samples.
+func protoToSeries(series []*pb.TimeSeries, start, end time.Time) ([]*metrics.Series, error) {
+	res := make([]*metrics.Series, 0, len(series))
+	for _, ts := range series {
+		name, labels, err := protoToLabels(ts.Labels)
+		if err != nil {
+			return nil, err
+		}
+		s := &metrics.Series{Name: name, Type: metrics.MetricTypeGauge, Labels: labels}
+		for _, smp := range ts.Samples {
+			t := time.UnixMilli(smp.Timestamp)
+			if t.Before(start) || t.After(end) {
+				continue
+			}
+			s.Samples = append(s.Samples, metrics.Sample{Timestamp: t, Value: smp.Value})
+		}
+		if len(s.Samples) > 0 {
+			res = append(res, s)
+		}
+	}
+	return res, nil
+}
+
+// mergeSeries adds the series of b to a. Samples of series present in both
+// are merged in timestamp order, keeping the sample of a on equal
+// timestamps.
+func mergeSeries(a, b []*metrics.Series) []*metrics.Series {
+	byKey := make(map[uint64]*metrics.Series, len(a))
+	for _, s := range a {
+		byKey[seriesKey(s)] = s
+	}
+
+	for _, s := range b {
+		existing, ok := byKey[seriesKey(s)]
+		if !ok {
+			byKey[seriesKey(s)] = s
+			a = append(a, s)
+			continue
+		}
+
+		seen := make(map[int64]bool, len(existing.Samples))
+		merged := append([]metrics.Sample(nil), existing.Samples...)
+		for _, smp := range existing.Samples {
+			seen[smp.Timestamp.UnixMilli()] = true
+		}
+		for _, smp := range s.Samples {
+			if !seen[smp.Timestamp.UnixMilli()] {
+				merged = append(merged, smp)
+			}
+		}
+		sort.SliceStable(merged, func(i, j int) bool { return merged[i].Timestamp.Before(merged[j].Timestamp) })
+		existing.Samples = merged
+	}
+	return a
+}
+
+// seriesKey identifies a series by its name and labels
+func seriesKey(s *metrics.Series) uint64 {
+	labels := make(map[string]string, len(s.Labels)+1)
+	for k, v := range s.Labels {
+		labels[k] = v
+	}
+	labels[metrics.MetricNameLabel] = s.Name
+	return seriesHash(labels)
+}
@@ -0,0 +1,450 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/relabel"
+)
+
+const (
+	// reshardInterval is how often the number of shards is recalculated
+	reshardInterval = 10 * time.Second
+	// shardTolerance is the relative change in desired shards ignored to
+	// avoid resharding on small fluctuations
+	shardTolerance = 0.3
+	// flushDeadline bounds how long stopping shards waits for queued
+	// samples to be sent
+	flushDeadline = time.Minute
+)
+
+// sample is a relabelled sample waiting to be sent
+type sample struct {
+	labels    map[string]string
+	value     float64
+	timestamp int64
+}
+
+// shard sends the samples of a subset of series in order
+type shard struct {
+	queue chan sample
+	done  chan struct{}
+}
+
+// Stats counts the samples handled by a queue
+type Stats struct {
+	// SamplesIn is the number of samples accepted into the queue
+	SamplesIn int64
+	// SamplesSent is the number of samples the endpoint accepted
+	SamplesSent int64
+	// SamplesFailed is the number of samples rejected with a
+	// non-recoverable error
+	SamplesFailed int64
+	// SamplesRetried is the number of samples in requests that were retried
+	SamplesRetried int64
+	// SamplesDropped is the number of samples dropped because a shard was
+	// full or the queue was stopped before they could be sent
+	SamplesDropped int64
+	// Shards is the current number of shards
+	Shards int
+	// Pending is the number of samples waiting to be sent
+	Pending int
+}
+
+// QueueManager sends samples to one remote write endpoint. Samples are
+// spread over shards by series, so samples of a series are sent in order,
+// and the number of shards follows the rate of incoming samples.
+type QueueManager struct {
+	cfg          config.QueueConfig
+	client       *Client
+	relabelRules []*relabel.Rule
+
+	// externalLabels are added to samples before relabeling
+	externalLabels map[string]string
+
+	// mu guards the shards, which are replaced while resharding
+	mu          sync.RWMutex
+	shards      []*shard
+	shardCancel context.CancelFunc
+	stopped     bool
+
+	statsMu         sync.Mutex
+	stats           Stats
+	sendDuration    time.Duration
+	lastSendFailure time.Time
+
+	// Counters at the previous reshard calculation
+	lastSamplesIn    int64
+	lastSamplesSent  int64
+	lastSendDuration time.Duration
+}
+
+// NewQueueManager creates a queue for a remote write endpoint and starts its
+// minimum number of shards
+func NewQueueManager(cfg config.RemoteWriteConfig) (*QueueManager, error) {
+	client, err := NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := relabel.NewRules(cfg.WriteRelabelConfigs)
+	if err != nil {
+		return nil, fmt.Errorf("remote write %s: %w", client.Name(), err)
+	}
+
+	qm := &QueueManager{
+		cfg:          cfg.QueueConfig,
+		client:       client,
+		relabelRules: rules,
+	}
+	ready := make(chan struct{})
+	close(ready)
+	qm.shards, qm.shardCancel = qm.newShards(max(cfg.QueueConfig.MinShards, 1), ready)
+	return qm, nil
+}
+
+// Name identifies the endpoint of the queue
+func (qm *QueueManager) Name() string {
+	return qm.client.Name()
+}
+
+// SetExternalLabels sets labels added to every sample lacking a label of
+// the same name. It must be called before samples are appended.
+func (qm *QueueManager) SetExternalLabels(labels map[string]string) {
+	qm.externalLabels = labels
+}
+
+// Append relabels a sample and queues it for sending. The sample is dropped
+// if relabeling drops it or its shard is full.
+func (qm *QueueManager) Append(m *metrics.Metric) {
+	labels := make(map[string]string, len(m.Labels)+len(qm.externalLabels)+1)
+	for k, v := range qm.externalLabels {
+		labels[k] = v
+	}
+	for k, v := range m.Labels {
+		labels[k] = v
+	}
+	labels[metrics.MetricNameLabel] = m.Name
+
+	labels = relabel.Process(labels, qm.relabelRules)
+	if labels == nil {
+		return
+	}
+	s := sample{labels: labels, value: m.Value, timestamp: m.Timestamp.UnixMilli()}
+
+	qm.mu.RLock()
+	defer qm.mu.RUnlock()
+
+	if qm.stopped {
+		qm.addStats(func(st *Stats) { st.SamplesDropped++ })
+		return
+	}
+	qm.addStats(func(st *Stats) { st.SamplesIn++ })
+	sh := qm.shards[seriesHash(labels)%uint64(len(qm.shards))]
+	select {
+	case sh.queue <- s:
+	default:
+		qm.addStats(func(st *Stats) { st.SamplesDropped++ })
+	}
+}
+
+// Run recalculates the number of shards every reshard interval until ctx is
+// cancelled, then sends the queued samples and stops
+func (qm *QueueManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(reshardInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			qm.stop()
+			return
+		case <-ticker.C:
+			qm.updateShards(reshardInterval)
+		}
+	}
+}
+
+// stop drops further appends and flushes the shards
+func (qm *QueueManager) stop() {
+	qm.mu.Lock()
+	shards, cancel := qm.shards, qm.shardCancel
+	qm.stopped = true
+	qm.mu.Unlock()
+	qm.stopShards(shards, cancel)
+}
+
+// Stats returns the queue counters
+func (qm *QueueManager) Stats() Stats {
+	qm.mu.RLock()
+	shards := len(qm.shards)
+	pending := 0
+	for _, sh := range qm.shards {
+		pending += len(sh.queue)
+	}
+	qm.mu.RUnlock()
+
+	qm.statsMu.Lock()
+	defer qm.statsMu.Unlock()
+
+	st := qm.stats
+	st.Shards = shards
+	st.Pending = pending
+	return st
+}
+
+func (qm *QueueManager) addStats(f func(st *Stats)) {
+	qm.statsMu.Lock()
+	f(&qm.stats)
+	qm.statsMu.Unlock()
+}
+
+// updateShards reshards if the desired number of shards changed. Resharding
+// is skipped while sends are failing, as more shards would not help.
+func (qm *QueueManager) updateShards(interval time.Duration) {
+	qm.statsMu.Lock()
+	samplesIn := qm.stats.SamplesIn - qm.lastSamplesIn
+	samplesSent := qm.stats.SamplesSent - qm.lastSamplesSent
+	sendDuration := qm.sendDuration - qm.lastSendDuration
+	qm.lastSamplesIn = qm.stats.SamplesIn
+	qm.lastSamplesSent = qm.stats.SamplesSent
+	qm.lastSendDuration = qm.sendDuration
+	failing := time.Since(qm.lastSendFailure) < interval
+	qm.statsMu.Unlock()
+
+	if failing || samplesSent == 0 {
+		return
+	}
+
+	st := qm.Stats()
+	timePerSample := sendDuration.Seconds() / float64(samplesSent)
+	inRate := float64(samplesIn) / interval.Seconds()
+	backlogRate := float64(st.Pending) / interval.Seconds()
+
+	desired := desiredShards(st.Shards, qm.cfg.MinShards, qm.cfg.MaxShards, timePerSample, inRate, backlogRate)
+	if desired == st.Shards {
+		return
+	}
+
+	slog.Info("Resharding remote write", "remote_name", qm.Name(), "from", st.Shards, "to", desired)
+	qm.reshard(desired)
+}
+
+// reshard replaces the shards with n new ones. Appends are queued on the new
+// shards right away instead of waiting for a slow endpoint, but the new
+// shards only start sending once the old ones are flushed, so no sample of a
+// series overtakes an earlier one.
+func (qm *QueueManager) reshard(n int) {
+	ready := make(chan struct{})
+	shards, cancel := qm.newShards(n, ready)
+
+	qm.mu.Lock()
+	oldShards, oldCancel := qm.shards, qm.shardCancel
+	qm.shards, qm.shardCancel = shards, cancel
+	qm.mu.Unlock()
+
+	qm.stopShards(oldShards, oldCancel)
+	close(ready)
+}
+
+// desiredShards returns the number of shards needed to send samples arriving
+// at inRate and to work off the backlog within one interval, given the time
+// it takes to send one sample. Changes within the tolerance are ignored.
+func desiredShards(current, minShards, maxShards int, timePerSample, inRate, backlogRate float64) int {
+	desired := timePerSample * (inRate + backlogRate)
+
+	lower := float64(current) * (1 - shardTolerance)
+	upper := float64(current) * (1 + shardTolerance)
+	if desired >= lower && desired <= upper {
+		return current
+	}
+
+	shards := int(math.Ceil(desired))
+	return min(max(shards, minShards, 1), maxShards)
+}
+
+// newShards starts n shards, which send once ready is closed, and returns
+// them with the function cancelling their sends
+func (qm *QueueManager) newShards(n int, ready <-chan struct{}) ([]*shard, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	shards := make([]*shard, n)
+	for i := range shards {
+		sh := &shard{
+			queue: make(chan sample, qm.cfg.Capacity),
+			done:  make(chan struct{}),
+		}
+		shards[i] = sh
+		go qm.runShard(ctx, sh, ready)
+	}
+	return shards, cancel
+}
+
+// stopShards closes the shard queues and waits for queued samples to be
+// sent. Sending is cancelled after the flush deadline. The shards must no
+// longer be reachable by Append.
+func (qm *QueueManager) stopShards(shards []*shard, cancel context.CancelFunc) {
+	for _, sh := range shards {
+		close(sh.queue)
+	}
+
+	deadline := time.NewTimer(flushDeadline)
+	defer deadline.Stop()
+	for _, sh := range shards {
+		select {
+		case <-sh.done:
+		case <-deadline.C:
+			cancel()
+			<-sh.done
+		}
+	}
+	cancel()
+}
+
+// runShard waits until ready is closed, then batches the samples of a shard
+// and sends a batch when it is full or the batch send deadline has passed
+func (qm *QueueManager) runShard(ctx context.Context, sh *shard, ready <-chan struct{}) {
+	defer close(sh.done)
+
+	select {
+	case <-ready:
+	case <-ctx.Done():
+	}
+
+	batch := make([]sample, 0, qm.cfg.MaxSamplesPerSend)
+	timer := time.NewTimer(qm.cfg.BatchSendDeadline)
+	defer timer.Stop()
+
+	for {
+		select {
+		case s, ok := <-sh.queue:
+			if !ok {
+				if len(batch) > 0 {
+					qm.sendSamples(ctx, batch)
+				}
+				return
+			}
+			batch = append(batch, s)
+			if len(batch) >= qm.cfg.MaxSamplesPerSend {
+				qm.sendSamples(ctx, batch)
+				batch = batch[:0]
+				timer.Reset(qm.cfg.BatchSendDeadline)
+			}
+		case <-timer.C:
+			if len(batch) > 0 {
+				qm.sendSamples(ctx, batch)
+				batch = batch[:0]
+			}
+			timer.Reset(qm.cfg.BatchSendDeadline)
+		}
+	}
+}
+
+// sendSamples sends a batch, retrying recoverable errors with exponential
+// backoff until it succeeds or ctx is cancelled
+func (qm *QueueManager) sendSamples(ctx context.Context, samples []sample) {
+	n := int64(len(samples))
+	data, err := buildWriteRequest(samples)
+	if err != nil {
+		slog.Error("Error encoding samples for remote write", "remote_name", qm.Name(), "err", err)
+		qm.addStats(func(st *Stats) { st.SamplesFailed += n })
+		return
+	}
+
+	backoff := qm.cfg.MinBackoff
+	for {
+		start := time.Now()
+		err := qm.client.Store(ctx, data)
+		if err == nil {
+			qm.statsMu.Lock()
+			qm.stats.SamplesSent += n
+			qm.sendDuration += time.Since(start)
+			qm.statsMu.Unlock()
+			return
+		}
+
+		if ctx.Err() != nil {
+			qm.addStats(func(st *Stats) { st.SamplesDropped += n })
+			return
+		}
+		var recoverable RecoverableError
+		if !errors.As(err, &recoverable) {
+			slog.Error("Error sending samples to remote write", "remote_name", qm.Name(), "count", n, "err", err)
+			qm.addStats(func(st *Stats) { st.SamplesFailed += n })
+			return
+		}
+
+		qm.statsMu.Lock()
+		qm.stats.SamplesRetried += n
+		qm.lastSendFailure = time.Now()
+		qm.statsMu.Unlock()
+
+		if !sleep(ctx, backoff) {
+			qm.addStats(func(st *Stats) { st.SamplesDropped += n })
+			return
+		}
+		backoff = min(backoff*2, qm.cfg.MaxBackoff)
+	}
+}
+
+// buildWriteRequest groups samples by series into a snappy-compressed
+// WriteRequest
+func buildWriteRequest(samples []sample) ([]byte, error) {
+	req := &pb.WriteRequest{}
+	series := make(map[uint64]*pb.TimeSeries)
+	for _, s := range samples {
+		key := seriesHash(s.labels)
+		ts, ok := series[key]
+		if !ok {
+			ts = &pb.TimeSeries{Labels: labelsToProto(s.labels)}
+			series[key] = ts
+			req.Timeseries = append(req.Timeseries, ts)
+		}
+		ts.Samples = append(ts.Samples, &pb.Sample{Value: s.value, Timestamp: s.timestamp})
+	}
+
+	data, err := proto.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	return snappy.Encode(nil, data), nil
+}
+
+// labelsToProto converts a label set to protobuf labels sorted by name
+func labelsToProto(labels map[string]string) []*pb.Label {
+	res := make([]*pb.Label, 0, len(labels))
+	for k, v := range labels {
+		res = append(res, &pb.Label{Name: k, Value: v})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// seriesHash returns a hash identifying a label set
+func seriesHash(labels map[string]string) uint64 {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	h := fnv.New64a()
+	for _, k := range names {
+		h.Write([]byte(k))
+		h.Write([]byte{0xff})
+		h.Write([]byte(labels[k]))
+		h.Write([]byte{0xff})
+	}
+	return h.Sum64()
+}
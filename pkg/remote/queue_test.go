@@ -0,0 +1,386 @@
+package remote
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// fakeReceiver is a remote write endpoint recording the series it receives.
+// The status function decides the response code of each request.
+type fakeReceiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests int
+	samples  map[string][]*pb.Sample
+	headers  http.Header
+	status   func(request int) int
+}
+
+func newFakeReceiver(t *testing.T, status func(request int) int) *fakeReceiver {
+	rcv := &fakeReceiver{samples: make(map[string][]*pb.Sample), status: status}
+	rcv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compressed, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var req pb.WriteRequest
+		if err := proto.Unmarshal(data, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rcv.mu.Lock()
+		defer rcv.mu.Unlock()
+
+		rcv.requests++
+		rcv.headers = r.Header.Clone()
+		if code := rcv.status(rcv.requests); code != http.StatusOK {
+			http.Error(w, "rejected", code)
+			return
+		}
+		for _, ts := range req.Timeseries {
+			key := ""
+			for _, l := range ts.Labels {
+				key += l.Name + "=" + l.Value + ","
+			}
+			rcv.samples[key] = append(rcv.samples[key], ts.Samples...)
+		}
+	}))
+	t.Cleanup(rcv.Close)
+	return rcv
+}
+
+func (rcv *fakeReceiver) received(key string) []*pb.Sample {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+
+	return rcv.samples[key]
+}
+
+func (rcv *fakeReceiver) total() int {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+
+	n := 0
+	for _, samples := range rcv.samples {
+		n += len(samples)
+	}
+	return n
+}
+
+func alwaysOK(int) int { return http.StatusOK }
+
+// newTestQueue creates a queue with small batches and short deadlines
+func newTestQueue(t *testing.T, url string, relabelConfigs ...config.RelabelConfig) *QueueManager {
+	t.Helper()
+	qm, err := NewQueueManager(config.RemoteWriteConfig{
+		URL:                 url,
+		RemoteTimeout:       time.Second,
+		WriteRelabelConfigs: relabelConfigs,
+		QueueConfig: config.QueueConfig{
+			Capacity:          1000,
+			MinShards:         1,
+			MaxShards:         4,
+			MaxSamplesPerSend: 10,
+			BatchSendDeadline: 10 * time.Millisecond,
+			MinBackoff:        time.Millisecond,
+			MaxBackoff:        10 * time.Millisecond,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return qm
+}
+
+// appendSamples appends n samples of a series one second apart
+func appendSamples(qm *QueueManager, name, job string, n int) {
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < n; i++ {
+		qm.Append(&metrics.Metric{
+			Name:      name,
+			Value:     float64(i),
+			Labels:    map[string]string{"job": job},
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+		})
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestQueueManager(t *testing.T) {
+	t.Run("Sends relabelled samples in order", func(t *testing.T) {
+		rcv := newFakeReceiver(t, alwaysOK)
+		qm := newTestQueue(t, rcv.URL,
+			config.RelabelConfig{SourceLabels: []string{"__name__"}, Separator: ";", Regex: "go_.*", Action: "drop"},
+			config.RelabelConfig{Separator: ";", Regex: "(.*)", TargetLabel: "cluster", Replacement: "eu-1", Action: "replace"},
+		)
+
+		appendSamples(qm, "http_requests_total", "api", 25)
+		appendSamples(qm, "go_goroutines", "api", 5)
+		appendSamples(qm, "up", "web", 3)
+
+		key := "__name__=http_requests_total,cluster=eu-1,job=api,"
+		waitFor(t, func() bool { return len(rcv.received(key)) == 25 && rcv.total() == 28 })
+
+		for i, s := range rcv.received(key) {
+			if s.Value != float64(i) || s.Timestamp != int64(1700000000+i)*1000 {
+				t.Fatalf("Sample %d out of order: %v", i, s)
+			}
+		}
+
+		if enc := rcv.headers.Get("Content-Encoding"); enc != "snappy" {
+			t.Errorf("Expected snappy encoding, got %q", enc)
+		}
+		if v := rcv.headers.Get("X-Prometheus-Remote-Write-Version"); v != "0.1.0" {
+			t.Errorf("Expected remote write version 0.1.0, got %q", v)
+		}
+
+		if st := qm.Stats(); st.SamplesIn != 28 || st.SamplesSent != 28 {
+			t.Errorf("Expected 28 samples in and sent, got %+v", st)
+		}
+	})
+
+	t.Run("Adds external labels before relabeling", func(t *testing.T) {
+		rcv := newFakeReceiver(t, alwaysOK)
+		qm := newTestQueue(t, rcv.URL,
+			config.RelabelConfig{SourceLabels: []string{"cluster"}, Separator: ";", Regex: "(.*)", TargetLabel: "origin", Replacement: "$1", Action: "replace"},
+		)
+		qm.SetExternalLabels(map[string]string{"cluster": "eu-1", "job": "ignored"})
+
+		appendSamples(qm, "up", "api", 3)
+
+		// The sample's own job label wins over the external label
+		key := "__name__=up,cluster=eu-1,job=api,origin=eu-1,"
+		waitFor(t, func() bool { return len(rcv.received(key)) == 3 })
+	})
+
+	t.Run("Retries recoverable errors", func(t *testing.T) {
+		rcv := newFakeReceiver(t, func(request int) int {
+			if request <= 2 {
+				return http.StatusServiceUnavailable
+			}
+			return http.StatusOK
+		})
+		qm := newTestQueue(t, rcv.URL)
+
+		appendSamples(qm, "up", "api", 5)
+		waitFor(t, func() bool { return rcv.total() == 5 })
+
+		st := qm.Stats()
+		if st.SamplesRetried == 0 || st.SamplesSent != 5 || st.SamplesFailed != 0 {
+			t.Errorf("Expected retried samples to be sent, got %+v", st)
+		}
+	})
+
+	t.Run("Does not retry client errors", func(t *testing.T) {
+		rcv := newFakeReceiver(t, func(int) int { return http.StatusBadRequest })
+		qm := newTestQueue(t, rcv.URL)
+
+		appendSamples(qm, "up", "api", 5)
+		waitFor(t, func() bool { return qm.Stats().SamplesFailed == 5 })
+
+		rcv.mu.Lock()
+		requests := rcv.requests
+		rcv.mu.Unlock()
+		if requests != 1 {
+			t.Errorf("Expected 1 request, got %d", requests)
+		}
+	})
+
+	t.Run("Reshards", func(t *testing.T) {
+		rcv := newFakeReceiver(t, alwaysOK)
+		qm := newTestQueue(t, rcv.URL)
+
+		// 10ms per sample at 1000 samples/s needs more than the maximum of
+		// 4 shards
+		qm.statsMu.Lock()
+		qm.stats.SamplesIn = 1000
+		qm.stats.SamplesSent = 100
+		qm.sendDuration = time.Second
+		qm.statsMu.Unlock()
+
+		qm.updateShards(time.Second)
+		if shards := qm.Stats().Shards; shards != 4 {
+			t.Fatalf("Expected 4 shards, got %d", shards)
+		}
+
+		appendSamples(qm, "up", "api", 20)
+		appendSamples(qm, "up", "web", 20)
+		waitFor(t, func() bool { return rcv.total() == 40 })
+	})
+
+	t.Run("Keeps series order while old shards drain", func(t *testing.T) {
+		var requests atomic.Int32
+		entered := make(chan struct{})
+		release := make(chan struct{})
+		unblock := sync.OnceFunc(func() { close(release) })
+		rcv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				close(entered)
+				select {
+				case <-release:
+				case <-r.Context().Done():
+				}
+			}
+		}))
+		defer rcv.Close()
+		defer unblock()
+		qm := newTestQueue(t, rcv.URL)
+
+		// Block the only shard in a send to the endpoint
+		appendSamples(qm, "up", "api", 1)
+		<-entered
+
+		resharded := make(chan struct{})
+		go func() {
+			qm.reshard(4)
+			close(resharded)
+		}()
+		waitFor(t, func() bool { return qm.Stats().Shards == 4 })
+
+		appended := make(chan struct{})
+		go func() {
+			appendSamples(qm, "up", "api", 1)
+			close(appended)
+		}()
+		select {
+		case <-appended:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected Append not to block while old shards drain")
+		}
+
+		// Many batch send deadlines pass without the new shards sending
+		time.Sleep(100 * time.Millisecond)
+		if n := requests.Load(); n != 1 {
+			t.Fatalf("Expected new shards to wait for the old shard, got %d requests", n)
+		}
+
+		unblock()
+		<-resharded
+		waitFor(t, func() bool { return qm.Stats().SamplesSent == 2 })
+		if n := requests.Load(); n != 2 {
+			t.Errorf("Expected 2 requests, got %d", n)
+		}
+	})
+
+	t.Run("Flushes on stop", func(t *testing.T) {
+		rcv := newFakeReceiver(t, alwaysOK)
+		qm, err := NewQueueManager(config.RemoteWriteConfig{
+			URL:           rcv.URL,
+			RemoteTimeout: time.Second,
+			QueueConfig: config.QueueConfig{
+				Capacity:          100,
+				MinShards:         1,
+				MaxShards:         1,
+				MaxSamplesPerSend: 100,
+				BatchSendDeadline: time.Hour,
+				MinBackoff:        time.Millisecond,
+				MaxBackoff:        time.Millisecond,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			qm.Run(ctx)
+			close(done)
+		}()
+
+		appendSamples(qm, "up", "api", 5)
+		cancel()
+		<-done
+
+		if n := rcv.total(); n != 5 {
+			t.Errorf("Expected queued samples to be flushed, got %d", n)
+		}
+
+		appendSamples(qm, "up", "api", 1)
+		if st := qm.Stats(); st.SamplesDropped != 1 {
+			t.Errorf("Expected sample after stop to be dropped, got %+v", st)
+		}
+	})
+}
+
+func TestDesiredShards(t *testing.T) {
+	tests := []struct {
+		name                       string
+		current                    int
+		timePerSample, in, backlog float64
+		expected                   int
+	}{
+		{"Within tolerance", 4, 0.001, 4000, 0, 4},
+		{"Scale up", 1, 0.001, 5000, 0, 5},
+		{"Scale up for backlog", 1, 0.001, 1000, 2000, 3},
+		{"Scale down", 10, 0.001, 2000, 0, 2},
+		{"Capped at max", 1, 0.01, 10000, 0, 50},
+		{"At least min", 10, 0.0001, 10, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := desiredShards(tt.current, 1, 50, tt.timePerSample, tt.in, tt.backlog); got != tt.expected {
+				t.Errorf("Expected %d shards, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewQueueManager(t *testing.T) {
+	if _, err := NewQueueManager(config.RemoteWriteConfig{URL: "localhost:9201"}); err == nil {
+		t.Error("Expected error for url without scheme")
+	}
+	if _, err := NewQueueManager(config.RemoteWriteConfig{
+		URL:                 "http://localhost:9201/write",
+		WriteRelabelConfigs: []config.RelabelConfig{{Action: "unknown"}},
+	}); err == nil {
+		t.Error("Expected error for invalid relabel config")
+	}
+}
+
+func TestNewWriteStorage(t *testing.T) {
+	t.Run("Stops created queues on error", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+		_, err := NewWriteStorage([]config.RemoteWriteConfig{
+			{URL: "http://localhost:9201/write", QueueConfig: config.QueueConfig{Capacity: 10, MinShards: 4, MaxShards: 4}},
+			{URL: "localhost:9201"},
+		}, nil)
+		if err == nil {
+			t.Fatal("Expected error for url without scheme")
+		}
+		waitFor(t, func() bool { return runtime.NumGoroutine() <= before })
+	})
+}
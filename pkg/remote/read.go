@@ -0,0 +1,282 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+const (
+	// maxReadRequestSize limits the compressed size of a read request
+	maxReadRequestSize = 32 << 20
+
+	// maxFrameSize limits the size of a frame in a streamed read response
+	maxFrameSize = 50 << 20
+
+	streamedContentType = "application/x-streamed-protobuf; proto=prometheus.ChunkedReadResponse"
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// readHandler serves remote read requests from a registry
+type readHandler struct {
+	registry *metrics.MetricRegistry
+}
+
+// NewReadHandler returns an HTTP handler serving remote read requests. Series
+// are returned as streamed XOR chunks if the client accepts them, otherwise
+// as a single response of raw samples.
+func NewReadHandler(registry *metrics.MetricRegistry) http.Handler {
+	return &readHandler{registry: registry}
+}
+
+func (h *readHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if enc := r.Header.Get("Content-Encoding"); enc != "" && enc != "snappy" {
+		http.Error(w, fmt.Sprintf("unsupported content encoding %q", enc), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	compressed, err := io.ReadAll(io.LimitReader(r.Body, maxReadRequestSize+1))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request body: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if len(compressed) > maxReadRequestSize {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode snappy body: %v", err), http.StatusBadRequest)
+		return
+	}
+	var req pb.ReadRequest
+	if err := proto.Unmarshal(data, &req); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode read request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	results := make([][]*pb.TimeSeries, len(req.Queries))
+	for i, q := range req.Queries {
+		matchers, err := protoToMatchers(q.Matchers)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		series := h.registry.Select(r.Context(), matchers, time.UnixMilli(q.StartTimestampMs), time.UnixMilli(q.EndTimestampMs))
+		results[i] = seriesToProto(series)
+	}
+
+	if negotiateResponseType(req.AcceptedResponseTypes) == pb.ReadRequest_STREAMED_XOR_CHUNKS {
+		h.writeChunked(w, results)
+		return
+	}
+	h.writeSamples(w, results)
+}
+
+// writeSamples sends all results as one snappy-compressed ReadResponse
+func (h *readHandler) writeSamples(w http.ResponseWriter, results [][]*pb.TimeSeries) {
+	resp := &pb.ReadResponse{Results: make([]*pb.QueryResult, len(results))}
+	for i, series := range results {
+		resp.Results[i] = &pb.QueryResult{Timeseries: series}
+	}
+
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to encode read response: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	w.Write(snappy.Encode(nil, data))
+}
+
+// writeChunked streams one ChunkedReadResponse frame per series so the
+// client can decode series while later ones are still being sent
+func (h *readHandler) writeChunked(w http.ResponseWriter, results [][]*pb.TimeSeries) {
+	w.Header().Set("Content-Type", streamedContentType)
+	flusher, _ := w.(http.Flusher)
+
+	for i, series := range results {
+		for _, ts := range series {
+			data, err := proto.Marshal(&pb.ChunkedReadResponse{
+				ChunkedSeries: []*pb.ChunkedSeries{{Labels: ts.Labels, Chunks: encodeChunks(ts.Samples)}},
+				QueryIndex:    int64(i),
+			})
+			if err != nil {
+				slog.Error("Error encoding chunked read response", "err", err)
+				return
+			}
+			if err := writeFrame(w, data); err != nil {
+				// The client went away, there is nobody left to report to
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// negotiateResponseType returns the first accepted response type that is
+// supported. Clients that do not list any types get samples.
+func negotiateResponseType(accepted []pb.ReadRequest_ResponseType) pb.ReadRequest_ResponseType {
+	for _, t := range accepted {
+		switch t {
+		case pb.ReadRequest_STREAMED_XOR_CHUNKS, pb.ReadRequest_SAMPLES:
+			return t
+		}
+	}
+	return pb.ReadRequest_SAMPLES
+}
+
+// encodeChunks packs samples into XOR chunks of up to maxSamplesPerChunk
+// samples each
+func encodeChunks(samples []*pb.Sample) []*pb.Chunk {
+	var chunks []*pb.Chunk
+	for len(samples) > 0 {
+		n := min(len(samples), maxSamplesPerChunk)
+		c := newXORChunk()
+		for _, s := range samples[:n] {
+			c.Append(s.Timestamp, s.Value)
+		}
+		chunks = append(chunks, &pb.Chunk{
+			MinTimeMs: samples[0].Timestamp,
+			MaxTimeMs: samples[n-1].Timestamp,
+			Type:      pb.Chunk_XOR,
+			Data:      c.Bytes(),
+		})
+		samples = samples[n:]
+	}
+	return chunks
+}
+
+// writeFrame writes a message prefixed with its uvarint size and the CRC32
+// Castagnoli checksum of its content
+func writeFrame(w io.Writer, msg []byte) error {
+	header := make([]byte, binary.MaxVarintLen64+4)
+	n := binary.PutUvarint(header, uint64(len(msg)))
+	binary.BigEndian.PutUint32(header[n:], crc32.Checksum(msg, castagnoliTable))
+
+	if _, err := w.Write(header[:n+4]); err != nil {
+		return err
+	}
+	_, err := w.Write(msg)
+	return err
+}
+
+// readFrame reads a message written by writeFrame, returning io.EOF at the
+// end of the stream
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxFrameSize {
+		return nil, fmt.Errorf("frame of %d bytes exceeds limit of %d bytes", size, maxFrameSize)
+	}
+
+	var crc [4]byte
+	if _, err := io.ReadFull(r, crc[:]); err != nil {
+		return nil, fmt.Errorf("failed to read frame checksum: %w", io.ErrUnexpectedEOF)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, fmt.Errorf("failed to read frame: %w", io.ErrUnexpectedEOF)
+	}
+	if crc32.Checksum(msg, castagnoliTable) != binary.BigEndian.Uint32(crc[:]) {
+		return nil, fmt.Errorf("frame checksum mismatch")
+	}
+	return msg, nil
+}
+
+// protoToMatchers converts protobuf label matchers into registry matchers
+func protoToMatchers(pm []*pb.LabelMatcher) ([]*metrics.Matcher, error) {
+	matchers := make([]*metrics.Matcher, 0, len(pm))
+	for _, m := range pm {
+		var t metrics.MatchType
+		switch m.Type {
+		case pb.LabelMatcher_EQ:
+			t = metrics.MatchEqual
+		case pb.LabelMatcher_NEQ:
+			t = metrics.MatchNotEqual
+		case pb.LabelMatcher_RE:
+			t = metrics.MatchRegexp
+		case pb.LabelMatcher_NRE:
+			t = metrics.MatchNotRegexp
+		default:
+			return nil, fmt.Errorf("invalid matcher type %v", m.Type)
+		}
+		matcher, err := metrics.NewMatcher(t, m.Name, m.Value)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+// matchersToProto converts registry matchers into protobuf label matchers
+func matchersToProto(matchers []*metrics.Matcher) []*pb.LabelMatcher {
+	res := make([]*pb.LabelMatcher, 0, len(matchers))
+	for _, m := range matchers {
+		var t pb.LabelMatcher_Type
+		switch m.Type {
+		case metrics.MatchEqual:
+			t = pb.LabelMatcher_EQ
+		case metrics.MatchNotEqual:
+			t = pb.LabelMatcher_NEQ
+		case metrics.MatchRegexp:
+			t = pb.LabelMatcher_RE
+		case metrics.MatchNotRegexp:
+			t = pb.LabelMatcher_NRE
+		}
+		res = append(res, &pb.LabelMatcher{Type: t, Name: m.Name, Value: m.Value})
+	}
+	return res
+}
+
+// seriesToProto converts registry series into protobuf series ordered by
+// their labels
+func seriesToProto(series []*metrics.Series) []*pb.TimeSeries {
+	res := make([]*pb.TimeSeries, 0, len(series))
+	for _, s := range series {
+		labels := make(map[string]string, len(s.Labels)+1)
+		for k, v := range s.Labels {
+			labels[k] = v
+		}
+		labels[metrics.MetricNameLabel] = s.Name
+
+		ts := &pb.TimeSeries{Labels: labelsToProto(labels), Samples: make([]*pb.Sample, len(s.Samples))}
+		for i, smp := range s.Samples {
+			ts.Samples[i] = &pb.Sample{Value: smp.Value, Timestamp: smp.Timestamp.UnixMilli()}
+		}
+		res = append(res, ts)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return labelsString(res[i].Labels) < labelsString(res[j].Labels) })
+	return res
+}
+
+// labelsString returns a sortable key for sorted protobuf labels
+func labelsString(labels []*pb.Label) string {
+	key := ""
+	for _, l := range labels {
+		key += l.Name + "\xff" + l.Value + "\xff"
+	}
+	return key
+}
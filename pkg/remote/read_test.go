@@ -0,0 +1,292 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// newReadRegistry returns a registry with n samples of two series one
+// second apart
+func newReadRegistry(start time.Time, n int) *metrics.MetricRegistry {
+	registry := metrics.NewMetricRegistry()
+	for i := 0; i < n; i++ {
+		for _, job := range []string{"api", "web"} {
+			registry.Register(&metrics.Metric{
+				Name:      "http_requests_total",
+				Value:     float64(i),
+				Labels:    map[string]string{"job": job},
+				Timestamp: start.Add(time.Duration(i) * time.Second),
+			})
+		}
+	}
+	return registry
+}
+
+func newReadClient(t *testing.T, url string) *Client {
+	t.Helper()
+	c, err := NewReadClient(config.RemoteReadConfig{URL: url, RemoteTimeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestReadHandler(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	registry := newReadRegistry(start, 300)
+	server := httptest.NewServer(NewReadHandler(registry))
+	defer server.Close()
+
+	query := &pb.Query{
+		StartTimestampMs: start.Add(10 * time.Second).UnixMilli(),
+		EndTimestampMs:   start.Add(259 * time.Second).UnixMilli(),
+		Matchers: []*pb.LabelMatcher{
+			{Type: pb.LabelMatcher_EQ, Name: "__name__", Value: "http_requests_total"},
+			{Type: pb.LabelMatcher_RE, Name: "job", Value: "a.*"},
+		},
+	}
+
+	checkSeries := func(t *testing.T, series []*pb.TimeSeries) {
+		t.Helper()
+		if len(series) != 1 {
+			t.Fatalf("Expected 1 series, got %d", len(series))
+		}
+		if labels := labelsString(series[0].Labels); labels != labelsString(labelsToProto(map[string]string{"__name__": "http_requests_total", "job": "api"})) {
+			t.Errorf("Unexpected labels %v", series[0].Labels)
+		}
+		samples := series[0].Samples
+		if len(samples) != 250 {
+			t.Fatalf("Expected 250 samples, got %d", len(samples))
+		}
+		for i, s := range samples {
+			if s.Value != float64(i+10) || s.Timestamp != start.Add(time.Duration(i+10)*time.Second).UnixMilli() {
+				t.Fatalf("Sample %d: unexpected %v", i, s)
+			}
+		}
+	}
+
+	t.Run("Streams chunked series", func(t *testing.T) {
+		series, err := newReadClient(t, server.URL).Read(context.Background(), query)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		checkSeries(t, series)
+	})
+
+	t.Run("Returns samples", func(t *testing.T) {
+		data, err := proto.Marshal(&pb.ReadRequest{Queries: []*pb.Query{query}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.Post(server.URL, "application/x-protobuf", bytes.NewReader(snappy.Encode(nil, data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if ct := resp.Header.Get("Content-Type"); ct != "application/x-protobuf" {
+			t.Errorf("Expected content type application/x-protobuf, got %q", ct)
+		}
+		series, err := readSampledResponse(resp.Body)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		checkSeries(t, series)
+	})
+
+	t.Run("Chunks of at most 120 samples", func(t *testing.T) {
+		samples := make([]*pb.Sample, 250)
+		for i := range samples {
+			samples[i] = &pb.Sample{Value: float64(i), Timestamp: int64(i) * 1000}
+		}
+		chunks := encodeChunks(samples)
+		if len(chunks) != 3 {
+			t.Fatalf("Expected 3 chunks, got %d", len(chunks))
+		}
+		if chunks[1].MinTimeMs != 120000 || chunks[1].MaxTimeMs != 239000 {
+			t.Errorf("Unexpected time range of second chunk: %d-%d", chunks[1].MinTimeMs, chunks[1].MaxTimeMs)
+		}
+	})
+
+	t.Run("Rejects bad requests", func(t *testing.T) {
+		badMatcher, err := proto.Marshal(&pb.ReadRequest{Queries: []*pb.Query{{
+			Matchers: []*pb.LabelMatcher{{Type: pb.LabelMatcher_RE, Name: "job", Value: "("}},
+		}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for name, body := range map[string][]byte{
+			"Not snappy":   []byte("not snappy"),
+			"Bad matcher":  snappy.Encode(nil, badMatcher),
+			"Not protobuf": snappy.Encode(nil, []byte{0xff, 0xff, 0xff}),
+		} {
+			t.Run(name, func(t *testing.T) {
+				resp, err := http.Post(server.URL, "application/x-protobuf", bytes.NewReader(body))
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusBadRequest {
+					t.Errorf("Expected status 400, got %d", resp.StatusCode)
+				}
+			})
+		}
+	})
+}
+
+func TestQueryable(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	matchers := func(t *testing.T, job string) []*metrics.Matcher {
+		t.Helper()
+		m, err := metrics.NewMatcher(metrics.MatchEqual, "job", job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []*metrics.Matcher{m}
+	}
+
+	// The remote holds two hours of history, the local registry only the
+	// last 10 minutes with a different value at the overlapping timestamp
+	remoteStart := now.Add(-2 * time.Hour)
+	remote := metrics.NewMetricRegistry()
+	remote.SetRetention(3 * time.Hour)
+	for ts := remoteStart; !ts.After(now.Add(-10 * time.Minute)); ts = ts.Add(10 * time.Minute) {
+		remote.Register(&metrics.Metric{Name: "up", Value: 1, Labels: map[string]string{"job": "api"}, Timestamp: ts})
+	}
+	server := httptest.NewServer(NewReadHandler(remote))
+	defer server.Close()
+
+	local := metrics.NewMetricRegistry()
+	for _, ts := range []time.Time{now.Add(-10 * time.Minute), now} {
+		local.Register(&metrics.Metric{Name: "up", Value: 2, Labels: map[string]string{"job": "api"}, Timestamp: ts})
+	}
+
+	newQueryable := func(t *testing.T, cfg config.RemoteReadConfig) *Queryable {
+		t.Helper()
+		cfg.RemoteTimeout = time.Second
+		q, err := NewQueryable(local, []config.RemoteReadConfig{cfg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		q.now = func() time.Time { return now }
+		return q
+	}
+
+	t.Run("Merges remote and local samples", func(t *testing.T) {
+		q := newQueryable(t, config.RemoteReadConfig{URL: server.URL})
+
+		series := q.Select(context.Background(), matchers(t, "api"), remoteStart, now)
+		if len(series) != 1 {
+			t.Fatalf("Expected 1 series, got %d", len(series))
+		}
+		samples := series[0].Samples
+		if len(samples) != 13 {
+			t.Fatalf("Expected 13 samples, got %d", len(samples))
+		}
+		if !samples[0].Timestamp.Equal(remoteStart) || samples[0].Value != 1 {
+			t.Errorf("Expected first sample from remote, got %v", samples[0])
+		}
+		if overlap := samples[11]; !overlap.Timestamp.Equal(now.Add(-10*time.Minute)) || overlap.Value != 2 {
+			t.Errorf("Expected local sample on equal timestamp, got %v", overlap)
+		}
+	})
+
+	t.Run("Skips ranges covered locally", func(t *testing.T) {
+		q := newQueryable(t, config.RemoteReadConfig{URL: server.URL})
+
+		series := q.Select(context.Background(), matchers(t, "api"), now.Add(-30*time.Minute), now)
+		if len(series) != 1 || len(series[0].Samples) != 2 {
+			t.Errorf("Expected only local samples, got %v", series)
+		}
+	})
+
+	t.Run("Reads recent ranges if configured", func(t *testing.T) {
+		q := newQueryable(t, config.RemoteReadConfig{URL: server.URL, ReadRecent: true})
+
+		series := q.Select(context.Background(), matchers(t, "api"), now.Add(-30*time.Minute), now)
+		if len(series) != 1 || len(series[0].Samples) != 4 {
+			t.Errorf("Expected remote and local samples, got %v", series)
+		}
+	})
+
+	t.Run("Requires matchers", func(t *testing.T) {
+		q := newQueryable(t, config.RemoteReadConfig{URL: server.URL, RequiredMatchers: map[string]string{"job": "web"}})
+
+		series := q.Select(context.Background(), matchers(t, "api"), remoteStart, now)
+		if len(series) != 1 || len(series[0].Samples) != 2 {
+			t.Errorf("Expected only local samples, got %v", series)
+		}
+	})
+
+	t.Run("Drops samples outside the range and empty series", func(t *testing.T) {
+		// Like a chunked response, the remote returns more than was asked for
+		fake := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			(&readHandler{}).writeSamples(w, [][]*pb.TimeSeries{{
+				{
+					Labels: []*pb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "batch"}},
+					Samples: []*pb.Sample{
+						{Timestamp: remoteStart.Add(-time.Hour).UnixMilli(), Value: 1},
+						{Timestamp: remoteStart.Add(time.Minute).UnixMilli(), Value: 2},
+						{Timestamp: now.Add(time.Hour).UnixMilli(), Value: 3},
+					},
+				},
+				{Labels: []*pb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "empty"}}},
+			}})
+		}))
+		defer fake.Close()
+		q := newQueryable(t, config.RemoteReadConfig{URL: fake.URL})
+
+		series := q.Select(context.Background(), matchers(t, "batch"), remoteStart, now)
+		if len(series) != 1 {
+			t.Fatalf("Expected 1 series, got %v", series)
+		}
+		if samples := series[0].Samples; len(samples) != 1 || samples[0].Value != 2 {
+			t.Errorf("Expected only the sample within the range, got %v", samples)
+		}
+	})
+
+	t.Run("Cancels reads with the query context", func(t *testing.T) {
+		var requests atomic.Int32
+		fake := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+		}))
+		defer fake.Close()
+		q := newQueryable(t, config.RemoteReadConfig{URL: fake.URL})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		series := q.Select(ctx, matchers(t, "api"), remoteStart, now)
+		if len(series) != 1 || len(series[0].Samples) != 2 {
+			t.Errorf("Expected only local samples, got %v", series)
+		}
+		if n := requests.Load(); n != 0 {
+			t.Errorf("Expected no request after the query was cancelled, got %d", n)
+		}
+	})
+
+	t.Run("Falls back to local data on errors", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}))
+		defer failing.Close()
+		q := newQueryable(t, config.RemoteReadConfig{URL: failing.URL})
+
+		series := q.Select(context.Background(), matchers(t, "api"), remoteStart, now)
+		if len(series) != 1 || len(series[0].Samples) != 2 {
+			t.Errorf("Expected only local samples, got %v", series)
+		}
+	})
+}
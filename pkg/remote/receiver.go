@@ -0,0 +1,135 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// maxWriteRequestSize limits the compressed size of a write request
+const maxWriteRequestSize = 32 << 20
+
+// writeHandler appends the samples of remote write requests to a registry
+type writeHandler struct {
+	registry *metrics.MetricRegistry
+}
+
+// NewWriteHandler returns an HTTP handler receiving remote write requests.
+// Malformed requests and out-of-order samples are rejected with 400 so the
+// sender does not retry them; other failures return 5xx.
+func NewWriteHandler(registry *metrics.MetricRegistry) http.Handler {
+	return &writeHandler{registry: registry}
+}
+
+func (h *writeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if enc := r.Header.Get("Content-Encoding"); enc != "" && enc != "snappy" {
+		http.Error(w, fmt.Sprintf("unsupported content encoding %q", enc), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	compressed, err := io.ReadAll(io.LimitReader(r.Body, maxWriteRequestSize+1))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request body: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if len(compressed) > maxWriteRequestSize {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode snappy body: %v", err), http.StatusBadRequest)
+		return
+	}
+	var req pb.WriteRequest
+	if err := proto.Unmarshal(data, &req); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode write request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.write(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// write validates every series of the request and appends their samples.
+// A request with an invalid series is rejected as a whole. Out-of-order
+// samples are skipped and reported after the remaining samples are stored.
+func (h *writeHandler) write(req *pb.WriteRequest) error {
+	type series struct {
+		name    string
+		labels  map[string]string
+		samples []*pb.Sample
+	}
+
+	all := make([]series, 0, len(req.Timeseries))
+	for _, ts := range req.Timeseries {
+		name, labels, err := protoToLabels(ts.Labels)
+		if err != nil {
+			return err
+		}
+		all = append(all, series{name: name, labels: labels, samples: ts.Samples})
+	}
+
+	outOfOrder := 0
+	for _, s := range all {
+		for _, smp := range s.samples {
+			err := h.registry.Append(&metrics.Metric{
+				Name:      s.name,
+				Type:      metrics.MetricTypeGauge,
+				Value:     smp.Value,
+				Labels:    s.labels,
+				Timestamp: time.UnixMilli(smp.Timestamp),
+			})
+			if errors.Is(err, metrics.ErrOutOfOrderSample) {
+				outOfOrder++
+			}
+		}
+	}
+
+	if outOfOrder > 0 {
+		return fmt.Errorf("%d out of order samples were dropped", outOfOrder)
+	}
+	return nil
+}
+
+// protoToLabels converts protobuf labels into a metric name and labels,
+// checking names are valid and unique
+func protoToLabels(pl []*pb.Label) (string, map[string]string, error) {
+	var name string
+	labels := make(map[string]string, len(pl))
+	seen := make(map[string]bool, len(pl))
+	for _, l := range pl {
+		if seen[l.Name] {
+			return "", nil, fmt.Errorf("duplicate label name %q", l.Name)
+		}
+		seen[l.Name] = true
+
+		if l.Name == metrics.MetricNameLabel {
+			name = l.Value
+			continue
+		}
+		if !metrics.IsValidLabelName(l.Name) {
+			return "", nil, fmt.Errorf("invalid label name %q", l.Name)
+		}
+		if l.Value != "" {
+			labels[l.Name] = l.Value
+		}
+	}
+
+	if !metrics.IsValidMetricName(name) {
+		return "", nil, fmt.Errorf("invalid or missing metric name %q", name)
+	}
+	return name, labels, nil
+}
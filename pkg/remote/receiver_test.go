@@ -0,0 +1,144 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// postWriteRequest sends a raw body to the write handler
+func postWriteRequest(t *testing.T, h http.Handler, body []byte, encoding string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/write", bytes.NewReader(body))
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// encodeWriteRequest marshals and compresses a write request
+func encodeWriteRequest(t *testing.T, req *pb.WriteRequest) []byte {
+	t.Helper()
+	data, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snappy.Encode(nil, data)
+}
+
+func testSeries(name string, samples ...*pb.Sample) *pb.TimeSeries {
+	return &pb.TimeSeries{
+		Labels:  []*pb.Label{{Name: "__name__", Value: name}, {Name: "job", Value: "edge"}},
+		Samples: samples,
+	}
+}
+
+func TestWriteHandler(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	t.Run("Appends samples", func(t *testing.T) {
+		registry := metrics.NewMetricRegistry()
+		h := NewWriteHandler(registry)
+
+		body := encodeWriteRequest(t, &pb.WriteRequest{Timeseries: []*pb.TimeSeries{
+			testSeries("edge_temperature",
+				&pb.Sample{Value: 20, Timestamp: start.UnixMilli()},
+				&pb.Sample{Value: 21, Timestamp: start.Add(time.Minute).UnixMilli()}),
+		}})
+
+		rec := postWriteRequest(t, h, body, "snappy")
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d: %s", rec.Code, rec.Body.String())
+		}
+
+		m, ok := registry.Get("edge_temperature", map[string]string{"job": "edge"})
+		if !ok || m.Value != 21 || !m.Timestamp.Equal(start.Add(time.Minute)) {
+			t.Fatalf("Expected latest sample 21 at %v, got %+v", start.Add(time.Minute), m)
+		}
+		if series := registry.Select(context.Background(), nil, start, start.Add(time.Hour)); len(series) != 1 || len(series[0].Samples) != 2 {
+			t.Errorf("Expected 2 samples in history, got %v", series)
+		}
+	})
+
+	t.Run("Rejects out of order samples", func(t *testing.T) {
+		registry := metrics.NewMetricRegistry()
+		h := NewWriteHandler(registry)
+
+		body := encodeWriteRequest(t, &pb.WriteRequest{Timeseries: []*pb.TimeSeries{
+			testSeries("edge_temperature",
+				&pb.Sample{Value: 21, Timestamp: start.Add(time.Minute).UnixMilli()},
+				&pb.Sample{Value: 20, Timestamp: start.UnixMilli()}),
+			testSeries("edge_humidity", &pb.Sample{Value: 50, Timestamp: start.UnixMilli()}),
+		}})
+
+		rec := postWriteRequest(t, h, body, "")
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status 400, got %d", rec.Code)
+		}
+		if _, ok := registry.Get("edge_humidity", map[string]string{"job": "edge"}); !ok {
+			t.Error("Expected in-order samples to be stored")
+		}
+	})
+
+	t.Run("Rejects bad payloads", func(t *testing.T) {
+		h := NewWriteHandler(metrics.NewMetricRegistry())
+
+		invalidName := encodeWriteRequest(t, &pb.WriteRequest{Timeseries: []*pb.TimeSeries{{
+			Labels:  []*pb.Label{{Name: "job", Value: "edge"}},
+			Samples: []*pb.Sample{{Value: 1, Timestamp: start.UnixMilli()}},
+		}}})
+		duplicateLabel := encodeWriteRequest(t, &pb.WriteRequest{Timeseries: []*pb.TimeSeries{{
+			Labels:  []*pb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "a"}, {Name: "job", Value: "b"}},
+			Samples: []*pb.Sample{{Value: 1, Timestamp: start.UnixMilli()}},
+		}}})
+
+		tests := []struct {
+			name     string
+			body     []byte
+			encoding string
+			expected int
+		}{
+			{"Not snappy", []byte("not snappy"), "snappy", http.StatusBadRequest},
+			{"Not protobuf", snappy.Encode(nil, []byte{0xff, 0xff, 0xff}), "snappy", http.StatusBadRequest},
+			{"Missing metric name", invalidName, "snappy", http.StatusBadRequest},
+			{"Duplicate label", duplicateLabel, "snappy", http.StatusBadRequest},
+			{"Unsupported encoding", invalidName, "gzip", http.StatusUnsupportedMediaType},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if rec := postWriteRequest(t, h, tt.body, tt.encoding); rec.Code != tt.expected {
+					t.Errorf("Expected status %d, got %d", tt.expected, rec.Code)
+				}
+			})
+		}
+	})
+
+	t.Run("Receives from queue manager", func(t *testing.T) {
+		registry := metrics.NewMetricRegistry()
+		server := httptest.NewServer(NewWriteHandler(registry))
+		defer server.Close()
+
+		qm := newTestQueue(t, server.URL)
+		appendSamples(qm, "http_requests_total", "api", 15)
+
+		waitFor(t, func() bool {
+			m, ok := registry.Get("http_requests_total", map[string]string{"job": "api"})
+			return ok && m.Value == 14
+		})
+		if st := qm.Stats(); st.SamplesFailed != 0 {
+			t.Errorf("Expected no failed samples, got %+v", st)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package remote
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// WriteStorage fans samples out to the queues of all remote write endpoints
+type WriteStorage struct {
+	queues []*QueueManager
+}
+
+// NewWriteStorage creates a queue for every remote write config. External
+// labels are added to every sent sample lacking them. If a config is invalid,
+// the queues already created are stopped.
+func NewWriteStorage(cfgs []config.RemoteWriteConfig, externalLabels map[string]string) (*WriteStorage, error) {
+	w := &WriteStorage{}
+	for _, cfg := range cfgs {
+		qm, err := NewQueueManager(cfg)
+		if err != nil {
+			for _, started := range w.queues {
+				started.stop()
+			}
+			return nil, err
+		}
+		qm.SetExternalLabels(externalLabels)
+		w.queues = append(w.queues, qm)
+	}
+	return w, nil
+}
+
+// Append queues a sample on every endpoint. It is used as a registry
+// listener.
+func (w *WriteStorage) Append(m *metrics.Metric) {
+	for _, qm := range w.queues {
+		qm.Append(m)
+	}
+}
+
+// Queues returns the queues of all endpoints
+func (w *WriteStorage) Queues() []*QueueManager {
+	return w.queues
+}
+
+// Run runs every queue until ctx is cancelled and their samples are flushed
+func (w *WriteStorage) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, qm := range w.queues {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			qm.Run(ctx)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,339 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+)
+
+const (
+	// AlertMetricName is the series recording the state of active alerts
+	AlertMetricName = "ALERTS"
+	// AlertForStateMetricName is the series recording when alerts became
+	// active
+	AlertForStateMetricName = "ALERTS_FOR_STATE"
+
+	// AlertNameLabel is the label holding the name of the alerting rule
+	AlertNameLabel = "alertname"
+	// AlertStateLabel is the label holding the state of an alert in ALERTS
+	AlertStateLabel = "alertstate"
+
+	// resolvedRetention is how long resolved alerts are kept so that their
+	// resolution can be sent to notifiers
+	resolvedRetention = 15 * time.Minute
+)
+
+// AlertState is the state of an alert
+type AlertState string
+
+const (
+	StateInactive AlertState = "inactive"
+	StatePending  AlertState = "pending"
+	StateFiring   AlertState = "firing"
+)
+
+// Alert is a single alert produced by an alerting rule for one label set
+type Alert struct {
+	State       AlertState
+	Labels      map[string]string
+	Annotations map[string]string
+	Value       float64
+
+	// ActiveAt is when the alert became pending. FiredAt and ResolvedAt are
+	// zero until the alert fires and resolves respectively.
+	ActiveAt   time.Time
+	FiredAt    time.Time
+	ResolvedAt time.Time
+
+	// KeepFiringSince is when the expression stopped returning the alert
+	// while keep_firing_for holds it in the firing state
+	KeepFiringSince time.Time
+
+	// LastSentAt is when the alert was last sent to notifiers. ValidUntil is
+	// when receivers should consider a firing alert resolved if it is not
+	// sent again.
+	LastSentAt time.Time
+	ValidUntil time.Time
+}
+
+// needsSending reports whether the alert must be sent to notifiers at ts.
+// Firing alerts are resent every resendDelay and resolved alerts are sent
+// as soon as they resolve.
+func (a *Alert) needsSending(ts time.Time, resendDelay time.Duration) bool {
+	if a.State == StatePending {
+		return false
+	}
+	if a.ResolvedAt.After(a.LastSentAt) {
+		return true
+	}
+	return a.LastSentAt.Add(resendDelay).Before(ts)
+}
+
+// NotifyFunc delivers alerts to notifiers
+type NotifyFunc func(alerts []*Alert)
+
+// AlertingRule generates alerts for every sample returned by its
+// expression. Alerts are pending until the expression has returned them for
+// the hold duration, and then fire.
+type AlertingRule struct {
+	evaluationState
+
+	name          string
+	query         string
+	expr          query.Expr
+	holdDuration  time.Duration
+	keepFiringFor time.Duration
+	labels        map[string]string
+	annotations   map[string]string
+
+	alertsMu sync.RWMutex
+	active   map[string]*Alert
+}
+
+// NewAlertingRule creates an alerting rule
+func NewAlertingRule(name, expr string, holdDuration, keepFiringFor time.Duration, labels, annotations map[string]string) (*AlertingRule, error) {
+	parsed, err := query.ParseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &AlertingRule{
+		name:          name,
+		query:         expr,
+		expr:          parsed,
+		holdDuration:  holdDuration,
+		keepFiringFor: keepFiringFor,
+		labels:        labels,
+		annotations:   annotations,
+		active:        make(map[string]*Alert),
+	}, nil
+}
+
+// Name returns the name of the alert
+func (r *AlertingRule) Name() string {
+	return r.name
+}
+
+// Query returns the expression of the rule
+func (r *AlertingRule) Query() string {
+	return r.query
+}
+
+// Labels returns the label templates added to alerts
+func (r *AlertingRule) Labels() map[string]string {
+	return r.labels
+}
+
+// Annotations returns the annotation templates of alerts
+func (r *AlertingRule) Annotations() map[string]string {
+	return r.annotations
+}
+
+// HoldDuration returns how long an alert is pending before it fires
+func (r *AlertingRule) HoldDuration() time.Duration {
+	return r.holdDuration
+}
+
+// KeepFiringFor returns how long an alert keeps firing after its
+// expression stops returning it
+func (r *AlertingRule) KeepFiringFor() time.Duration {
+	return r.keepFiringFor
+}
+
+// Eval evaluates the expression, updates the state of the rule's alerts and
+// returns the ALERTS and ALERTS_FOR_STATE samples of active alerts
+func (r *AlertingRule) Eval(ctx context.Context, engine *query.Engine, ts time.Time) (query.Vector, error) {
+	val, err := engine.EvalInstant(ctx, r.expr, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	var vec query.Vector
+	switch v := val.(type) {
+	case query.Scalar:
+		vec = query.Vector{{Labels: map[string]string{}, T: ts, V: v.V}}
+	case query.Vector:
+		vec = v
+	default:
+		return nil, fmt.Errorf("rule result is not a vector or scalar")
+	}
+
+	// Build the alerts of this evaluation before touching any state, so a
+	// failing evaluation leaves the active alerts unchanged
+	current := make(map[string]*Alert, len(vec))
+	for _, s := range vec {
+		_, sampleLabels := query.SplitLabels(s.Labels)
+
+		labels := make(map[string]string, len(sampleLabels)+len(r.labels)+1)
+		for k, v := range sampleLabels {
+			labels[k] = v
+		}
+		for k, v := range r.labels {
+			if expanded := expandTemplate(k, v, sampleLabels, s.V); expanded != "" {
+				labels[k] = expanded
+			} else {
+				delete(labels, k)
+			}
+		}
+		labels[AlertNameLabel] = r.name
+
+		annotations := make(map[string]string, len(r.annotations))
+		for k, v := range r.annotations {
+			annotations[k] = expandTemplate(k, v, sampleLabels, s.V)
+		}
+
+		key := query.LabelsString(labels)
+		if _, dup := current[key]; dup {
+			return nil, fmt.Errorf("vector contains metrics with the same labelset after applying alert labels")
+		}
+		current[key] = &Alert{Labels: labels, Annotations: annotations, Value: s.V}
+	}
+
+	r.alertsMu.Lock()
+	defer r.alertsMu.Unlock()
+
+	for key, a := range current {
+		if existing, ok := r.active[key]; ok && existing.State != StateInactive {
+			existing.Value = a.Value
+			existing.Annotations = a.Annotations
+			existing.KeepFiringSince = time.Time{}
+			continue
+		}
+		a.State = StatePending
+		a.ActiveAt = ts
+		r.active[key] = a
+	}
+
+	var result query.Vector
+	for key, a := range r.active {
+		if _, ok := current[key]; !ok {
+			if a.State == StateInactive {
+				if ts.Sub(a.ResolvedAt) >= resolvedRetention {
+					delete(r.active, key)
+				}
+				continue
+			}
+
+			keepFiring := false
+			if a.State == StateFiring && r.keepFiringFor > 0 {
+				if a.KeepFiringSince.IsZero() {
+					a.KeepFiringSince = ts
+				}
+				keepFiring = ts.Sub(a.KeepFiringSince) < r.keepFiringFor
+			}
+			if !keepFiring {
+				if a.State == StatePending {
+					delete(r.active, key)
+				} else {
+					a.State = StateInactive
+					a.ResolvedAt = ts
+				}
+				continue
+			}
+		}
+
+		if a.State == StatePending && ts.Sub(a.ActiveAt) >= r.holdDuration {
+			a.State = StateFiring
+			a.FiredAt = ts
+		}
+
+		result = append(result, alertSample(a, ts), alertForStateSample(a, ts))
+	}
+	return result, nil
+}
+
+// sendAlerts passes the firing and recently resolved alerts that are due to
+// notify. interval is the evaluation interval of the rule's group.
+func (r *AlertingRule) sendAlerts(ts time.Time, resendDelay, interval time.Duration, notify NotifyFunc) {
+	var alerts []*Alert
+
+	r.alertsMu.Lock()
+	for _, a := range r.active {
+		if !a.needsSending(ts, resendDelay) {
+			continue
+		}
+		a.LastSentAt = ts
+		// Allow for a couple of missed evaluations before the alert expires
+		a.ValidUntil = ts.Add(4 * max(resendDelay, interval))
+
+		c := *a
+		alerts = append(alerts, &c)
+	}
+	r.alertsMu.Unlock()
+
+	if len(alerts) > 0 {
+		notify(alerts)
+	}
+}
+
+// alertSample returns the ALERTS sample of an active alert
+func alertSample(a *Alert, ts time.Time) query.Sample {
+	labels := make(map[string]string, len(a.Labels)+2)
+	for k, v := range a.Labels {
+		labels[k] = v
+	}
+	labels[metrics.MetricNameLabel] = AlertMetricName
+	labels[AlertStateLabel] = string(a.State)
+	return query.Sample{Labels: labels, T: ts, V: 1}
+}
+
+// alertForStateSample returns the ALERTS_FOR_STATE sample of an active
+// alert, whose value is the time the alert became active
+func alertForStateSample(a *Alert, ts time.Time) query.Sample {
+	labels := make(map[string]string, len(a.Labels)+1)
+	for k, v := range a.Labels {
+		labels[k] = v
+	}
+	labels[metrics.MetricNameLabel] = AlertForStateMetricName
+	return query.Sample{Labels: labels, T: ts, V: float64(a.ActiveAt.Unix())}
+}
+
+// State returns the most severe state of the rule's alerts
+func (r *AlertingRule) State() AlertState {
+	r.alertsMu.RLock()
+	defer r.alertsMu.RUnlock()
+
+	state := StateInactive
+	for _, a := range r.active {
+		switch a.State {
+		case StateFiring:
+			return StateFiring
+		case StatePending:
+			state = StatePending
+		}
+	}
+	return state
+}
+
+// ActiveAlerts returns copies of the pending and firing alerts of the rule,
+// sorted by labels
+func (r *AlertingRule) ActiveAlerts() []*Alert {
+	var alerts []*Alert
+	for _, a := range r.alerts() {
+		if a.State != StateInactive {
+			alerts = append(alerts, a)
+		}
+	}
+	return alerts
+}
+
+// alerts returns copies of all alerts of the rule, including recently
+// resolved ones, sorted by labels
+func (r *AlertingRule) alerts() []*Alert {
+	r.alertsMu.RLock()
+	defer r.alertsMu.RUnlock()
+
+	alerts := make([]*Alert, 0, len(r.active))
+	for _, a := range r.active {
+		c := *a
+		alerts = append(alerts, &c)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return query.LabelsString(alerts[i].Labels) < query.LabelsString(alerts[j].Labels)
+	})
+	return alerts
+}
@@ -0,0 +1,261 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+)
+
+// setQueueSize registers the value of the queue_size series at ts
+func setQueueSize(registry *metrics.MetricRegistry, value float64, ts time.Time) {
+	registry.Register(&metrics.Metric{
+		Name:      "queue_size",
+		Type:      metrics.MetricTypeGauge,
+		Value:     value,
+		Labels:    map[string]string{"job": "worker", "instance": "w1"},
+		Timestamp: ts,
+	})
+}
+
+func TestAlertingRule(t *testing.T) {
+	t.Run("Pending then firing then resolved", func(t *testing.T) {
+		registry := metrics.NewMetricRegistry()
+		engine := query.NewEngine(registry)
+		rule, err := NewAlertingRule("QueueTooLong", `queue_size > 10`, 2*time.Minute, 0,
+			map[string]string{"severity": "page"},
+			map[string]string{"summary": "Queue of {{ $labels.instance }} is {{ $value }}"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		group := NewGroup("alerts", "alerts.yml", time.Minute, []Rule{rule})
+
+		start := time.Unix(10000, 0)
+		steps := []struct {
+			value    float64
+			expected AlertState
+		}{
+			{5, StateInactive},
+			{20, StatePending},
+			{25, StatePending},
+			{30, StateFiring},
+			{5, StateInactive},
+		}
+		for i, step := range steps {
+			ts := start.Add(time.Duration(i) * time.Minute)
+			setQueueSize(registry, step.value, ts)
+			group.Eval(context.Background(), engine, registry, ts)
+
+			if state := rule.State(); state != step.expected {
+				t.Fatalf("Step %d: expected state %s, got %s", i, step.expected, state)
+			}
+		}
+
+		if rule.Health() != HealthGood {
+			t.Errorf("Expected health ok, got %s", rule.Health())
+		}
+		if len(rule.ActiveAlerts()) != 0 {
+			t.Errorf("Expected no active alerts after resolution, got %d", len(rule.ActiveAlerts()))
+		}
+
+		alerts := rule.alerts()
+		if len(alerts) != 1 || alerts[0].ResolvedAt.IsZero() {
+			t.Fatalf("Expected resolved alert to be retained, got %v", alerts)
+		}
+		if alerts[0].Annotations["summary"] != "Queue of w1 is 30" {
+			t.Errorf("Expected expanded annotation, got %q", alerts[0].Annotations["summary"])
+		}
+		if _, ok := registry.Get(AlertMetricName, map[string]string{
+			"alertname": "QueueTooLong", "alertstate": "firing", "severity": "page", "job": "worker", "instance": "w1",
+		}); ok {
+			t.Error("Expected ALERTS series to be removed after resolution")
+		}
+	})
+
+	t.Run("ALERTS and ALERTS_FOR_STATE series", func(t *testing.T) {
+		registry := metrics.NewMetricRegistry()
+		engine := query.NewEngine(registry)
+		rule, _ := NewAlertingRule("QueueTooLong", `queue_size > 10`, time.Minute, 0, nil, nil)
+		group := NewGroup("alerts", "alerts.yml", time.Minute, []Rule{rule})
+
+		start := time.Unix(10000, 0)
+		setQueueSize(registry, 20, start)
+		group.Eval(context.Background(), engine, registry, start)
+
+		labels := map[string]string{"alertname": "QueueTooLong", "job": "worker", "instance": "w1"}
+		pendingLabels := map[string]string{"alertname": "QueueTooLong", "alertstate": "pending", "job": "worker", "instance": "w1"}
+		if m, ok := registry.Get(AlertMetricName, pendingLabels); !ok || m.Value != 1 {
+			t.Errorf("Expected pending ALERTS series with value 1, got %v", m)
+		}
+		if m, ok := registry.Get(AlertForStateMetricName, labels); !ok || m.Value != float64(start.Unix()) {
+			t.Errorf("Expected ALERTS_FOR_STATE series with active time, got %v", m)
+		}
+
+		ts := start.Add(time.Minute)
+		setQueueSize(registry, 20, ts)
+		group.Eval(context.Background(), engine, registry, ts)
+
+		if _, ok := registry.Get(AlertMetricName, pendingLabels); ok {
+			t.Error("Expected pending ALERTS series to be removed once firing")
+		}
+		firingLabels := map[string]string{"alertname": "QueueTooLong", "alertstate": "firing", "job": "worker", "instance": "w1"}
+		if _, ok := registry.Get(AlertMetricName, firingLabels); !ok {
+			t.Error("Expected firing ALERTS series")
+		}
+	})
+
+	t.Run("Keep firing for", func(t *testing.T) {
+		registry := metrics.NewMetricRegistry()
+		engine := query.NewEngine(registry)
+		rule, _ := NewAlertingRule("QueueTooLong", `queue_size > 10`, 0, 2*time.Minute, nil, nil)
+		group := NewGroup("alerts", "alerts.yml", time.Minute, []Rule{rule})
+
+		start := time.Unix(10000, 0)
+		values := []float64{20, 5, 5, 5}
+		expected := []AlertState{StateFiring, StateFiring, StateFiring, StateInactive}
+		for i, v := range values {
+			ts := start.Add(time.Duration(i) * time.Minute)
+			setQueueSize(registry, v, ts)
+			group.Eval(context.Background(), engine, registry, ts)
+
+			if state := rule.State(); state != expected[i] {
+				t.Fatalf("Step %d: expected state %s, got %s", i, expected[i], state)
+			}
+		}
+	})
+
+	t.Run("Pending alert disappears without firing", func(t *testing.T) {
+		registry := metrics.NewMetricRegistry()
+		engine := query.NewEngine(registry)
+		rule, _ := NewAlertingRule("QueueTooLong", `queue_size > 10`, 5*time.Minute, 0, nil, nil)
+
+		start := time.Unix(10000, 0)
+		setQueueSize(registry, 20, start)
+		if _, err := rule.Eval(context.Background(), engine, start); err != nil {
+			t.Fatal(err)
+		}
+		setQueueSize(registry, 5, start.Add(time.Minute))
+		if _, err := rule.Eval(context.Background(), engine, start.Add(time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(rule.alerts()) != 0 {
+			t.Errorf("Expected pending alert to be dropped, got %v", rule.alerts())
+		}
+	})
+
+	t.Run("Label templates", func(t *testing.T) {
+		registry := metrics.NewMetricRegistry()
+		engine := query.NewEngine(registry)
+		rule, _ := NewAlertingRule("QueueTooLong", `queue_size > 10`, 0, 0,
+			map[string]string{"owner": "team-{{ $labels.job }}"}, nil)
+
+		ts := time.Unix(10000, 0)
+		setQueueSize(registry, 20, ts)
+		if _, err := rule.Eval(context.Background(), engine, ts); err != nil {
+			t.Fatal(err)
+		}
+
+		alerts := rule.ActiveAlerts()
+		if len(alerts) != 1 || alerts[0].Labels["owner"] != "team-worker" {
+			t.Errorf("Expected owner label team-worker, got %v", alerts)
+		}
+	})
+}
+
+func TestSendAlerts(t *testing.T) {
+	registry := metrics.NewMetricRegistry()
+	engine := query.NewEngine(registry)
+	rule, _ := NewAlertingRule("QueueTooLong", `queue_size > 10`, time.Minute, 0, nil, nil)
+	group := NewGroup("alerts", "alerts.yml", time.Minute, []Rule{rule})
+
+	var sent [][]*Alert
+	group.notify = func(alerts []*Alert) {
+		sent = append(sent, alerts)
+	}
+	group.resendDelay = 2 * time.Minute
+
+	start := time.Unix(10000, 0)
+	values := []float64{20, 20, 20, 20, 20, 5}
+	// Pending, firing (sent), not due, not due, resent, resolved (sent)
+	expectedSends := []int{0, 1, 1, 1, 2, 3}
+	for i, v := range values {
+		ts := start.Add(time.Duration(i) * time.Minute)
+		setQueueSize(registry, v, ts)
+		group.Eval(context.Background(), engine, registry, ts)
+
+		if len(sent) != expectedSends[i] {
+			t.Fatalf("Step %d: expected %d notifications, got %d", i, expectedSends[i], len(sent))
+		}
+	}
+
+	firing := sent[0][0]
+	if firing.State != StateFiring || !firing.ValidUntil.Equal(start.Add(time.Minute).Add(8*time.Minute)) {
+		t.Errorf("Expected firing alert valid for 4 resend delays, got %+v", firing)
+	}
+
+	resolved := sent[2][0]
+	if resolved.State != StateInactive || !resolved.ResolvedAt.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("Expected resolved alert, got %+v", resolved)
+	}
+}
+
+func TestParseAlertingRules(t *testing.T) {
+	dir := t.TempDir()
+
+	path := writeRuleFile(t, dir, "alerts.yml", `groups:
+  - name: alerts
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+        for: 5m
+        keep_firing_for: 10m
+        labels:
+          severity: page
+        annotations:
+          summary: "{{ $labels.instance }} is down"
+`)
+	rf, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	r := rf.Groups[0].Rules[0]
+	if r.Alert != "InstanceDown" || r.For != 5*time.Minute || r.KeepFiringFor != 10*time.Minute {
+		t.Errorf("Alerting rule not parsed correctly: %+v", r)
+	}
+
+	invalid := map[string]string{
+		"record and alert": `groups:
+  - name: a
+    rules:
+      - record: r
+        alert: A
+        expr: up
+`,
+		"for on recording rule": `groups:
+  - name: a
+    rules:
+      - record: r
+        expr: up
+        for: 5m
+`,
+		"bad template": `groups:
+  - name: a
+    rules:
+      - alert: A
+        expr: up
+        annotations:
+          summary: "{{ $labels.instance"
+`,
+	}
+	for name, content := range invalid {
+		t.Run("Invalid "+name, func(t *testing.T) {
+			path := writeRuleFile(t, dir, "invalid.yml", content)
+			if _, err := ParseFile(path); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
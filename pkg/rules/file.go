@@ -0,0 +1,136 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+)
+
+// RuleFile is the content of a rule file
+type RuleFile struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup is a named set of rules evaluated together on an interval
+type RuleGroup struct {
+	Name     string        `yaml:"name"`
+	Interval time.Duration `yaml:"interval,omitempty"`
+	Rules    []RuleNode    `yaml:"rules"`
+}
+
+// RuleNode is a single recording or alerting rule as written in a rule file
+type RuleNode struct {
+	Record        string            `yaml:"record,omitempty"`
+	Alert         string            `yaml:"alert,omitempty"`
+	Expr          string            `yaml:"expr"`
+	For           time.Duration     `yaml:"for,omitempty"`
+	KeepFiringFor time.Duration     `yaml:"keep_firing_for,omitempty"`
+	Labels        map[string]string `yaml:"labels,omitempty"`
+	Annotations   map[string]string `yaml:"annotations,omitempty"`
+}
+
+// ParseFile reads and validates a rule file
+func ParseFile(path string) (*RuleFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule file: %w", err)
+	}
+
+	var rf RuleFile
+	if err := yaml.Unmarshal(data, &rf); err != nil {
+		return nil, fmt.Errorf("failed to parse rule file %s: %w", path, err)
+	}
+	if err := rf.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rule file %s: %w", path, err)
+	}
+	return &rf, nil
+}
+
+// Validate checks group names are unique and every rule is well formed
+func (rf *RuleFile) Validate() error {
+	seen := make(map[string]bool)
+	for _, g := range rf.Groups {
+		if g.Name == "" {
+			return fmt.Errorf("group name must not be empty")
+		}
+		if seen[g.Name] {
+			return fmt.Errorf("duplicate group name %q", g.Name)
+		}
+		seen[g.Name] = true
+
+		if g.Interval < 0 {
+			return fmt.Errorf("group %q: interval must not be negative", g.Name)
+		}
+		for i, r := range g.Rules {
+			if err := r.Validate(); err != nil {
+				return fmt.Errorf("group %q, rule %d: %w", g.Name, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the rule has a valid name, expression, labels and, for
+// alerting rules, valid templates
+func (r *RuleNode) Validate() error {
+	switch {
+	case r.Record != "" && r.Alert != "":
+		return fmt.Errorf("only one of 'record' and 'alert' must be set")
+	case r.Record == "" && r.Alert == "":
+		return fmt.Errorf("one of 'record' or 'alert' must be set")
+	}
+
+	if r.Record != "" {
+		if !metrics.IsValidMetricName(r.Record) {
+			return fmt.Errorf("invalid recording rule name %q", r.Record)
+		}
+		if r.For != 0 {
+			return fmt.Errorf("invalid field 'for' in recording rule")
+		}
+		if r.KeepFiringFor != 0 {
+			return fmt.Errorf("invalid field 'keep_firing_for' in recording rule")
+		}
+		if len(r.Annotations) > 0 {
+			return fmt.Errorf("invalid field 'annotations' in recording rule")
+		}
+	}
+	if r.For < 0 || r.KeepFiringFor < 0 {
+		return fmt.Errorf("'for' and 'keep_firing_for' must not be negative")
+	}
+
+	if r.Expr == "" {
+		return fmt.Errorf("'expr' must be set")
+	}
+	expr, err := query.ParseExpr(r.Expr)
+	if err != nil {
+		return fmt.Errorf("could not parse expression: %w", err)
+	}
+	if t := expr.Type(); t != query.ValueTypeScalar && t != query.ValueTypeVector {
+		return fmt.Errorf("expression must evaluate to a scalar or instant vector, got %s", t)
+	}
+
+	for name, value := range r.Labels {
+		if !metrics.IsValidLabelName(name) {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+		if r.Alert != "" {
+			if err := checkTemplate(name, value); err != nil {
+				return err
+			}
+		}
+	}
+	for name, value := range r.Annotations {
+		if !metrics.IsValidLabelName(name) {
+			return fmt.Errorf("invalid annotation name %q", name)
+		}
+		if err := checkTemplate(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package rules
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+)
+
+// Group is a set of rules evaluated in order on a shared interval. Later
+// rules see the samples written by earlier rules of the same evaluation.
+type Group struct {
+	name     string
+	file     string
+	interval time.Duration
+	rules    []Rule
+
+	// notify receives alerts due to be sent, resent every resendDelay
+	notify      NotifyFunc
+	resendDelay time.Duration
+
+	mu                 sync.RWMutex
+	lastEvaluation     time.Time
+	evaluationDuration time.Duration
+
+	// seriesInPreviousEval holds the series written by each rule in the
+	// previous evaluation, so series a rule no longer produces can be removed
+	seriesInPreviousEval []map[string]map[string]string
+}
+
+// NewGroup creates a rule group
+func NewGroup(name, file string, interval time.Duration, rules []Rule) *Group {
+	return &Group{
+		name:                 name,
+		file:                 file,
+		interval:             interval,
+		rules:                rules,
+		seriesInPreviousEval: make([]map[string]map[string]string, len(rules)),
+	}
+}
+
+// Name returns the name of the group
+func (g *Group) Name() string {
+	return g.name
+}
+
+// File returns the rule file the group was loaded from
+func (g *Group) File() string {
+	return g.file
+}
+
+// Interval returns how often the group is evaluated
+func (g *Group) Interval() time.Duration {
+	return g.interval
+}
+
+// Rules returns the rules of the group
+func (g *Group) Rules() []Rule {
+	return g.rules
+}
+
+// AlertingRules returns the alerting rules of the group
+func (g *Group) AlertingRules() []*AlertingRule {
+	var alerting []*AlertingRule
+	for _, rule := range g.rules {
+		if ar, ok := rule.(*AlertingRule); ok {
+			alerting = append(alerting, ar)
+		}
+	}
+	return alerting
+}
+
+// LastEvaluation returns when the group was last evaluated
+func (g *Group) LastEvaluation() time.Time {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.lastEvaluation
+}
+
+// EvaluationDuration returns how long the last evaluation of the group took
+func (g *Group) EvaluationDuration() time.Duration {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.evaluationDuration
+}
+
+// Eval evaluates every rule of the group at ts and writes the results to the
+// registry as gauges. Series a rule produced in the previous evaluation but
+// not in this one are removed. A failing rule does not stop the remaining
+// rules and keeps its previous series.
+func (g *Group) Eval(ctx context.Context, engine *query.Engine, registry *metrics.MetricRegistry, ts time.Time) {
+	groupStart := time.Now()
+
+	for i, rule := range g.rules {
+		start := time.Now()
+		vec, err := rule.Eval(ctx, engine, ts)
+		rule.setEvaluation(ts, time.Since(start), err)
+		if err != nil {
+			slog.Error("Error evaluating rule", "group", g.name, "rule", rule.Name(), "err", err)
+			continue
+		}
+
+		if ar, ok := rule.(*AlertingRule); ok && g.notify != nil {
+			ar.sendAlerts(ts, g.resendDelay, g.interval, g.notify)
+		}
+
+		seriesReturned := make(map[string]map[string]string, len(vec))
+		for _, s := range vec {
+			name, labels := query.SplitLabels(s.Labels)
+			registry.Register(&metrics.Metric{
+				Name:      name,
+				Type:      metrics.MetricTypeGauge,
+				Value:     s.V,
+				Labels:    labels,
+				Timestamp: ts,
+			})
+			seriesReturned[query.LabelsString(s.Labels)] = s.Labels
+		}
+
+		for key, labels := range g.seriesInPreviousEval[i] {
+			if _, ok := seriesReturned[key]; !ok {
+				name, rest := query.SplitLabels(labels)
+				registry.Delete(name, rest)
+			}
+		}
+		g.seriesInPreviousEval[i] = seriesReturned
+	}
+
+	g.mu.Lock()
+	g.lastEvaluation = ts
+	g.evaluationDuration = time.Since(groupStart)
+	g.mu.Unlock()
+}
+
+// copyState takes over the state of a group being replaced: the active
+// alerts of alerting rules and the series written by every rule, matched by
+// rule name and expression. Neither group may be running.
+func (g *Group) copyState(prev *Group) {
+	prevRules := make(map[string]int, len(prev.rules))
+	for i, rule := range prev.rules {
+		prevRules[rule.Name()+"\xff"+rule.Query()] = i
+	}
+
+	for i, rule := range g.rules {
+		j, ok := prevRules[rule.Name()+"\xff"+rule.Query()]
+		if !ok {
+			continue
+		}
+		// Each previous rule hands over its state once
+		delete(prevRules, rule.Name()+"\xff"+rule.Query())
+
+		g.seriesInPreviousEval[i] = prev.seriesInPreviousEval[j]
+		if ar, ok := rule.(*AlertingRule); ok {
+			if prevAR, ok := prev.rules[j].(*AlertingRule); ok {
+				prevAR.alertsMu.RLock()
+				ar.alertsMu.Lock()
+				ar.active = prevAR.active
+				ar.alertsMu.Unlock()
+				prevAR.alertsMu.RUnlock()
+			}
+		}
+	}
+}
+
+// run evaluates the group every interval until ctx is cancelled
+func (g *Group) run(ctx context.Context, engine *query.Engine, registry *metrics.MetricRegistry) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	g.Eval(ctx, engine, registry, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ts := <-ticker.C:
+			g.Eval(ctx, engine, registry, ts)
+		}
+	}
+}
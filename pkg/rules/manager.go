@@ -0,0 +1,223 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+)
+
+const (
+	// DefaultEvaluationInterval is used for groups that do not set an interval
+	DefaultEvaluationInterval = time.Minute
+	// DefaultResendDelay is how often firing alerts are resent by default
+	DefaultResendDelay = time.Minute
+)
+
+// Manager loads rule groups and evaluates them periodically
+type Manager struct {
+	engine   *query.Engine
+	registry *metrics.MetricRegistry
+	interval time.Duration
+
+	notify      NotifyFunc
+	resendDelay time.Duration
+
+	mu     sync.RWMutex
+	groups []*Group
+
+	// runMu serializes starting and stopping group evaluation
+	runMu  sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager creates a rule manager that evaluates rules with engine and
+// writes their results to registry
+func NewManager(engine *query.Engine, registry *metrics.MetricRegistry) *Manager {
+	return &Manager{
+		engine:      engine,
+		registry:    registry,
+		interval:    DefaultEvaluationInterval,
+		resendDelay: DefaultResendDelay,
+	}
+}
+
+// SetNotifier sets where alerts are sent and how often firing alerts are
+// resent. It applies to groups loaded afterwards.
+func (m *Manager) SetNotifier(notify NotifyFunc, resendDelay time.Duration) {
+	m.notify = notify
+	m.resendDelay = resendDelay
+}
+
+// SetEvaluationInterval sets how often groups without their own interval
+// are evaluated. It applies to groups loaded afterwards.
+func (m *Manager) SetEvaluationInterval(interval time.Duration) {
+	m.interval = interval
+}
+
+// LoadGroups loads the groups of every rule file matching the given glob
+// patterns, replacing any previously loaded groups
+func (m *Manager) LoadGroups(patterns []string) error {
+	groups, err := m.ParseGroups(patterns, m.interval)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.groups = groups
+	m.mu.Unlock()
+	return nil
+}
+
+// Update loads the groups of the given rule files and, if they are all
+// valid, replaces the running groups with them. The state of alerts is
+// carried over to alerting rules with the same name and expression in a
+// group of the same name and file, so reloading does not reset pending and
+// firing alerts.
+func (m *Manager) Update(patterns []string) error {
+	groups, err := m.ParseGroups(patterns, m.interval)
+	if err != nil {
+		return err
+	}
+	m.ReplaceGroups(groups, m.interval)
+	return nil
+}
+
+// ReplaceGroups replaces the running groups with groups returned by
+// ParseGroups like Update, and sets the evaluation interval of groups loaded
+// afterwards
+func (m *Manager) ReplaceGroups(groups []*Group, interval time.Duration) {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+
+	// Wait for running evaluations to finish before taking over their state
+	if m.cancel != nil {
+		m.cancel()
+		m.wg.Wait()
+	}
+
+	m.mu.Lock()
+	old := make(map[string]*Group, len(m.groups))
+	for _, g := range m.groups {
+		old[g.file+"\xff"+g.name] = g
+	}
+	for _, g := range groups {
+		if prev, ok := old[g.file+"\xff"+g.name]; ok {
+			g.copyState(prev)
+		}
+	}
+	m.groups = groups
+	m.mu.Unlock()
+
+	m.interval = interval
+	if m.ctx != nil {
+		m.startGroups()
+	}
+}
+
+// ParseGroups parses the groups of every rule file matching the patterns
+// without changing the running groups. Groups without their own interval
+// are evaluated every interval.
+func (m *Manager) ParseGroups(patterns []string, interval time.Duration) ([]*Group, error) {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule file pattern %q: %w", pattern, err)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	var groups []*Group
+	for _, file := range files {
+		rf, err := ParseFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rg := range rf.Groups {
+			groupInterval := rg.Interval
+			if groupInterval == 0 {
+				groupInterval = interval
+			}
+
+			rules := make([]Rule, 0, len(rg.Rules))
+			for _, r := range rg.Rules {
+				var rule Rule
+				var err error
+				if r.Alert != "" {
+					rule, err = NewAlertingRule(r.Alert, r.Expr, r.For, r.KeepFiringFor, r.Labels, r.Annotations)
+				} else {
+					rule, err = NewRecordingRule(r.Record, r.Expr, r.Labels)
+				}
+				if err != nil {
+					return nil, fmt.Errorf("%s: group %q: %w", file, rg.Name, err)
+				}
+				rules = append(rules, rule)
+			}
+			group := NewGroup(rg.Name, file, groupInterval, rules)
+			group.notify = m.notify
+			group.resendDelay = m.resendDelay
+			groups = append(groups, group)
+		}
+	}
+
+	return groups, nil
+}
+
+// Groups returns the loaded rule groups
+func (m *Manager) Groups() []*Group {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.groups
+}
+
+// AlertingRules returns the alerting rules of all groups
+func (m *Manager) AlertingRules() []*AlertingRule {
+	var alerting []*AlertingRule
+	for _, g := range m.Groups() {
+		alerting = append(alerting, g.AlertingRules()...)
+	}
+	return alerting
+}
+
+// Alerts returns the pending and firing alerts of all alerting rules
+func (m *Manager) Alerts() []*Alert {
+	var alerts []*Alert
+	for _, rule := range m.AlertingRules() {
+		alerts = append(alerts, rule.ActiveAlerts()...)
+	}
+	return alerts
+}
+
+// Start begins evaluating every group on its interval until ctx is cancelled
+func (m *Manager) Start(ctx context.Context) {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+
+	m.ctx = ctx
+	m.startGroups()
+}
+
+// startGroups runs every group until the manager's context is cancelled or
+// the groups are replaced. Must be called with m.runMu held.
+func (m *Manager) startGroups() {
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.cancel = cancel
+	for _, g := range m.Groups() {
+		m.wg.Add(1)
+		go func(g *Group) {
+			defer m.wg.Done()
+			g.run(ctx, m.engine, m.registry)
+		}(g)
+	}
+}
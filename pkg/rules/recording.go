@@ -0,0 +1,95 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+)
+
+// RecordingRule stores the result of an expression as a new series
+type RecordingRule struct {
+	evaluationState
+
+	name   string
+	query  string
+	expr   query.Expr
+	labels map[string]string
+}
+
+// NewRecordingRule creates a recording rule writing the result of expr to
+// series named name
+func NewRecordingRule(name, expr string, labels map[string]string) (*RecordingRule, error) {
+	parsed, err := query.ParseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordingRule{
+		name:   name,
+		query:  expr,
+		expr:   parsed,
+		labels: labels,
+	}, nil
+}
+
+// Name returns the name of the recorded series
+func (r *RecordingRule) Name() string {
+	return r.name
+}
+
+// Query returns the expression of the rule
+func (r *RecordingRule) Query() string {
+	return r.query
+}
+
+// Labels returns the labels added to recorded samples
+func (r *RecordingRule) Labels() map[string]string {
+	return r.labels
+}
+
+// Eval evaluates the expression and renames every resulting sample to the
+// rule name with the rule labels applied
+func (r *RecordingRule) Eval(ctx context.Context, engine *query.Engine, ts time.Time) (query.Vector, error) {
+	val, err := engine.EvalInstant(ctx, r.expr, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	var vec query.Vector
+	switch v := val.(type) {
+	case query.Scalar:
+		vec = query.Vector{{Labels: map[string]string{}, T: ts, V: v.V}}
+	case query.Vector:
+		vec = v
+	default:
+		return nil, fmt.Errorf("rule result is not a vector or scalar")
+	}
+
+	result := make(query.Vector, 0, len(vec))
+	seen := make(map[string]bool, len(vec))
+	for _, s := range vec {
+		labels := make(map[string]string, len(s.Labels)+len(r.labels)+1)
+		for k, v := range s.Labels {
+			labels[k] = v
+		}
+		for k, v := range r.labels {
+			if v == "" {
+				delete(labels, k)
+			} else {
+				labels[k] = v
+			}
+		}
+		labels[metrics.MetricNameLabel] = r.name
+
+		key := query.LabelsString(labels)
+		if seen[key] {
+			return nil, fmt.Errorf("vector contains metrics with the same labelset after applying rule labels")
+		}
+		seen[key] = true
+
+		result = append(result, query.Sample{Labels: labels, T: ts, V: s.V})
+	}
+	return result, nil
+}
@@ -0,0 +1,94 @@
+package rules
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+)
+
+// RuleHealth describes the outcome of the last evaluation of a rule
+type RuleHealth string
+
+const (
+	HealthUnknown RuleHealth = "unknown"
+	HealthGood    RuleHealth = "ok"
+	HealthBad     RuleHealth = "err"
+)
+
+// Rule is a rule evaluated periodically by its group
+type Rule interface {
+	// Name returns the name of the rule
+	Name() string
+	// Query returns the expression of the rule
+	Query() string
+	// Labels returns the labels added to the rule's output
+	Labels() map[string]string
+	// Eval evaluates the rule at ts and returns the samples to store
+	Eval(ctx context.Context, engine *query.Engine, ts time.Time) (query.Vector, error)
+
+	// Health, LastError, LastEvaluation and EvaluationDuration describe
+	// the last evaluation of the rule
+	Health() RuleHealth
+	LastError() string
+	LastEvaluation() time.Time
+	EvaluationDuration() time.Duration
+
+	setEvaluation(ts time.Time, duration time.Duration, err error)
+}
+
+// evaluationState tracks the outcome of the last evaluation of a rule
+type evaluationState struct {
+	mu                 sync.RWMutex
+	health             RuleHealth
+	lastError          string
+	lastEvaluation     time.Time
+	evaluationDuration time.Duration
+}
+
+func (s *evaluationState) Health() RuleHealth {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.health == "" {
+		return HealthUnknown
+	}
+	return s.health
+}
+
+func (s *evaluationState) LastError() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.lastError
+}
+
+func (s *evaluationState) LastEvaluation() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.lastEvaluation
+}
+
+func (s *evaluationState) EvaluationDuration() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.evaluationDuration
+}
+
+func (s *evaluationState) setEvaluation(ts time.Time, duration time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastEvaluation = ts
+	s.evaluationDuration = duration
+	if err != nil {
+		s.health = HealthBad
+		s.lastError = err.Error()
+	} else {
+		s.health = HealthGood
+		s.lastError = ""
+	}
+}
@@ -0,0 +1,264 @@
+package rules
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+)
+
+// writeRuleFile writes a rule file into dir and returns its path
+func writeRuleFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseFile(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("Valid file", func(t *testing.T) {
+		path := writeRuleFile(t, dir, "valid.yml", `groups:
+  - name: http
+    interval: 30s
+    rules:
+      - record: job:http_requests:rate5m
+        expr: sum by (job) (rate(http_requests_total[5m]))
+        labels:
+          team: platform
+`)
+		rf, err := ParseFile(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(rf.Groups) != 1 {
+			t.Fatalf("Expected 1 group, got %d", len(rf.Groups))
+		}
+		g := rf.Groups[0]
+		if g.Name != "http" || g.Interval != 30*time.Second {
+			t.Errorf("Group not parsed correctly: %+v", g)
+		}
+		if len(g.Rules) != 1 || g.Rules[0].Record != "job:http_requests:rate5m" || g.Rules[0].Labels["team"] != "platform" {
+			t.Errorf("Rule not parsed correctly: %+v", g.Rules)
+		}
+	})
+
+	invalid := map[string]string{
+		"duplicate group": `groups:
+  - name: a
+    rules: []
+  - name: a
+    rules: []
+`,
+		"missing record": `groups:
+  - name: a
+    rules:
+      - expr: up
+`,
+		"invalid name": `groups:
+  - name: a
+    rules:
+      - record: 1bad
+        expr: up
+`,
+		"bad expression": `groups:
+  - name: a
+    rules:
+      - record: good
+        expr: sum(
+`,
+		"range vector": `groups:
+  - name: a
+    rules:
+      - record: good
+        expr: up[5m]
+`,
+	}
+	for name, content := range invalid {
+		t.Run("Invalid "+name, func(t *testing.T) {
+			path := writeRuleFile(t, dir, "invalid.yml", content)
+			if _, err := ParseFile(path); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestRecordingRule(t *testing.T) {
+	registry := metrics.NewMetricRegistry()
+	start := time.Unix(1000, 0)
+	for i := 0; i <= 20; i++ {
+		for _, instance := range []string{"a", "b"} {
+			registry.Register(&metrics.Metric{
+				Name:      "http_requests_total",
+				Type:      metrics.MetricTypeCounter,
+				Value:     float64(i * 15),
+				Labels:    map[string]string{"job": "api", "instance": instance},
+				Timestamp: start.Add(time.Duration(i) * 15 * time.Second),
+			})
+		}
+	}
+	engine := query.NewEngine(registry)
+	ts := start.Add(300 * time.Second)
+
+	t.Run("Writes results to the registry", func(t *testing.T) {
+		rule, err := NewRecordingRule("job:http_requests:rate5m", `sum by (job) (rate(http_requests_total[5m]))`, map[string]string{"team": "platform"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		group := NewGroup("http", "rules.yml", time.Minute, []Rule{rule})
+		group.Eval(context.Background(), engine, registry, ts)
+
+		metric, ok := registry.Get("job:http_requests:rate5m", map[string]string{"job": "api", "team": "platform"})
+		if !ok {
+			t.Fatal("Expected recorded series to exist")
+		}
+		if metric.Value != 2 {
+			t.Errorf("Expected value 2, got %v", metric.Value)
+		}
+		if metric.Type != metrics.MetricTypeGauge {
+			t.Errorf("Expected gauge, got %s", metric.Type)
+		}
+		if !metric.Timestamp.Equal(ts) {
+			t.Errorf("Expected timestamp %v, got %v", ts, metric.Timestamp)
+		}
+	})
+
+	t.Run("Later rules see earlier results", func(t *testing.T) {
+		first, _ := NewRecordingRule("instance_count", `count(http_requests_total)`, nil)
+		second, _ := NewRecordingRule("instance_count_times_ten", `instance_count * 10`, nil)
+		group := NewGroup("chain", "rules.yml", time.Minute, []Rule{first, second})
+		group.Eval(context.Background(), engine, registry, ts)
+
+		metric, ok := registry.Get("instance_count_times_ten", map[string]string{})
+		if !ok || metric.Value != 20 {
+			t.Errorf("Expected chained rule value 20, got %v", metric)
+		}
+	})
+
+	t.Run("Duplicate label sets are rejected", func(t *testing.T) {
+		rule, _ := NewRecordingRule("dup", `http_requests_total`, map[string]string{"instance": ""})
+		if _, err := rule.Eval(context.Background(), engine, ts); err == nil {
+			t.Error("Expected error for duplicate label sets")
+		}
+	})
+}
+
+func TestManagerLoadGroups(t *testing.T) {
+	dir := t.TempDir()
+	writeRuleFile(t, dir, "a.yml", `groups:
+  - name: fast
+    interval: 10s
+    rules:
+      - record: r1
+        expr: vector(1)
+`)
+	writeRuleFile(t, dir, "b.yml", `groups:
+  - name: default
+    rules:
+      - record: r2
+        expr: vector(2)
+`)
+
+	manager := NewManager(query.NewEngine(metrics.NewMetricRegistry()), metrics.NewMetricRegistry())
+	if err := manager.LoadGroups([]string{filepath.Join(dir, "*.yml")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	groups := manager.Groups()
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(groups))
+	}
+	if groups[0].Name() != "fast" || groups[0].Interval() != 10*time.Second {
+		t.Errorf("Expected fast group with 10s interval, got %s %v", groups[0].Name(), groups[0].Interval())
+	}
+	if groups[1].Interval() != DefaultEvaluationInterval {
+		t.Errorf("Expected default interval, got %v", groups[1].Interval())
+	}
+
+	manager.SetEvaluationInterval(30 * time.Second)
+	if err := manager.LoadGroups([]string{filepath.Join(dir, "*.yml")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if groups := manager.Groups(); groups[0].Interval() != 10*time.Second || groups[1].Interval() != 30*time.Second {
+		t.Errorf("Expected intervals 10s and 30s, got %v and %v", groups[0].Interval(), groups[1].Interval())
+	}
+}
+
+func TestManagerUpdate(t *testing.T) {
+	dir := t.TempDir()
+	alerts := `groups:
+  - name: alerts
+    interval: 1h
+    rules:
+      - alert: AlwaysOn
+        expr: vector(1)
+        for: 1h
+`
+	writeRuleFile(t, dir, "a.yml", alerts)
+	pattern := filepath.Join(dir, "*.yml")
+
+	registry := metrics.NewMetricRegistry()
+	manager := NewManager(query.NewEngine(registry), registry)
+	if err := manager.LoadGroups([]string{pattern}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	manager.Start(ctx)
+
+	waitForAlerts := func(t *testing.T) *Alert {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for len(manager.Alerts()) == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("Timed out waiting for alert")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		return manager.Alerts()[0]
+	}
+	pending := waitForAlerts(t)
+
+	t.Run("Keeps alert state of unchanged rules", func(t *testing.T) {
+		writeRuleFile(t, dir, "b.yml", `groups:
+  - name: recording
+    rules:
+      - record: r1
+        expr: vector(2)
+`)
+		if err := manager.Update([]string{pattern}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if groups := manager.Groups(); len(groups) != 2 {
+			t.Fatalf("Expected 2 groups, got %d", len(groups))
+		}
+		if a := waitForAlerts(t); !a.ActiveAt.Equal(pending.ActiveAt) || a.State != StatePending {
+			t.Errorf("Expected pending alert active since %v, got %+v", pending.ActiveAt, a)
+		}
+	})
+
+	t.Run("Keeps running groups on invalid rules", func(t *testing.T) {
+		writeRuleFile(t, dir, "c.yml", `groups:
+  - name: broken
+    rules:
+      - record: r2
+        expr: rate(
+`)
+		if err := manager.Update([]string{pattern}); err == nil {
+			t.Fatal("Expected error for invalid rule file")
+		}
+		if groups := manager.Groups(); len(groups) != 2 {
+			t.Errorf("Expected previous 2 groups, got %d", len(groups))
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// templateDefs makes the alert labels and value available to label and
+// annotation templates as $labels and $value
+const templateDefs = "{{$labels := .Labels}}{{$value := .Value}}"
+
+// templateData is the data an alert template is executed with
+type templateData struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// checkTemplate reports whether a label or annotation template parses
+func checkTemplate(name, text string) error {
+	if _, err := template.New(name).Option("missingkey=zero").Parse(templateDefs + text); err != nil {
+		return fmt.Errorf("invalid template for %q: %w", name, err)
+	}
+	return nil
+}
+
+// expandTemplate executes a label or annotation template. Errors are
+// returned in the expanded text so they show up on the alert.
+func expandTemplate(name, text string, labels map[string]string, value float64) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(templateDefs + text)
+	if err != nil {
+		return fmt.Sprintf("<error expanding template: %v>", err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, templateData{Labels: labels, Value: value}); err != nil {
+		return fmt.Sprintf("<error expanding template: %v>", err)
+	}
+	return b.String()
+}
@@ -0,0 +1,453 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+)
+
+// UnitTestFile is the content of a rule unit test file
+type UnitTestFile struct {
+	// RuleFiles lists glob patterns of the rule files under test, relative
+	// to the test file
+	RuleFiles []string `yaml:"rule_files"`
+	// EvaluationInterval is how often rules are evaluated during a test
+	EvaluationInterval time.Duration `yaml:"evaluation_interval,omitempty"`
+	Tests              []TestGroup   `yaml:"tests"`
+}
+
+// TestGroup is a set of input series and the assertions made against them
+type TestGroup struct {
+	Name string `yaml:"name,omitempty"`
+	// Interval is the time between two values of an input series
+	Interval       time.Duration   `yaml:"interval,omitempty"`
+	InputSeries    []InputSeries   `yaml:"input_series"`
+	AlertRuleTests []AlertTestCase `yaml:"alert_rule_test,omitempty"`
+	ExprTests      []ExprTestCase  `yaml:"promql_expr_test,omitempty"`
+}
+
+// InputSeries is a series and its values in expanding notation, such as
+// 'up{job="api"}' with '1 1 0x3 0+10x100'
+type InputSeries struct {
+	Series string `yaml:"series"`
+	Values string `yaml:"values"`
+}
+
+// AlertTestCase asserts the alerts of an alerting rule firing at a time
+type AlertTestCase struct {
+	EvalTime  time.Duration   `yaml:"eval_time"`
+	Alertname string          `yaml:"alertname"`
+	ExpAlerts []ExpectedAlert `yaml:"exp_alerts"`
+}
+
+// ExpectedAlert is the labels and annotations of an expected alert. The
+// alertname label is added automatically.
+type ExpectedAlert struct {
+	ExpLabels      map[string]string `yaml:"exp_labels"`
+	ExpAnnotations map[string]string `yaml:"exp_annotations"`
+}
+
+// ExprTestCase asserts the result of an expression at a time
+type ExprTestCase struct {
+	Expr       string           `yaml:"expr"`
+	EvalTime   time.Duration    `yaml:"eval_time"`
+	ExpSamples []ExpectedSample `yaml:"exp_samples"`
+}
+
+// ExpectedSample is an expected sample of an expression result. Labels is
+// written in series notation and is empty for scalar results.
+type ExpectedSample struct {
+	Labels string  `yaml:"labels"`
+	Value  float64 `yaml:"value"`
+}
+
+// RunUnitTests runs the rule unit tests of every file, writing the results
+// to out. It reports whether all tests passed.
+func RunUnitTests(out io.Writer, files ...string) bool {
+	passed := true
+	for _, file := range files {
+		fmt.Fprintf(out, "Unit Testing: %s\n", file)
+
+		errs := runUnitTestFile(file)
+		if len(errs) == 0 {
+			fmt.Fprintf(out, "  SUCCESS\n\n")
+			continue
+		}
+
+		passed = false
+		fmt.Fprintf(out, "  FAILED:\n")
+		for _, err := range errs {
+			fmt.Fprintf(out, "%s\n", indent(err.Error(), "    "))
+		}
+		fmt.Fprintln(out)
+	}
+	return passed
+}
+
+// runUnitTestFile runs the tests of a single file
+func runUnitTestFile(file string) []error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return []error{fmt.Errorf("failed to read test file: %w", err)}
+	}
+
+	var utf UnitTestFile
+	if err := yaml.Unmarshal(data, &utf); err != nil {
+		return []error{fmt.Errorf("failed to parse test file: %w", err)}
+	}
+	if utf.EvaluationInterval == 0 {
+		utf.EvaluationInterval = DefaultEvaluationInterval
+	}
+
+	ruleFiles := make([]string, len(utf.RuleFiles))
+	for i, pattern := range utf.RuleFiles {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(file), pattern)
+		}
+		ruleFiles[i] = pattern
+	}
+
+	var errs []error
+	for i, tg := range utf.Tests {
+		name := tg.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		for _, err := range tg.run(ruleFiles, utf.EvaluationInterval) {
+			errs = append(errs, fmt.Errorf("test %s: %w", name, err))
+		}
+	}
+	return errs
+}
+
+// testStart is the time the first value of every input series is at
+var testStart = time.Unix(0, 0).UTC()
+
+// run loads the input series and the rules, evaluates the rules every
+// evalInterval and checks each test case after the last evaluation at or
+// before its eval_time
+func (tg *TestGroup) run(ruleFiles []string, evalInterval time.Duration) []error {
+	interval := tg.Interval
+	if interval == 0 {
+		interval = evalInterval
+	}
+
+	inputs, err := tg.parseInputSeries(interval)
+	if err != nil {
+		return []error{err}
+	}
+
+	// Keep the whole test in the sample history
+	maxEval := time.Duration(0)
+	for _, tc := range tg.AlertRuleTests {
+		maxEval = max(maxEval, tc.EvalTime)
+	}
+	for _, tc := range tg.ExprTests {
+		maxEval = max(maxEval, tc.EvalTime)
+	}
+	span := maxEval
+	for _, m := range inputs {
+		span = max(span, m.Timestamp.Sub(testStart))
+	}
+
+	registry := metrics.NewMetricRegistry()
+	registry.SetRetention(max(span, metrics.DefaultRetention))
+	for _, m := range inputs {
+		registry.Register(m)
+	}
+
+	engine := query.NewEngine(registry)
+	manager := NewManager(engine, registry)
+	if err := manager.LoadGroups(ruleFiles); err != nil {
+		return []error{err}
+	}
+
+	alertTests := append([]AlertTestCase(nil), tg.AlertRuleTests...)
+	sort.SliceStable(alertTests, func(i, j int) bool { return alertTests[i].EvalTime < alertTests[j].EvalTime })
+	exprTests := append([]ExprTestCase(nil), tg.ExprTests...)
+	sort.SliceStable(exprTests, func(i, j int) bool { return exprTests[i].EvalTime < exprTests[j].EvalTime })
+
+	var errs []error
+	for ts := time.Duration(0); ts <= maxEval; ts += evalInterval {
+		for _, g := range manager.Groups() {
+			g.Eval(context.Background(), engine, registry, testStart.Add(ts))
+		}
+
+		for len(alertTests) > 0 && alertTests[0].EvalTime < ts+evalInterval {
+			if err := alertTests[0].check(manager); err != nil {
+				errs = append(errs, err)
+			}
+			alertTests = alertTests[1:]
+		}
+		for len(exprTests) > 0 && exprTests[0].EvalTime < ts+evalInterval {
+			if err := exprTests[0].check(engine); err != nil {
+				errs = append(errs, err)
+			}
+			exprTests = exprTests[1:]
+		}
+	}
+	return errs
+}
+
+// parseInputSeries expands the input series into samples spaced interval
+// apart, oldest first
+func (tg *TestGroup) parseInputSeries(interval time.Duration) ([]*metrics.Metric, error) {
+	var samples []*metrics.Metric
+	for _, in := range tg.InputSeries {
+		labels, err := parseSeriesLabels(in.Series)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input series %q: %w", in.Series, err)
+		}
+		name, rest := query.SplitLabels(labels)
+		if name == "" {
+			return nil, fmt.Errorf("invalid input series %q: missing metric name", in.Series)
+		}
+
+		values, err := ParseSeriesValues(in.Values)
+		if err != nil {
+			return nil, fmt.Errorf("invalid values of input series %q: %w", in.Series, err)
+		}
+		for i, v := range values {
+			if v.Omitted {
+				continue
+			}
+			samples = append(samples, &metrics.Metric{
+				Name:      name,
+				Type:      metrics.MetricTypeGauge,
+				Value:     v.Value,
+				Labels:    rest,
+				Timestamp: testStart.Add(time.Duration(i) * interval),
+			})
+		}
+	}
+	return samples, nil
+}
+
+// check compares the firing alerts of the rule named Alertname with the
+// expected alerts
+func (tc *AlertTestCase) check(manager *Manager) error {
+	var got []string
+	for _, rule := range manager.AlertingRules() {
+		if rule.Name() != tc.Alertname {
+			continue
+		}
+		for _, a := range rule.ActiveAlerts() {
+			if a.State == StateFiring {
+				got = append(got, alertString(a.Labels, a.Annotations))
+			}
+		}
+	}
+
+	exp := make([]string, 0, len(tc.ExpAlerts))
+	for _, ea := range tc.ExpAlerts {
+		labels := make(map[string]string, len(ea.ExpLabels)+1)
+		for k, v := range ea.ExpLabels {
+			labels[k] = v
+		}
+		labels[AlertNameLabel] = tc.Alertname
+		exp = append(exp, alertString(labels, ea.ExpAnnotations))
+	}
+
+	sort.Strings(got)
+	sort.Strings(exp)
+	if strings.Join(got, "\n") != strings.Join(exp, "\n") {
+		return fmt.Errorf("alertname: %s, time: %s,\n    exp: %s,\n    got: %s",
+			tc.Alertname, tc.EvalTime, listString(exp), listString(got))
+	}
+	return nil
+}
+
+// alertString formats the labels and annotations of an alert for comparison
+func alertString(labels, annotations map[string]string) string {
+	return fmt.Sprintf("Labels:%s Annotations:%s", query.LabelsString(labels), query.LabelsString(annotations))
+}
+
+// check evaluates the expression and compares the result with the expected
+// samples
+func (tc *ExprTestCase) check(engine *query.Engine) error {
+	v, err := engine.Instant(context.Background(), tc.Expr, testStart.Add(tc.EvalTime))
+	if err != nil {
+		return fmt.Errorf("expr: %q, time: %s, err: %v", tc.Expr, tc.EvalTime, err)
+	}
+
+	got := map[string]float64{}
+	switch v := v.(type) {
+	case query.Vector:
+		for _, s := range v {
+			got[query.LabelsString(s.Labels)] = s.V
+		}
+	case query.Scalar:
+		got[query.LabelsString(nil)] = v.V
+	default:
+		return fmt.Errorf("expr: %q, time: %s, err: expression returned %s, expected scalar or vector", tc.Expr, tc.EvalTime, v.Type())
+	}
+
+	exp := map[string]float64{}
+	for _, es := range tc.ExpSamples {
+		labels, err := parseSeriesLabels(es.Labels)
+		if err != nil {
+			return fmt.Errorf("expr: %q, time: %s, err: invalid expected labels %q: %v", tc.Expr, tc.EvalTime, es.Labels, err)
+		}
+		exp[query.LabelsString(labels)] = es.Value
+	}
+
+	match := len(got) == len(exp)
+	for k, ev := range exp {
+		gv, ok := got[k]
+		if !ok || !almostEqual(ev, gv) {
+			match = false
+		}
+	}
+	if !match {
+		return fmt.Errorf("expr: %q, time: %s,\n    exp: %s,\n    got: %s",
+			tc.Expr, tc.EvalTime, samplesString(exp), samplesString(got))
+	}
+	return nil
+}
+
+// samplesString formats samples keyed by their labels, ordered by labels
+func samplesString(samples map[string]float64) string {
+	list := make([]string, 0, len(samples))
+	for k, v := range samples {
+		list = append(list, k+" "+strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	sort.Strings(list)
+	return listString(list)
+}
+
+// listString formats a list of items for test failure messages
+func listString(items []string) string {
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// almostEqual compares floats allowing for rounding errors
+func almostEqual(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	if a == b {
+		return true
+	}
+	return math.Abs(a-b) <= 1e-9*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// parseSeriesLabels parses a series such as up{job="api"} into its label
+// set, including __name__. An empty string or {} is the empty label set.
+func parseSeriesLabels(s string) (map[string]string, error) {
+	labels := map[string]string{}
+	if s = strings.TrimSpace(s); s == "" || s == "{}" {
+		return labels, nil
+	}
+
+	matchers, err := query.ParseSelector(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range matchers {
+		if m.Type != metrics.MatchEqual {
+			return nil, fmt.Errorf("only equality matchers are allowed in a series, got %s", m)
+		}
+		labels[m.Name] = m.Value
+	}
+	return labels, nil
+}
+
+// SequenceValue is one value of an expanded series. Omitted values have no
+// sample.
+type SequenceValue struct {
+	Value   float64
+	Omitted bool
+}
+
+// ParseSeriesValues expands a space separated list of values in expanding
+// notation: 'a+bxn' and 'a-bxn' are the n+1 values a, a±b, ..., a±n*b,
+// 'axn' repeats a n+1 times, '_' is a missing value and '_xn' is n missing
+// values
+func ParseSeriesValues(s string) ([]SequenceValue, error) {
+	var values []SequenceValue
+	for _, field := range strings.Fields(s) {
+		if field == "_" {
+			values = append(values, SequenceValue{Omitted: true})
+			continue
+		}
+
+		x := strings.LastIndexByte(field, 'x')
+		if x < 0 {
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", field)
+			}
+			values = append(values, SequenceValue{Value: v})
+			continue
+		}
+
+		n, err := strconv.Atoi(field[x+1:])
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid repetition count in %q", field)
+		}
+		base := field[:x]
+		if base == "_" {
+			for i := 0; i < n; i++ {
+				values = append(values, SequenceValue{Omitted: true})
+			}
+			continue
+		}
+
+		start, step, err := parseExpandingBase(base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q: %w", field, err)
+		}
+		for i := 0; i <= n; i++ {
+			values = append(values, SequenceValue{Value: start + float64(i)*step})
+		}
+	}
+	return values, nil
+}
+
+// parseExpandingBase parses the 'a', 'a+b' or 'a-b' part of an expanding
+// value into its start value and step
+func parseExpandingBase(base string) (float64, float64, error) {
+	// Find the operator, skipping a leading sign and exponent signs
+	op := -1
+	for i := 1; i < len(base); i++ {
+		if (base[i] == '+' || base[i] == '-') && base[i-1] != 'e' && base[i-1] != 'E' {
+			op = i
+			break
+		}
+	}
+
+	if op < 0 {
+		start, err := strconv.ParseFloat(base, 64)
+		return start, 0, err
+	}
+
+	start, err := strconv.ParseFloat(base[:op], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	step, err := strconv.ParseFloat(base[op+1:], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if base[op] == '-' {
+		step = -step
+	}
+	return start, step, nil
+}
+
+// indent prefixes every line of s
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
@@ -0,0 +1,179 @@
+package rules
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseSeriesValues(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []float64
+		omitted  []int
+	}{
+		{input: "1 2 3", expected: []float64{1, 2, 3}},
+		{input: "0+10x3", expected: []float64{0, 10, 20, 30}},
+		{input: "10-2x2", expected: []float64{10, 8, 6}},
+		{input: "-1+1x2", expected: []float64{-1, 0, 1}},
+		{input: "5x2", expected: []float64{5, 5, 5}},
+		{input: "1e3+1e-3x1", expected: []float64{1000, 1000.001}},
+		{input: "1 _ 3", expected: []float64{1, 0, 3}, omitted: []int{1}},
+		{input: "1 _x2 4", expected: []float64{1, 0, 0, 4}, omitted: []int{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			values, err := ParseSeriesValues(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(values) != len(tt.expected) {
+				t.Fatalf("Expected %d values, got %d", len(tt.expected), len(values))
+			}
+
+			omitted := map[int]bool{}
+			for _, i := range tt.omitted {
+				omitted[i] = true
+			}
+			for i, v := range values {
+				if v.Omitted != omitted[i] {
+					t.Errorf("Value %d: expected omitted=%v, got %v", i, omitted[i], v.Omitted)
+				}
+				if !v.Omitted && !almostEqual(v.Value, tt.expected[i]) {
+					t.Errorf("Value %d: expected %v, got %v", i, tt.expected[i], v.Value)
+				}
+			}
+		})
+	}
+
+	for _, input := range []string{"abc", "1+x3", "1+1xa", "1+1x-1"} {
+		if _, err := ParseSeriesValues(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestRunUnitTests(t *testing.T) {
+	dir := t.TempDir()
+	writeRuleFile(t, dir, "rules.yml", `groups:
+  - name: example
+    rules:
+      - record: job:http_requests:rate5m
+        expr: sum by (job) (rate(http_requests_total[5m]))
+      - alert: InstanceDown
+        expr: up == 0
+        for: 5m
+        labels:
+          severity: page
+        annotations:
+          summary: 'Instance {{ $labels.instance }} down'
+`)
+
+	t.Run("Passing tests", func(t *testing.T) {
+		path := writeRuleFile(t, dir, "pass_test.yml", `rule_files:
+  - rules.yml
+evaluation_interval: 1m
+tests:
+  - interval: 1m
+    input_series:
+      - series: 'up{job="api", instance="a"}'
+        values: '1 1 0x10'
+      - series: 'up{job="api", instance="b"}'
+        values: '1x12'
+      - series: 'http_requests_total{job="api", instance="a"}'
+        values: '0+60x20'
+    alert_rule_test:
+      - eval_time: 5m
+        alertname: InstanceDown
+        exp_alerts: []
+      - eval_time: 8m
+        alertname: InstanceDown
+        exp_alerts:
+          - exp_labels:
+              severity: page
+              job: api
+              instance: a
+            exp_annotations:
+              summary: 'Instance a down'
+    promql_expr_test:
+      - expr: job:http_requests:rate5m
+        eval_time: 10m
+        exp_samples:
+          - labels: 'job:http_requests:rate5m{job="api"}'
+            value: 1
+      - expr: count(up)
+        eval_time: 1m
+        exp_samples:
+          - labels: '{}'
+            value: 2
+      - expr: scalar(up{instance="b"}) * 3
+        eval_time: 2m
+        exp_samples:
+          - value: 3
+`)
+
+		var out bytes.Buffer
+		if !RunUnitTests(&out, path) {
+			t.Fatalf("Expected tests to pass, got:\n%s", out.String())
+		}
+		if !strings.Contains(out.String(), "SUCCESS") {
+			t.Errorf("Expected SUCCESS in output, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("Failing tests", func(t *testing.T) {
+		path := writeRuleFile(t, dir, "fail_test.yml", `rule_files:
+  - rules.yml
+tests:
+  - name: down
+    input_series:
+      - series: 'up{job="api", instance="a"}'
+        values: '0x10'
+    alert_rule_test:
+      - eval_time: 2m
+        alertname: InstanceDown
+        exp_alerts:
+          - exp_labels:
+              severity: page
+              job: api
+              instance: a
+    promql_expr_test:
+      - expr: up
+        eval_time: 1m
+        exp_samples:
+          - labels: 'up{job="api", instance="a"}'
+            value: 1
+`)
+
+		var out bytes.Buffer
+		if RunUnitTests(&out, path) {
+			t.Fatalf("Expected tests to fail, got:\n%s", out.String())
+		}
+		for _, want := range []string{
+			"FAILED",
+			"test down: alertname: InstanceDown, time: 2m0s",
+			`test down: expr: "up", time: 1m0s`,
+			`got: [{__name__="up", instance="a", job="api"} 0]`,
+		} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+			}
+		}
+	})
+
+	t.Run("Invalid input series", func(t *testing.T) {
+		path := writeRuleFile(t, dir, "invalid_test.yml", `rule_files:
+  - rules.yml
+tests:
+  - input_series:
+      - series: 'up{job="api"'
+        values: '1'
+`)
+
+		var out bytes.Buffer
+		if RunUnitTests(&out, path) {
+			t.Fatalf("Expected invalid input series to fail, got:\n%s", out.String())
+		}
+	})
+}
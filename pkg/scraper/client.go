@@ -0,0 +1,124 @@
+package scraper
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+)
+
+// newHTTPClient builds an HTTP client for a scrape job from its TLS,
+// authentication and proxy settings
+func newHTTPClient(cfg config.ScrapeConfig, timeout time.Duration) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(cfg.TLSConfig)
+	if err != nil {
+		return nil, fmt.Errorf("job %q: %w", cfg.JobName, err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("job %q: invalid proxy_url: %w", cfg.JobName, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	var rt http.RoundTripper = transport
+	if cfg.BasicAuth != nil {
+		rt = &basicAuthRoundTripper{auth: *cfg.BasicAuth, next: rt}
+	}
+	if cfg.Authorization != nil {
+		rt = &authorizationRoundTripper{auth: *cfg.Authorization, next: rt}
+	}
+
+	return &http.Client{
+		Transport: rt,
+		Timeout:   timeout,
+	}, nil
+}
+
+// newTLSConfig converts a scrape TLS config into a crypto/tls config
+func newTLSConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		caPEM, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, fmt.Errorf("cert_file and key_file must be set together")
+	}
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// basicAuthRoundTripper adds HTTP basic authentication to each request
+type basicAuthRoundTripper struct {
+	auth config.BasicAuth
+	next http.RoundTripper
+}
+
+func (rt *basicAuthRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	password := rt.auth.Password
+	if rt.auth.PasswordFile != "" {
+		// Read on every request so rotated secrets are picked up
+		data, err := os.ReadFile(rt.auth.PasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read password file: %w", err)
+		}
+		password = strings.TrimSpace(string(data))
+	}
+
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(rt.auth.Username, password)
+	return rt.next.RoundTrip(req)
+}
+
+// authorizationRoundTripper sets the Authorization header on each request
+type authorizationRoundTripper struct {
+	auth config.Authorization
+	next http.RoundTripper
+}
+
+func (rt *authorizationRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	credentials := rt.auth.Credentials
+	if rt.auth.CredentialsFile != "" {
+		// Read on every request so rotated tokens are picked up
+		data, err := os.ReadFile(rt.auth.CredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credentials file: %w", err)
+		}
+		credentials = strings.TrimSpace(string(data))
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", rt.auth.Type+" "+credentials)
+	return rt.next.RoundTrip(req)
+}
@@ -0,0 +1,315 @@
+package scraper
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+)
+
+// writeCAFile writes the certificate of a TLS test server to a temporary PEM file
+func writeCAFile(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+
+	caFile, err := os.CreateTemp("", "ca-*.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer caFile.Close()
+	t.Cleanup(func() { os.Remove(caFile.Name()) })
+
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}
+	if err := pem.Encode(caFile, block); err != nil {
+		t.Fatal(err)
+	}
+	return caFile.Name()
+}
+
+func TestScrapeTargetTLSAndAuth(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		switch {
+		case r.Header.Get("Authorization") == "Bearer secret-token":
+		case ok && user == "admin" && pass == "hunter2":
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintln(w, "# TYPE up_metric gauge")
+		fmt.Fprintln(w, "up_metric 1")
+	}))
+	defer srv.Close()
+
+	target := strings.TrimPrefix(srv.URL, "https://")
+	caFile := writeCAFile(t, srv)
+
+	instance := target
+	scrape := func(t *testing.T, cfg config.ScrapeConfig) *metrics.MetricRegistry {
+		t.Helper()
+		cfg.JobName = "tls-job"
+		cfg.Scheme = "https"
+
+		registry := metrics.NewMetricRegistry()
+		s, err := NewScraper(&config.Config{ScrapeConfigs: []config.ScrapeConfig{cfg}}, registry)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		s.scrapeTarget(context.Background(), cfg, target, nil)
+		return registry
+	}
+
+	t.Run("Bearer token with CA file", func(t *testing.T) {
+		registry := scrape(t, config.ScrapeConfig{
+			TLSConfig:     config.TLSConfig{CAFile: caFile},
+			Authorization: &config.Authorization{Type: "Bearer", Credentials: "secret-token"},
+		})
+
+		if findMetric(registry, "up_metric") == nil {
+			t.Error("Expected up_metric to be scraped")
+		}
+		if up := findMetric(registry, "up"); up == nil || up.Value != 1 || up.Labels["instance"] != instance {
+			t.Errorf("Expected up=1 for %s, got %+v", instance, up)
+		}
+	})
+
+	t.Run("Bearer token from credentials file", func(t *testing.T) {
+		tokenFile, err := os.CreateTemp("", "token-*")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(tokenFile.Name())
+		tokenFile.WriteString("secret-token\n")
+		tokenFile.Close()
+
+		registry := scrape(t, config.ScrapeConfig{
+			TLSConfig:     config.TLSConfig{CAFile: caFile},
+			Authorization: &config.Authorization{Type: "Bearer", CredentialsFile: tokenFile.Name()},
+		})
+
+		if findMetric(registry, "up_metric") == nil {
+			t.Error("Expected up_metric to be scraped")
+		}
+		if up := findMetric(registry, "up"); up == nil || up.Value != 1 || up.Labels["instance"] != instance {
+			t.Errorf("Expected up=1 for %s, got %+v", instance, up)
+		}
+	})
+
+	t.Run("Basic auth with insecure skip verify", func(t *testing.T) {
+		registry := scrape(t, config.ScrapeConfig{
+			TLSConfig: config.TLSConfig{InsecureSkipVerify: true},
+			BasicAuth: &config.BasicAuth{Username: "admin", Password: "hunter2"},
+		})
+
+		if findMetric(registry, "up_metric") == nil {
+			t.Error("Expected up_metric to be scraped")
+		}
+		if up := findMetric(registry, "up"); up == nil || up.Value != 1 || up.Labels["instance"] != instance {
+			t.Errorf("Expected up=1 for %s, got %+v", instance, up)
+		}
+	})
+
+	t.Run("Wrong credentials are rejected", func(t *testing.T) {
+		registry := scrape(t, config.ScrapeConfig{
+			TLSConfig: config.TLSConfig{CAFile: caFile},
+			BasicAuth: &config.BasicAuth{Username: "admin", Password: "wrong"},
+		})
+
+		if findMetric(registry, "up_metric") != nil {
+			t.Error("Expected up_metric not to be scraped")
+		}
+		if up := findMetric(registry, "up"); up == nil || up.Value != 0 {
+			t.Errorf("Expected up=0, got %+v", up)
+		}
+	})
+
+	t.Run("Untrusted certificate is rejected", func(t *testing.T) {
+		registry := scrape(t, config.ScrapeConfig{
+			Authorization: &config.Authorization{Type: "Bearer", Credentials: "secret-token"},
+		})
+
+		if findMetric(registry, "up_metric") != nil {
+			t.Error("Expected up_metric not to be scraped")
+		}
+		if up := findMetric(registry, "up"); up == nil || up.Value != 0 {
+			t.Errorf("Expected up=0, got %+v", up)
+		}
+	})
+}
+
+// writeClientCert writes a self-signed client certificate and its key to a
+// temporary directory, returning the certificate and both file paths
+func writeClientCert(t *testing.T) (*x509.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "scraper"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return cert, certFile, keyFile
+}
+
+func TestScrapeTargetClientCert(t *testing.T) {
+	clientCert, certFile, keyFile := writeClientCert(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "# TYPE up_metric gauge")
+		fmt.Fprintln(w, "up_metric 1")
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	defer srv.Close()
+
+	target := strings.TrimPrefix(srv.URL, "https://")
+	caFile := writeCAFile(t, srv)
+
+	scrape := func(t *testing.T, tlsConfig config.TLSConfig) *metrics.MetricRegistry {
+		t.Helper()
+		cfg := config.ScrapeConfig{JobName: "mtls-job", Scheme: "https", TLSConfig: tlsConfig}
+
+		registry := metrics.NewMetricRegistry()
+		s, err := NewScraper(&config.Config{ScrapeConfigs: []config.ScrapeConfig{cfg}}, registry)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		s.scrapeTarget(context.Background(), cfg, target, nil)
+		return registry
+	}
+
+	t.Run("Client certificate is sent", func(t *testing.T) {
+		registry := scrape(t, config.TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile})
+
+		if findMetric(registry, "up_metric") == nil {
+			t.Error("Expected up_metric to be scraped")
+		}
+		if up := findMetric(registry, "up"); up == nil || up.Value != 1 {
+			t.Errorf("Expected up=1, got %+v", up)
+		}
+	})
+
+	t.Run("Missing client certificate is rejected", func(t *testing.T) {
+		registry := scrape(t, config.TLSConfig{CAFile: caFile})
+
+		if findMetric(registry, "up_metric") != nil {
+			t.Error("Expected up_metric not to be scraped")
+		}
+		if up := findMetric(registry, "up"); up == nil || up.Value != 0 {
+			t.Errorf("Expected up=0, got %+v", up)
+		}
+	})
+}
+
+func TestScrapeTargetProxy(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.String())
+		mu.Unlock()
+		fmt.Fprintln(w, "# TYPE up_metric gauge")
+		fmt.Fprintln(w, "up_metric 1")
+	}))
+	defer proxy.Close()
+
+	// The target does not resolve, so it can only be reached through the proxy
+	cfg := config.ScrapeConfig{JobName: "proxy-job", Scheme: "http", MetricsPath: "/metrics", ProxyURL: proxy.URL}
+	registry := metrics.NewMetricRegistry()
+	s, err := NewScraper(&config.Config{ScrapeConfigs: []config.ScrapeConfig{cfg}}, registry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	s.scrapeTarget(context.Background(), cfg, "target.invalid:9100", nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 1 || requests[0] != "http://target.invalid:9100/metrics" {
+		t.Errorf("Expected one proxied request for http://target.invalid:9100/metrics, got %v", requests)
+	}
+	if findMetric(registry, "up_metric") == nil {
+		t.Error("Expected up_metric to be scraped")
+	}
+	if up := findMetric(registry, "up"); up == nil || up.Value != 1 {
+		t.Errorf("Expected up=1, got %+v", up)
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	t.Run("Missing CA file", func(t *testing.T) {
+		_, err := newHTTPClient(config.ScrapeConfig{
+			JobName:   "bad-ca",
+			TLSConfig: config.TLSConfig{CAFile: "/nonexistent/ca.pem"},
+		}, time.Second)
+		if err == nil {
+			t.Error("Expected error for missing CA file")
+		}
+	})
+
+	t.Run("Cert without key", func(t *testing.T) {
+		_, err := newHTTPClient(config.ScrapeConfig{
+			JobName:   "bad-cert",
+			TLSConfig: config.TLSConfig{CertFile: "client.pem"},
+		}, time.Second)
+		if err == nil {
+			t.Error("Expected error when key_file is missing")
+		}
+	})
+
+	t.Run("Invalid proxy URL", func(t *testing.T) {
+		_, err := newHTTPClient(config.ScrapeConfig{
+			JobName:  "bad-proxy",
+			ProxyURL: "://bad",
+		}, time.Second)
+		if err == nil {
+			t.Error("Expected error for invalid proxy_url")
+		}
+	})
+}
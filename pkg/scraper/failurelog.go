@@ -0,0 +1,48 @@
+package scraper
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// failureLogFiles holds the open scrape failure log files by path, so jobs
+// logging to the same file share it
+type failureLogFiles map[string]*os.File
+
+// logger returns a logger appending JSON lines to the file at path, opening
+// the file if needed
+func (f failureLogFiles) logger(path string) (*slog.Logger, error) {
+	file, ok := f[path]
+	if !ok {
+		var err error
+		file, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open scrape failure log file: %w", err)
+		}
+		f[path] = file
+	}
+	return slog.New(slog.NewJSONHandler(file, nil)), nil
+}
+
+// closeExcept closes and removes the files that are not in keep
+func (f failureLogFiles) closeExcept(keep failureLogFiles) {
+	for path, file := range f {
+		if _, ok := keep[path]; !ok {
+			file.Close()
+			delete(f, path)
+		}
+	}
+}
+
+// logFailure appends a failed scrape to the failure log of its job, if any
+func (s *Scraper) logFailure(jobName, target string, duration time.Duration, err error) {
+	s.mu.RLock()
+	logger := s.failureLogs[jobName]
+	s.mu.RUnlock()
+
+	if logger != nil {
+		logger.Error("Scrape failed", "job", jobName, "target", target, "duration", duration, "err", err)
+	}
+}
@@ -2,15 +2,23 @@ package scraper
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
+	"hash/fnv"
 	"io"
+	"log/slog"
+	"maps"
 	"net/http"
+	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/instrument"
 	"github.com/Avinash7390/Promenitheus/pkg/metrics"
 )
 
@@ -18,95 +26,457 @@ import (
 type Scraper struct {
 	config   *config.Config
 	registry *metrics.MetricRegistry
-	client   *http.Client
+
+	mu          sync.RWMutex
+	clients     map[string]*http.Client
+	failureLogs map[string]*slog.Logger
+	targets     map[string]*TargetStatus
+
+	// loopsMu serializes starting and stopping scrape loops
+	loopsMu         sync.Mutex
+	ctx             context.Context
+	loops           map[string]*jobLoop
+	failureLogFiles failureLogFiles
+
+	scrapeDuration *instrument.HistogramVec
+	scrapeFailures *instrument.CounterVec
+}
+
+// jobLoop is the running scrape loop of a job
+type jobLoop struct {
+	cfg    config.ScrapeConfig
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
-// NewScraper creates a new scraper
-func NewScraper(cfg *config.Config, registry *metrics.MetricRegistry) *Scraper {
+// NewScraper creates a new scraper with an HTTP client and, if configured,
+// a scrape failure log per scrape job
+func NewScraper(cfg *config.Config, registry *metrics.MetricRegistry) (*Scraper, error) {
+	clients := make(map[string]*http.Client, len(cfg.ScrapeConfigs))
+	failureLogs := make(map[string]*slog.Logger)
+	files := failureLogFiles{}
+	targets := make(map[string]*TargetStatus)
+	for _, scrapeConfig := range cfg.ScrapeConfigs {
+		client, err := newHTTPClient(scrapeConfig, scrapeConfig.ScrapeTimeout)
+		if err != nil {
+			files.closeExcept(nil)
+			return nil, err
+		}
+		clients[scrapeConfig.JobName] = client
+		if path := scrapeConfig.ScrapeFailureLogFile; path != "" {
+			logger, err := files.logger(path)
+			if err != nil {
+				files.closeExcept(nil)
+				return nil, fmt.Errorf("job %q: %w", scrapeConfig.JobName, err)
+			}
+			failureLogs[scrapeConfig.JobName] = logger
+		}
+		addTargets(targets, scrapeConfig)
+	}
+
 	return &Scraper{
-		config:   cfg,
-		registry: registry,
-		client: &http.Client{
-			Timeout: cfg.Global.ScrapeTimeout,
-		},
+		config:          cfg,
+		registry:        registry,
+		clients:         clients,
+		failureLogs:     failureLogs,
+		targets:         targets,
+		loops:           make(map[string]*jobLoop),
+		failureLogFiles: files,
+		scrapeDuration: instrument.NewHistogramVec("promenitheus_scrape_duration_seconds",
+			"Duration of scrapes by job.", instrument.DefBuckets, "job"),
+		scrapeFailures: instrument.NewCounterVec("promenitheus_scrape_failures_total",
+			"Failed scrapes by job and reason.", "job", "reason"),
+	}, nil
+}
+
+// RegisterMetrics adds the scraper's own metrics to a registry
+func (s *Scraper) RegisterMetrics(reg *instrument.Registry) {
+	reg.MustRegister(s.scrapeDuration, s.scrapeFailures)
+}
+
+// addTargets adds the status of every target of a job with unknown health
+func addTargets(targets map[string]*TargetStatus, cfg config.ScrapeConfig) {
+	for _, staticConfig := range cfg.StaticConfigs {
+		for _, target := range staticConfig.Targets {
+			targets[targetKey(cfg.JobName, target)] = &TargetStatus{
+				Job:       cfg.JobName,
+				Instance:  target,
+				ScrapeURL: scrapeURL(cfg, target),
+				Labels:    staticConfig.Labels,
+				Health:    HealthUnknown,
+			}
+		}
 	}
 }
 
 // Start begins scraping metrics from all configured targets
 func (s *Scraper) Start(ctx context.Context) {
+	s.loopsMu.Lock()
+	defer s.loopsMu.Unlock()
+
+	s.ctx = ctx
 	for _, scrapeConfig := range s.config.ScrapeConfigs {
-		go s.runScrapeLoop(ctx, scrapeConfig)
+		s.startJob(scrapeConfig)
+	}
+}
+
+// startJob starts the scrape loop of a job. Must be called with s.loopsMu
+// held.
+func (s *Scraper) startJob(cfg config.ScrapeConfig) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	loop := &jobLoop{cfg: cfg, cancel: cancel, done: make(chan struct{})}
+	s.loops[cfg.JobName] = loop
+
+	go func() {
+		defer close(loop.done)
+		s.runScrapeLoop(ctx, cfg)
+	}()
+}
+
+// ApplyConfig switches the scraper to a new configuration. Jobs whose
+// configuration is unchanged keep scraping without interruption, removed
+// jobs are stopped and new or changed jobs are (re)started. If a client for
+// a new or changed job or its scrape failure log cannot be created nothing
+// is changed.
+func (s *Scraper) ApplyConfig(cfg *config.Config) error {
+	s.loopsMu.Lock()
+	defer s.loopsMu.Unlock()
+
+	current := make(map[string]config.ScrapeConfig, len(s.config.ScrapeConfigs))
+	for _, sc := range s.config.ScrapeConfigs {
+		current[sc.JobName] = sc
+	}
+
+	// Create the clients and failure logs of new and changed jobs before
+	// stopping anything
+	var started []config.ScrapeConfig
+	clients := make(map[string]*http.Client)
+	failureLogs := make(map[string]*slog.Logger)
+	files := maps.Clone(s.failureLogFiles)
+	for _, sc := range cfg.ScrapeConfigs {
+		if old, ok := current[sc.JobName]; ok && reflect.DeepEqual(old, sc) {
+			delete(current, sc.JobName)
+			continue
+		}
+		client, err := newHTTPClient(sc, sc.ScrapeTimeout)
+		if err != nil {
+			files.closeExcept(s.failureLogFiles)
+			return fmt.Errorf("job %s: %w", sc.JobName, err)
+		}
+		clients[sc.JobName] = client
+		if sc.ScrapeFailureLogFile != "" {
+			logger, err := files.logger(sc.ScrapeFailureLogFile)
+			if err != nil {
+				files.closeExcept(s.failureLogFiles)
+				return fmt.Errorf("job %s: %w", sc.JobName, err)
+			}
+			failureLogs[sc.JobName] = logger
+		}
+		started = append(started, sc)
+	}
+
+	// Jobs left in current were removed or changed
+	for name := range current {
+		if loop, ok := s.loops[name]; ok {
+			loop.cancel()
+			<-loop.done
+			delete(s.loops, name)
+		}
+	}
+
+	s.mu.Lock()
+	for name := range current {
+		delete(s.clients, name)
+		delete(s.failureLogs, name)
+		for key, status := range s.targets {
+			if status.Job == name {
+				delete(s.targets, key)
+			}
+		}
+	}
+	for _, sc := range started {
+		s.clients[sc.JobName] = clients[sc.JobName]
+		if logger, ok := failureLogs[sc.JobName]; ok {
+			s.failureLogs[sc.JobName] = logger
+		}
+		addTargets(s.targets, sc)
+	}
+	s.mu.Unlock()
+
+	// Close the failure log files no job writes to anymore
+	used := failureLogFiles{}
+	for _, sc := range cfg.ScrapeConfigs {
+		if sc.ScrapeFailureLogFile != "" {
+			used[sc.ScrapeFailureLogFile] = files[sc.ScrapeFailureLogFile]
+		}
+	}
+	files.closeExcept(used)
+	s.failureLogFiles = used
+
+	s.config = cfg
+	if s.ctx != nil {
+		for _, sc := range started {
+			s.startJob(sc)
+		}
 	}
+
+	slog.Info("Applied scrape config", "unchanged", len(cfg.ScrapeConfigs)-len(started), "stopped", len(current), "started", len(started))
+	return nil
 }
 
-// runScrapeLoop runs the scrape loop for a specific job
+// runScrapeLoop runs one scrape loop per target of a job and waits for them
+// to finish
 func (s *Scraper) runScrapeLoop(ctx context.Context, cfg config.ScrapeConfig) {
+	var wg sync.WaitGroup
+	for _, staticConfig := range cfg.StaticConfigs {
+		for _, target := range staticConfig.Targets {
+			wg.Add(1)
+			go func(target string, labels map[string]string) {
+				defer wg.Done()
+				s.runTargetLoop(ctx, cfg, target, labels)
+			}(target, staticConfig.Labels)
+		}
+	}
+	wg.Wait()
+}
+
+// runTargetLoop scrapes a single target every interval. The first scrape is
+// delayed by a stable per-target offset so targets of a job are spread over
+// the interval instead of being hit at the same instant. Scrapes of the same
+// target run sequentially and never overlap; ticks missed while a slow scrape
+// was in flight are reported and dropped.
+func (s *Scraper) runTargetLoop(ctx context.Context, cfg config.ScrapeConfig, target string, labels map[string]string) {
+	offset := scrapeOffset(cfg.JobName, target, cfg.ScrapeInterval, time.Now())
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(offset):
+	}
+
 	ticker := time.NewTicker(cfg.ScrapeInterval)
 	defer ticker.Stop()
 
-	// Initial scrape
-	s.scrapeJob(cfg)
-
 	for {
+		start := time.Now()
+		s.scrapeTarget(ctx, cfg, target, labels)
+
+		if missed := int(time.Since(start) / cfg.ScrapeInterval); missed > 0 && ctx.Err() == nil {
+			slog.Warn("Scrape took longer than the scrape interval", "job", cfg.JobName, "target", target,
+				"duration", time.Since(start), "missed", missed)
+		}
+
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			s.scrapeJob(cfg)
 		}
 	}
 }
 
-// scrapeJob scrapes all targets in a job
-func (s *Scraper) scrapeJob(cfg config.ScrapeConfig) {
-	for _, staticConfig := range cfg.StaticConfigs {
-		for _, target := range staticConfig.Targets {
-			go s.scrapeTarget(target, cfg.JobName, staticConfig.Labels)
+// scrapeOffset returns how long to wait from now until the target's first
+// scrape. Each target gets a phase within the interval derived from a hash of
+// its job and address, so the schedule is stable across restarts.
+func scrapeOffset(jobName, target string, interval time.Duration, now time.Time) time.Duration {
+	h := fnv.New64a()
+	h.Write([]byte(jobName))
+	h.Write([]byte{0})
+	h.Write([]byte(target))
+
+	phase := time.Duration(h.Sum64() % uint64(interval))
+	base := time.Duration(now.UnixNano() % int64(interval))
+	return (phase - base + interval) % interval
+}
+
+// scrapeTarget scrapes metrics from a single target. The request is bound to
+// ctx so in-flight scrapes are abandoned on shutdown. Samples are only written
+// to the registry if the whole scrape succeeds; either way the target's up
+// series and status are updated.
+func (s *Scraper) scrapeTarget(ctx context.Context, cfg config.ScrapeConfig, target string, labels map[string]string) {
+	start := time.Now()
+	scraped, err := s.scrape(ctx, cfg, target, labels)
+	duration := time.Since(start)
+
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down; don't record a failure for an abandoned scrape
+			return
 		}
+		slog.Warn("Scrape failed", "job", cfg.JobName, "target", target, "duration", duration, "err", err)
+		s.logFailure(cfg.JobName, target, duration, err)
+		s.scrapeFailures.Inc(cfg.JobName, failureReason(err))
+		scraped = nil
+	}
+	s.scrapeDuration.Observe(duration.Seconds(), cfg.JobName)
+
+	for _, metric := range scraped {
+		s.registry.Register(metric)
 	}
+
+	s.reportScrape(cfg, target, labels, len(scraped), duration, err)
+	s.updateTarget(cfg.JobName, target, start, duration, err)
 }
 
-// scrapeTarget scrapes metrics from a single target
-func (s *Scraper) scrapeTarget(target, jobName string, labels map[string]string) {
-	url := fmt.Sprintf("http://%s/metrics", target)
+// scrape fetches, parses and labels the samples exposed by a target,
+// enforcing the job's limits
+func (s *Scraper) scrape(ctx context.Context, cfg config.ScrapeConfig, target string, labels map[string]string) ([]*metrics.Metric, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scrapeURL(cfg, target), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	// Let targets know how long they have to produce the response
+	req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", strconv.FormatFloat(cfg.ScrapeTimeout.Seconds(), 'f', -1, 64))
+
+	s.mu.RLock()
+	client := s.clients[cfg.JobName]
+	s.mu.RUnlock()
 
-	resp, err := s.client.Get(url)
+	resp, err := client.Do(req)
 	if err != nil {
-		fmt.Printf("Error scraping %s: %v\n", target, err)
-		return
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		fmt.Printf("Non-OK status from %s: %d\n", target, resp.StatusCode)
-		return
+		return nil, fmt.Errorf("%w %s", errHTTPStatus, resp.Status)
 	}
 
-	parsedMetrics, err := s.parseMetrics(resp.Body)
+	var body io.Reader = resp.Body
+	if cfg.BodySizeLimit > 0 {
+		// Read one byte past the limit to detect oversized bodies
+		data, err := io.ReadAll(io.LimitReader(resp.Body, int64(cfg.BodySizeLimit)+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) > int64(cfg.BodySizeLimit) {
+			return nil, fmt.Errorf("%w: limit is %d bytes", errBodySizeLimit, cfg.BodySizeLimit)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	parsedMetrics, err := ParseMetrics(body)
 	if err != nil {
-		fmt.Printf("Error parsing metrics from %s: %v\n", target, err)
-		return
+		return nil, fmt.Errorf("%w: %w", errParse, err)
+	}
+
+	if cfg.SampleLimit > 0 && len(parsedMetrics) > cfg.SampleLimit {
+		return nil, fmt.Errorf("%w: %d samples, limit is %d", errSampleLimit, len(parsedMetrics), cfg.SampleLimit)
 	}
 
-	// Add job and instance labels
+	targetLabels := newTargetLabels(cfg, target, labels)
 	for _, metric := range parsedMetrics {
 		if metric.Labels == nil {
 			metric.Labels = make(map[string]string)
 		}
-		metric.Labels["job"] = jobName
-		metric.Labels["instance"] = target
+		mergeTargetLabels(metric.Labels, targetLabels, cfg.HonorLabels)
 
-		// Add static config labels
-		for k, v := range labels {
-			metric.Labels[k] = v
+		if !cfg.HonorTimestamps {
+			metric.Timestamp = time.Time{}
 		}
 
-		s.registry.Register(metric)
+		if err := checkLabelLimits(cfg, metric); err != nil {
+			return nil, err
+		}
+	}
+
+	return parsedMetrics, nil
+}
+
+// mergeTargetLabels adds target labels to the labels of a scraped sample. On
+// conflict the sample's own label wins if honorLabels is set; otherwise it is
+// kept as exported_<name> and the target label is applied.
+func mergeTargetLabels(sampleLabels, targetLabels map[string]string, honorLabels bool) {
+	for name, value := range targetLabels {
+		existing, conflict := sampleLabels[name]
+		if conflict {
+			if honorLabels {
+				continue
+			}
+
+			exported := "exported_" + name
+			for {
+				if _, taken := sampleLabels[exported]; !taken {
+					break
+				}
+				exported = "exported_" + exported
+			}
+			sampleLabels[exported] = existing
+		}
+		sampleLabels[name] = value
+	}
+}
+
+// checkLabelLimits verifies a metric's labels against the job's label limits
+func checkLabelLimits(cfg config.ScrapeConfig, metric *metrics.Metric) error {
+	if cfg.LabelLimit > 0 && len(metric.Labels) > cfg.LabelLimit {
+		return fmt.Errorf("%w: %s has %d labels, limit is %d", errLabelLimit, metric.Name, len(metric.Labels), cfg.LabelLimit)
+	}
+
+	for name, value := range metric.Labels {
+		if cfg.LabelNameLengthLimit > 0 && len(name) > cfg.LabelNameLengthLimit {
+			return fmt.Errorf("%w: %s has label %q, limit is %d", errLabelNameLengthLimit, metric.Name, name, cfg.LabelNameLengthLimit)
+		}
+		if cfg.LabelValueLengthLimit > 0 && len(value) > cfg.LabelValueLengthLimit {
+			return fmt.Errorf("%w: %s has label %s with a %d byte value, limit is %d",
+				errLabelValueLengthLimit, metric.Name, name, len(value), cfg.LabelValueLengthLimit)
+		}
+	}
+	return nil
+}
+
+// reportScrape writes the synthetic series describing a scrape: up,
+// scrape_duration_seconds and scrape_samples_scraped
+func (s *Scraper) reportScrape(cfg config.ScrapeConfig, target string, labels map[string]string, samples int, duration time.Duration, scrapeErr error) {
+	up := 1.0
+	if scrapeErr != nil {
+		up = 0
+	}
+
+	series := []struct {
+		name  string
+		value float64
+	}{
+		{"up", up},
+		{"scrape_duration_seconds", duration.Seconds()},
+		{"scrape_samples_scraped", float64(samples)},
 	}
+
+	for _, sr := range series {
+		s.registry.Register(&metrics.Metric{
+			Name:   sr.name,
+			Type:   metrics.MetricTypeGauge,
+			Value:  sr.value,
+			Labels: newTargetLabels(cfg, target, labels),
+		})
+	}
+}
+
+// newTargetLabels returns the labels attached to every series of a target:
+// job and instance, overridden by static config labels
+func newTargetLabels(cfg config.ScrapeConfig, target string, labels map[string]string) map[string]string {
+	targetLabels := make(map[string]string, len(labels)+2)
+	targetLabels["job"] = cfg.JobName
+	targetLabels["instance"] = target
+	for k, v := range labels {
+		targetLabels[k] = v
+	}
+	return targetLabels
+}
+
+// scrapeURL returns the URL scraped for a target
+func scrapeURL(cfg config.ScrapeConfig, target string) string {
+	u := url.URL{Scheme: cfg.Scheme, Host: target, Path: cfg.MetricsPath}
+	if u.Path == "" {
+		u.Path = "/metrics"
+	}
+	if len(cfg.Params) > 0 {
+		u.RawQuery = url.Values(cfg.Params).Encode()
+	}
+	return u.String()
 }
 
-// parseMetrics parses Prometheus text format metrics
-func (s *Scraper) parseMetrics(r io.Reader) ([]*metrics.Metric, error) {
+// ParseMetrics parses Prometheus text format metrics. Invalid lines are
+// skipped.
+func ParseMetrics(r io.Reader) ([]*metrics.Metric, error) {
 	var result []*metrics.Metric
 	scanner := bufio.NewScanner(r)
 
@@ -142,7 +512,7 @@ func (s *Scraper) parseMetrics(r io.Reader) ([]*metrics.Metric, error) {
 		}
 
 		// Parse metric line
-		metric, err := s.parseMetricLine(line, currentType)
+		metric, err := parseMetricLine(line, currentType)
 		if err != nil {
 			// Skip invalid lines
 			continue
@@ -159,13 +529,15 @@ func (s *Scraper) parseMetrics(r io.Reader) ([]*metrics.Metric, error) {
 }
 
 // parseMetricLine parses a single metric line
-func (s *Scraper) parseMetricLine(line string, metricType metrics.MetricType) (*metrics.Metric, error) {
+func parseMetricLine(line string, metricType metrics.MetricType) (*metrics.Metric, error) {
 	// Format: metric_name{label1="value1",label2="value2"} value
 	// or: metric_name value
 
+	// Both forms may be followed by a timestamp in milliseconds
+
 	var name string
 	var labelsStr string
-	var valueStr string
+	var sampleStr string
 
 	// Check if line has labels
 	if idx := strings.Index(line, "{"); idx != -1 {
@@ -179,23 +551,38 @@ func (s *Scraper) parseMetricLine(line string, metricType metrics.MetricType) (*
 		}
 
 		labelsStr = rest[:closeIdx]
-		valueStr = strings.TrimSpace(rest[closeIdx+1:])
+		sampleStr = rest[closeIdx+1:]
 	} else {
 		// No labels
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
+		idx := strings.IndexAny(line, " \t")
+		if idx == -1 {
 			return nil, fmt.Errorf("malformed metric line: %s", line)
 		}
-		name = parts[0]
-		valueStr = parts[1]
+		name = line[:idx]
+		sampleStr = line[idx:]
+	}
+
+	fields := strings.Fields(sampleStr)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("malformed metric line: %s", line)
 	}
 
 	// Parse value
-	value, err := strconv.ParseFloat(valueStr, 64)
+	value, err := strconv.ParseFloat(fields[0], 64)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse value: %w", err)
 	}
 
+	// Parse optional timestamp
+	var timestamp time.Time
+	if len(fields) == 2 {
+		ms, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		timestamp = time.UnixMilli(ms)
+	}
+
 	// Parse labels
 	labels := make(map[string]string)
 	if labelsStr != "" {
@@ -218,9 +605,10 @@ func (s *Scraper) parseMetricLine(line string, metricType metrics.MetricType) (*
 	}
 
 	return &metrics.Metric{
-		Name:   name,
-		Type:   metricType,
-		Value:  value,
-		Labels: labels,
+		Name:      name,
+		Type:      metricType,
+		Value:     value,
+		Labels:    labels,
+		Timestamp: timestamp,
 	}, nil
 }
@@ -1,21 +1,40 @@
 package scraper
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/Avinash7390/Promenitheus/pkg/config"
 	"github.com/Avinash7390/Promenitheus/pkg/metrics"
 )
 
-func TestParseMetrics(t *testing.T) {
-	scraper := &Scraper{}
+// findMetric returns the first metric with the given name, or nil
+func findMetric(registry *metrics.MetricRegistry, name string) *metrics.Metric {
+	for _, m := range registry.GetAll() {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
 
+func TestParseMetrics(t *testing.T) {
 	t.Run("Parse simple metric without labels", func(t *testing.T) {
 		input := `# TYPE simple_metric counter
 simple_metric 42`
 
 		reader := strings.NewReader(input)
-		parsed, err := scraper.parseMetrics(reader)
+		parsed, err := ParseMetrics(reader)
 
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
@@ -43,7 +62,7 @@ simple_metric 42`
 http_requests{method="GET",path="/api"} 100`
 
 		reader := strings.NewReader(input)
-		parsed, err := scraper.parseMetrics(reader)
+		parsed, err := ParseMetrics(reader)
 
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
@@ -80,7 +99,7 @@ metric2 20.5
 metric3{label="value"} 30`
 
 		reader := strings.NewReader(input)
-		parsed, err := scraper.parseMetrics(reader)
+		parsed, err := ParseMetrics(reader)
 
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
@@ -112,7 +131,7 @@ test_metric 123
 # Another comment`
 
 		reader := strings.NewReader(input)
-		parsed, err := scraper.parseMetrics(reader)
+		parsed, err := ParseMetrics(reader)
 
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
@@ -132,7 +151,7 @@ test_metric 123
 gauge_metric 3.14`
 
 		reader := strings.NewReader(input)
-		parsed, err := scraper.parseMetrics(reader)
+		parsed, err := ParseMetrics(reader)
 
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
@@ -147,7 +166,7 @@ gauge_metric 3.14`
 		input := `http_requests{method="POST",status="200",path="/users"} 456`
 
 		reader := strings.NewReader(input)
-		parsed, err := scraper.parseMetrics(reader)
+		parsed, err := ParseMetrics(reader)
 
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
@@ -173,3 +192,668 @@ gauge_metric 3.14`
 		}
 	})
 }
+
+func TestScrapeTargetTimeout(t *testing.T) {
+	t.Run("Sends scrape timeout header", func(t *testing.T) {
+		var header string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds")
+			fmt.Fprintln(w, "test_metric 1")
+		}))
+		defer srv.Close()
+
+		cfg := config.ScrapeConfig{JobName: "header-job", Scheme: "http", ScrapeTimeout: 2500 * time.Millisecond}
+		registry := metrics.NewMetricRegistry()
+		s, err := NewScraper(&config.Config{ScrapeConfigs: []config.ScrapeConfig{cfg}}, registry)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		s.scrapeTarget(context.Background(), cfg, strings.TrimPrefix(srv.URL, "http://"), nil)
+
+		if header != "2.5" {
+			t.Errorf("Expected timeout header '2.5', got '%s'", header)
+		}
+		if findMetric(registry, "test_metric") == nil {
+			t.Error("Expected test_metric to be scraped")
+		}
+	})
+
+	t.Run("Uses per-job timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			fmt.Fprintln(w, "test_metric 1")
+		}))
+		defer srv.Close()
+		defer close(release)
+
+		fast := config.ScrapeConfig{JobName: "fast-job", Scheme: "http", ScrapeTimeout: 50 * time.Millisecond}
+		slow := config.ScrapeConfig{JobName: "slow-job", Scheme: "http", ScrapeTimeout: time.Minute}
+		registry := metrics.NewMetricRegistry()
+		s, err := NewScraper(&config.Config{ScrapeConfigs: []config.ScrapeConfig{fast, slow}}, registry)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if s.clients["fast-job"].Timeout != 50*time.Millisecond {
+			t.Errorf("Expected fast-job client timeout 50ms, got %v", s.clients["fast-job"].Timeout)
+		}
+		if s.clients["slow-job"].Timeout != time.Minute {
+			t.Errorf("Expected slow-job client timeout 1m, got %v", s.clients["slow-job"].Timeout)
+		}
+
+		start := time.Now()
+		s.scrapeTarget(context.Background(), fast, strings.TrimPrefix(srv.URL, "http://"), nil)
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Expected scrape to time out quickly, took %v", elapsed)
+		}
+		if findMetric(registry, "test_metric") != nil {
+			t.Error("Expected no samples after timeout")
+		}
+		if up := findMetric(registry, "up"); up == nil || up.Value != 0 {
+			t.Errorf("Expected up=0 after timeout, got %+v", up)
+		}
+	})
+
+	t.Run("Cancelled context aborts scrape", func(t *testing.T) {
+		release := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer srv.Close()
+		defer close(release)
+
+		cfg := config.ScrapeConfig{JobName: "cancel-job", Scheme: "http", ScrapeTimeout: time.Minute}
+		s, err := NewScraper(&config.Config{ScrapeConfigs: []config.ScrapeConfig{cfg}}, metrics.NewMetricRegistry())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			s.scrapeTarget(ctx, cfg, strings.TrimPrefix(srv.URL, "http://"), nil)
+			close(done)
+		}()
+
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Error("Expected scrape to return after context cancellation")
+		}
+	})
+}
+
+func TestScrapeOffset(t *testing.T) {
+	interval := 10 * time.Second
+	now := time.Unix(1700000000, 123456789)
+
+	t.Run("Offset is within interval", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			offset := scrapeOffset("job", fmt.Sprintf("host-%d:8080", i), interval, now)
+			if offset < 0 || offset >= interval {
+				t.Fatalf("Offset %v out of range [0, %v)", offset, interval)
+			}
+		}
+	})
+
+	t.Run("Offset is stable for a target", func(t *testing.T) {
+		first := scrapeOffset("job", "localhost:8080", interval, now)
+		second := scrapeOffset("job", "localhost:8080", interval, now)
+		if first != second {
+			t.Errorf("Expected stable offset, got %v and %v", first, second)
+		}
+
+		// The scrape instant stays on the same phase as time moves on
+		later := now.Add(3 * time.Second)
+		if got := scrapeOffset("job", "localhost:8080", interval, later); got != (first-3*time.Second+interval)%interval {
+			t.Errorf("Expected offset to keep phase, got %v", got)
+		}
+	})
+
+	t.Run("Targets are spread over the interval", func(t *testing.T) {
+		offsets := make(map[time.Duration]bool)
+		for i := 0; i < 10; i++ {
+			offsets[scrapeOffset("job", fmt.Sprintf("host-%d:8080", i), interval, now)] = true
+		}
+		if len(offsets) < 2 {
+			t.Errorf("Expected targets to get different offsets, got %d distinct", len(offsets))
+		}
+	})
+}
+
+func TestRunTargetLoopNoOverlap(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight, scrapes := 0, 0, 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		scrapes++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		// Each scrape takes longer than the interval
+		time.Sleep(30 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		fmt.Fprintln(w, "test_metric 1")
+	}))
+	defer srv.Close()
+
+	cfg := config.ScrapeConfig{
+		JobName:        "slow-job",
+		Scheme:         "http",
+		ScrapeInterval: 10 * time.Millisecond,
+		ScrapeTimeout:  time.Second,
+	}
+	s, err := NewScraper(&config.Config{ScrapeConfigs: []config.ScrapeConfig{cfg}}, metrics.NewMetricRegistry())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	s.runTargetLoop(ctx, cfg, strings.TrimPrefix(srv.URL, "http://"), nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if maxInFlight != 1 {
+		t.Errorf("Expected at most 1 scrape in flight, got %d", maxInFlight)
+	}
+	if scrapes < 2 {
+		t.Errorf("Expected at least 2 scrapes, got %d", scrapes)
+	}
+}
+
+func TestScrapeLimits(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "# TYPE requests_total counter")
+		fmt.Fprintln(w, `requests_total{method="GET",path="/very/long/path/value"} 10`)
+		fmt.Fprintln(w, `requests_total{method="POST",path="/"} 5`)
+		fmt.Fprintln(w, "# TYPE temperature gauge")
+		fmt.Fprintln(w, "temperature 21.5")
+	}))
+	defer srv.Close()
+	target := strings.TrimPrefix(srv.URL, "http://")
+
+	tests := []struct {
+		name    string
+		cfg     config.ScrapeConfig
+		wantErr error
+	}{
+		{"No limits", config.ScrapeConfig{}, nil},
+		{"Within limits", config.ScrapeConfig{SampleLimit: 3, LabelLimit: 4, LabelNameLengthLimit: 8, LabelValueLengthLimit: 32, BodySizeLimit: 1024}, nil},
+		{"Sample limit", config.ScrapeConfig{SampleLimit: 2}, errSampleLimit},
+		{"Label limit", config.ScrapeConfig{LabelLimit: 3}, errLabelLimit},
+		{"Label name length limit", config.ScrapeConfig{LabelNameLengthLimit: 5}, errLabelNameLengthLimit},
+		{"Label value length limit", config.ScrapeConfig{LabelValueLengthLimit: 10}, errLabelValueLengthLimit},
+		{"Body size limit", config.ScrapeConfig{BodySizeLimit: 64}, errBodySizeLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.JobName = "limited-job"
+			cfg.Scheme = "http"
+			cfg.StaticConfigs = []config.StaticConfig{{Targets: []string{target}}}
+
+			registry := metrics.NewMetricRegistry()
+			s, err := NewScraper(&config.Config{ScrapeConfigs: []config.ScrapeConfig{cfg}}, registry)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			_, err = s.scrape(context.Background(), cfg, target, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+
+			s.scrapeTarget(context.Background(), cfg, target, nil)
+			status := s.Targets()[0]
+			up := findMetric(registry, "up")
+
+			if tt.wantErr == nil {
+				if findMetric(registry, "temperature") == nil {
+					t.Error("Expected samples to be stored")
+				}
+				if up == nil || up.Value != 1 {
+					t.Errorf("Expected up=1, got %+v", up)
+				}
+				if status.Health != HealthGood {
+					t.Errorf("Expected health 'up', got '%s'", status.Health)
+				}
+				return
+			}
+
+			// A failed scrape must not store any of the target's samples
+			if findMetric(registry, "temperature") != nil || findMetric(registry, "requests_total") != nil {
+				t.Error("Expected no samples to be stored after a limit was exceeded")
+			}
+			if up == nil || up.Value != 0 {
+				t.Errorf("Expected up=0, got %+v", up)
+			}
+			if status.Health != HealthBad {
+				t.Errorf("Expected health 'down', got '%s'", status.Health)
+			}
+			if !strings.Contains(status.LastError, tt.wantErr.Error()) {
+				t.Errorf("Expected last error to mention %q, got %q", tt.wantErr, status.LastError)
+			}
+		})
+	}
+}
+
+func TestScrapeFailureMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/missing":
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	target := strings.TrimPrefix(srv.URL, "http://")
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedTarget := strings.TrimPrefix(closed.URL, "http://")
+	closed.Close()
+
+	tests := []struct {
+		name   string
+		target string
+		path   string
+		reason string
+	}{
+		{"Timeout", target, "/slow", "timeout"},
+		{"HTTP status", target, "/missing", "http_status"},
+		{"Connection refused", closedTarget, "/metrics", "connection"},
+		{"Success", target, "/metrics", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.ScrapeConfig{
+				JobName:       "job",
+				Scheme:        "http",
+				MetricsPath:   tt.path,
+				ScrapeTimeout: 50 * time.Millisecond,
+				StaticConfigs: []config.StaticConfig{{Targets: []string{tt.target}}},
+			}
+			s, err := NewScraper(&config.Config{ScrapeConfigs: []config.ScrapeConfig{cfg}}, metrics.NewMetricRegistry())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			s.scrapeTarget(context.Background(), cfg, tt.target, nil)
+
+			for _, reason := range []string{"timeout", "http_status", "connection"} {
+				expected := 0.0
+				if reason == tt.reason {
+					expected = 1
+				}
+				if got := s.scrapeFailures.Value("job", reason); got != expected {
+					t.Errorf("Expected %v failures with reason %s, got %v", expected, reason, got)
+				}
+			}
+		})
+	}
+}
+
+func TestTargets(t *testing.T) {
+	cfg := &config.Config{ScrapeConfigs: []config.ScrapeConfig{
+		{JobName: "b-job", Scheme: "https", MetricsPath: "/federate", Params: map[string][]string{"match[]": {`{job="api"}`}},
+			StaticConfigs: []config.StaticConfig{{Targets: []string{"host-2:443", "host-1:443"}}}},
+		{JobName: "a-job", Scheme: "http", StaticConfigs: []config.StaticConfig{{Targets: []string{"host-3:80"}, Labels: map[string]string{"env": "dev"}}}},
+	}}
+
+	s, err := NewScraper(cfg, metrics.NewMetricRegistry())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	targets := s.Targets()
+	if len(targets) != 3 {
+		t.Fatalf("Expected 3 targets, got %d", len(targets))
+	}
+
+	if targets[0].Job != "a-job" || targets[1].Instance != "host-1:443" || targets[2].Instance != "host-2:443" {
+		t.Errorf("Targets not sorted by job and instance: %+v", targets)
+	}
+
+	if targets[0].ScrapeURL != "http://host-3:80/metrics" {
+		t.Errorf("Expected scrape URL 'http://host-3:80/metrics', got '%s'", targets[0].ScrapeURL)
+	}
+	if expected := "https://host-1:443/federate?match%5B%5D=%7Bjob%3D%22api%22%7D"; targets[1].ScrapeURL != expected {
+		t.Errorf("Expected scrape URL '%s', got '%s'", expected, targets[1].ScrapeURL)
+	}
+
+	if targets[0].Labels["env"] != "dev" {
+		t.Error("Expected static labels on target status")
+	}
+
+	for _, target := range targets {
+		if target.Health != HealthUnknown {
+			t.Errorf("Expected health 'unknown' before first scrape, got '%s'", target.Health)
+		}
+	}
+}
+
+func TestParseMetricTimestamps(t *testing.T) {
+	input := `with_labels{method="GET"} 10 1700000000123
+without_labels 20 1700000000456
+no_timestamp 30`
+
+	parsed, err := ParseMetrics(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(parsed) != 3 {
+		t.Fatalf("Expected 3 metrics, got %d", len(parsed))
+	}
+
+	if parsed[0].Value != 10 || !parsed[0].Timestamp.Equal(time.UnixMilli(1700000000123)) {
+		t.Errorf("Labelled sample parsed incorrectly: value %v, timestamp %v", parsed[0].Value, parsed[0].Timestamp)
+	}
+
+	if parsed[1].Value != 20 || !parsed[1].Timestamp.Equal(time.UnixMilli(1700000000456)) {
+		t.Errorf("Unlabelled sample parsed incorrectly: value %v, timestamp %v", parsed[1].Value, parsed[1].Timestamp)
+	}
+
+	if !parsed[2].Timestamp.IsZero() {
+		t.Errorf("Expected zero timestamp, got %v", parsed[2].Timestamp)
+	}
+}
+
+func TestHonorLabelsAndTimestamps(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `federated{job="origin",instance="origin:9090",exported_job="older",env="prod"} 1 1700000000000`)
+	}))
+	defer srv.Close()
+	target := strings.TrimPrefix(srv.URL, "http://")
+	static := map[string]string{"env": "staging"}
+
+	scrape := func(t *testing.T, cfg config.ScrapeConfig) *metrics.Metric {
+		t.Helper()
+		cfg.JobName = "federate"
+		cfg.Scheme = "http"
+
+		s, err := NewScraper(&config.Config{ScrapeConfigs: []config.ScrapeConfig{cfg}}, metrics.NewMetricRegistry())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		scraped, err := s.scrape(context.Background(), cfg, target, static)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return scraped[0]
+	}
+
+	t.Run("Conflicting labels are exported", func(t *testing.T) {
+		m := scrape(t, config.ScrapeConfig{})
+
+		expected := map[string]string{
+			"job":                   "federate",
+			"instance":              target,
+			"env":                   "staging",
+			"exported_instance":     "origin:9090",
+			"exported_env":          "prod",
+			"exported_job":          "older",
+			"exported_exported_job": "origin",
+		}
+		for k, v := range expected {
+			if m.Labels[k] != v {
+				t.Errorf("Expected label %s=%q, got %q", k, v, m.Labels[k])
+			}
+		}
+		if len(m.Labels) != len(expected) {
+			t.Errorf("Expected %d labels, got %v", len(expected), m.Labels)
+		}
+	})
+
+	t.Run("Honor labels keeps target labels", func(t *testing.T) {
+		m := scrape(t, config.ScrapeConfig{HonorLabels: true})
+
+		if m.Labels["job"] != "origin" || m.Labels["instance"] != "origin:9090" || m.Labels["env"] != "prod" {
+			t.Errorf("Expected exposed labels to be kept, got %v", m.Labels)
+		}
+		if _, ok := m.Labels["exported_instance"]; ok {
+			t.Error("Expected no exported_ labels with honor_labels")
+		}
+	})
+
+	t.Run("Timestamps are ignored by default", func(t *testing.T) {
+		m := scrape(t, config.ScrapeConfig{})
+
+		if !m.Timestamp.IsZero() {
+			t.Errorf("Expected exposed timestamp to be dropped, got %v", m.Timestamp)
+		}
+	})
+
+	t.Run("Honor timestamps keeps exposed timestamps", func(t *testing.T) {
+		m := scrape(t, config.ScrapeConfig{HonorTimestamps: true})
+
+		if !m.Timestamp.Equal(time.UnixMilli(1700000000000)) {
+			t.Errorf("Expected exposed timestamp, got %v", m.Timestamp)
+		}
+	})
+}
+
+func TestStaticLabelsOverrideTargetLabels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "test_metric 1")
+	}))
+	defer srv.Close()
+	target := strings.TrimPrefix(srv.URL, "http://")
+
+	cfg := config.ScrapeConfig{JobName: "api", Scheme: "http"}
+	registry := metrics.NewMetricRegistry()
+	s, err := NewScraper(&config.Config{ScrapeConfigs: []config.ScrapeConfig{cfg}}, registry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	s.scrapeTarget(context.Background(), cfg, target, map[string]string{"instance": "api-1", "env": "prod"})
+
+	for _, name := range []string{"test_metric", "up", "scrape_duration_seconds", "scrape_samples_scraped"} {
+		m := findMetric(registry, name)
+		if m == nil {
+			t.Errorf("Expected %s to be registered", name)
+			continue
+		}
+		if m.Labels["job"] != "api" || m.Labels["instance"] != "api-1" || m.Labels["env"] != "prod" {
+			t.Errorf("Expected static labels to override instance of %s, got %v", name, m.Labels)
+		}
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	var mu sync.Mutex
+	scrapes := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		scrapes[r.URL.Path]++
+		mu.Unlock()
+		fmt.Fprintln(w, "test_metric 1")
+	}))
+	defer srv.Close()
+	target := strings.TrimPrefix(srv.URL, "http://")
+
+	scraped := func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return scrapes[path]
+	}
+	waitScraped := func(t *testing.T, path string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for scraped(path) == 0 {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for scrape of %s", path)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	job := func(name string) config.ScrapeConfig {
+		return config.ScrapeConfig{
+			JobName:        name,
+			Scheme:         "http",
+			MetricsPath:    "/" + name,
+			ScrapeInterval: 20 * time.Millisecond,
+			ScrapeTimeout:  time.Second,
+			StaticConfigs:  []config.StaticConfig{{Targets: []string{target}}},
+		}
+	}
+
+	s, err := NewScraper(&config.Config{ScrapeConfigs: []config.ScrapeConfig{job("a"), job("b")}}, metrics.NewMetricRegistry())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+	waitScraped(t, "/a")
+	waitScraped(t, "/b")
+
+	t.Run("Keeps unchanged jobs and replaces the rest", func(t *testing.T) {
+		loopA := s.loops["a"]
+
+		if err := s.ApplyConfig(&config.Config{ScrapeConfigs: []config.ScrapeConfig{job("a"), job("c")}}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if s.loops["a"] != loopA {
+			t.Error("Expected loop of unchanged job to keep running")
+		}
+		select {
+		case <-loopA.done:
+			t.Error("Expected loop of unchanged job not to stop")
+		default:
+		}
+		if _, ok := s.loops["b"]; ok {
+			t.Error("Expected loop of removed job to stop")
+		}
+
+		waitScraped(t, "/c")
+		before := scraped("/b")
+		time.Sleep(60 * time.Millisecond)
+		if after := scraped("/b"); after != before {
+			t.Errorf("Expected removed job to stop scraping, got %d more scrapes", after-before)
+		}
+
+		targets := s.Targets()
+		if len(targets) != 2 || targets[0].Job != "a" || targets[1].Job != "c" {
+			t.Errorf("Expected targets of jobs a and c, got %+v", targets)
+		}
+	})
+
+	t.Run("Keeps running jobs on invalid config", func(t *testing.T) {
+		bad := job("d")
+		bad.Scheme = "https"
+		bad.TLSConfig.CAFile = "/nonexistent/ca.pem"
+
+		if err := s.ApplyConfig(&config.Config{ScrapeConfigs: []config.ScrapeConfig{bad}}); err == nil {
+			t.Fatal("Expected error for invalid TLS config")
+		}
+		if len(s.loops) != 2 || len(s.Targets()) != 2 {
+			t.Errorf("Expected jobs a and c to keep running, got %d loops", len(s.loops))
+		}
+	})
+}
+
+func TestScrapeFailureLog(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	target := strings.TrimPrefix(closed.URL, "http://")
+	closed.Close()
+
+	dir := t.TempDir()
+	job := func(name, logFile string) config.ScrapeConfig {
+		return config.ScrapeConfig{
+			JobName:              name,
+			Scheme:               "http",
+			ScrapeTimeout:        time.Second,
+			ScrapeFailureLogFile: logFile,
+			StaticConfigs:        []config.StaticConfig{{Targets: []string{target}}},
+		}
+	}
+	readLines := func(t *testing.T, path string) []map[string]any {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var lines []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var entry map[string]any
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("Invalid log line %q: %v", line, err)
+			}
+			lines = append(lines, entry)
+		}
+		return lines
+	}
+
+	shared := filepath.Join(dir, "failures.log")
+	a, b := job("a", shared), job("b", shared)
+	s, err := NewScraper(&config.Config{ScrapeConfigs: []config.ScrapeConfig{a, b, job("quiet", "")}}, metrics.NewMetricRegistry())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(s.failureLogFiles) != 1 {
+		t.Errorf("Expected jobs to share 1 open file, got %d", len(s.failureLogFiles))
+	}
+
+	t.Run("Logs failed scrapes of configured jobs", func(t *testing.T) {
+		s.scrapeTarget(context.Background(), a, target, nil)
+		s.scrapeTarget(context.Background(), b, target, nil)
+		s.scrapeTarget(context.Background(), job("quiet", ""), target, nil)
+
+		lines := readLines(t, shared)
+		if len(lines) != 2 {
+			t.Fatalf("Expected 2 log lines, got %d", len(lines))
+		}
+		for i, name := range []string{"a", "b"} {
+			entry := lines[i]
+			if entry["job"] != name || entry["target"] != target || !strings.Contains(fmt.Sprint(entry["err"]), "connection refused") {
+				t.Errorf("Unexpected log line: %v", entry)
+			}
+		}
+	})
+
+	t.Run("Reopens files on config change", func(t *testing.T) {
+		moved := filepath.Join(dir, "moved.log")
+		b = job("b", moved)
+		if err := s.ApplyConfig(&config.Config{ScrapeConfigs: []config.ScrapeConfig{a, b}}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(s.failureLogFiles) != 2 {
+			t.Errorf("Expected 2 open files, got %d", len(s.failureLogFiles))
+		}
+
+		s.scrapeTarget(context.Background(), b, target, nil)
+		if lines := readLines(t, moved); len(lines) != 1 || lines[0]["job"] != "b" {
+			t.Errorf("Expected failure of job b in new file, got %v", lines)
+		}
+
+		if err := s.ApplyConfig(&config.Config{ScrapeConfigs: []config.ScrapeConfig{job("a", "")}}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(s.failureLogFiles) != 0 {
+			t.Errorf("Expected all files to be closed, got %d open", len(s.failureLogFiles))
+		}
+	})
+
+	t.Run("Unwritable file is an error", func(t *testing.T) {
+		_, err := NewScraper(&config.Config{ScrapeConfigs: []config.ScrapeConfig{job("a", filepath.Join(dir, "missing", "x.log"))}}, metrics.NewMetricRegistry())
+		if err == nil || !strings.Contains(err.Error(), "failed to open scrape failure log file") {
+			t.Errorf("Expected open error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"time"
+)
+
+// TargetHealth describes the outcome of the last scrape of a target
+type TargetHealth string
+
+const (
+	HealthUnknown TargetHealth = "unknown"
+	HealthGood    TargetHealth = "up"
+	HealthBad     TargetHealth = "down"
+)
+
+// Errors returned when a target answers with a non-200 status or an
+// exposition that cannot be read
+var (
+	errHTTPStatus = errors.New("server returned HTTP status")
+	errParse      = errors.New("failed to parse metrics")
+)
+
+// Errors returned when a scrape exceeds one of the job's limits
+var (
+	errBodySizeLimit         = errors.New("body size limit exceeded")
+	errSampleLimit           = errors.New("sample limit exceeded")
+	errLabelLimit            = errors.New("label limit exceeded")
+	errLabelNameLengthLimit  = errors.New("label name length limit exceeded")
+	errLabelValueLengthLimit = errors.New("label value length limit exceeded")
+)
+
+// TargetStatus reports the state of a scrape target
+type TargetStatus struct {
+	Job                string
+	Instance           string
+	ScrapeURL          string
+	Labels             map[string]string
+	Health             TargetHealth
+	LastError          string
+	LastScrape         time.Time
+	LastScrapeDuration time.Duration
+}
+
+// targetKey identifies a target within the scraper
+func targetKey(jobName, target string) string {
+	return jobName + "/" + target
+}
+
+// Targets returns the status of all scrape targets sorted by job and instance
+func (s *Scraper) Targets() []TargetStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]TargetStatus, 0, len(s.targets))
+	for _, status := range s.targets {
+		result = append(result, *status)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Job != result[j].Job {
+			return result[i].Job < result[j].Job
+		}
+		return result[i].Instance < result[j].Instance
+	})
+	return result
+}
+
+// updateTarget records the outcome of a scrape
+func (s *Scraper) updateTarget(jobName, target string, start time.Time, duration time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, ok := s.targets[targetKey(jobName, target)]
+	if !ok {
+		return
+	}
+
+	status.LastScrape = start
+	status.LastScrapeDuration = duration
+	if err != nil {
+		status.Health = HealthBad
+		status.LastError = err.Error()
+	} else {
+		status.Health = HealthGood
+		status.LastError = ""
+	}
+}
+
+// failureReason classifies a scrape error for the
+// promenitheus_scrape_failures_total metric
+func failureReason(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, errHTTPStatus):
+		return "http_status"
+	case errors.Is(err, errParse):
+		return "parse"
+	case errors.Is(err, errBodySizeLimit):
+		return "body_size_limit"
+	case errors.Is(err, errSampleLimit):
+		return "sample_limit"
+	case errors.Is(err, errLabelLimit), errors.Is(err, errLabelNameLengthLimit), errors.Is(err, errLabelValueLengthLimit):
+		return "label_limit"
+	case errors.As(err, &netErr):
+		return "connection"
+	}
+	return "other"
+}
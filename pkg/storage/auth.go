@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// newServerTLSConfig converts a web TLS config into a crypto/tls config
+func newServerTLSConfig(cfg *config.TLSServerConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		// gRPC clients only offer h2. Preferring HTTP/1.1 keeps other
+		// clients, which cmux cannot route over HTTP/2, on the gateway.
+		NextProtos: []string{"http/1.1", "h2"},
+	}
+
+	for t := tls.NoClientCert; t <= tls.RequireAndVerifyClientCert; t++ {
+		if t.String() == cfg.ClientAuthType {
+			tlsConfig.ClientAuth = t
+		}
+	}
+
+	if cfg.ClientCAFile != "" {
+		caPEM, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// authenticator checks the Authorization header of HTTP requests and the
+// authorization metadata of gRPC calls against the users and tokens of the
+// web config
+type authenticator struct {
+	users  map[string]string
+	tokens []string
+
+	// dummyHash is compared against for unknown users, so they take as
+	// long to reject as wrong passwords
+	dummyHash []byte
+	// accepted caches credentials that matched, since bcrypt is slow by
+	// design
+	accepted sync.Map
+}
+
+func newAuthenticator(cfg *config.WebConfig) (*authenticator, error) {
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("dummy"), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	return &authenticator{
+		users:     cfg.BasicAuthUsers,
+		tokens:    cfg.BearerTokens,
+		dummyHash: dummyHash,
+	}, nil
+}
+
+// authenticate reports whether an Authorization header value holds valid
+// credentials
+func (a *authenticator) authenticate(header string) bool {
+	scheme, credentials, _ := strings.Cut(header, " ")
+	switch {
+	case strings.EqualFold(scheme, "Basic") && len(a.users) > 0:
+		decoded, err := base64.StdEncoding.DecodeString(credentials)
+		if err != nil {
+			return false
+		}
+		user, password, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return false
+		}
+		return a.checkPassword(user, password)
+	case strings.EqualFold(scheme, "Bearer"):
+		for _, token := range a.tokens {
+			if subtle.ConstantTimeCompare([]byte(credentials), []byte(token)) == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *authenticator) checkPassword(user, password string) bool {
+	key := sha256.Sum256([]byte(user + "\x00" + password))
+	if _, ok := a.accepted.Load(key); ok {
+		return true
+	}
+
+	hash, ok := a.users[user]
+	if !ok {
+		bcrypt.CompareHashAndPassword(a.dummyHash, []byte(password))
+		return false
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return false
+	}
+	a.accepted.Store(key, struct{}{})
+	return true
+}
+
+// checkMetadata returns Unauthenticated unless the call carries valid
+// credentials
+func (a *authenticator) checkMetadata(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, header := range md.Get("authorization") {
+		if a.authenticate(header) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "invalid or missing credentials")
+}
+
+// unaryInterceptor rejects gRPC calls without valid credentials. Requests
+// through the gateway are checked by middleware instead.
+func (a *authenticator) unaryInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := a.checkMetadata(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamInterceptor rejects gRPC streams without valid credentials
+func (a *authenticator) streamInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.checkMetadata(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// middleware rejects HTTP requests matched by the gateway mux without
+// valid credentials
+func (a *authenticator) middleware(next runtime.HandlerFunc) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		if !a.authenticate(r.Header.Get("Authorization")) {
+			if len(a.users) > 0 {
+				w.Header().Add("WWW-Authenticate", `Basic realm="Promenitheus"`)
+			}
+			if len(a.tokens) > 0 {
+				w.Header().Add("WWW-Authenticate", "Bearer")
+			}
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r, pathParams)
+	}
+}
@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthenticator(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := newAuthenticator(&config.WebConfig{
+		BasicAuthUsers: map[string]string{"alice": string(hash)},
+		BearerTokens:   []string{"token-1"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	handler := auth.middleware(func(w http.ResponseWriter, r *http.Request, _ map[string]string) {})
+
+	tests := []struct {
+		name     string
+		setAuth  func(r *http.Request)
+		expected int
+	}{
+		{"No credentials", func(r *http.Request) {}, http.StatusUnauthorized},
+		{"Basic auth", func(r *http.Request) { r.SetBasicAuth("alice", "secret") }, http.StatusOK},
+		{"Wrong password", func(r *http.Request) { r.SetBasicAuth("alice", "wrong") }, http.StatusUnauthorized},
+		{"Unknown user", func(r *http.Request) { r.SetBasicAuth("bob", "secret") }, http.StatusUnauthorized},
+		{"Bearer token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer token-1") }, http.StatusOK},
+		{"Wrong bearer token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer token-2") }, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil)
+			tt.setAuth(req)
+			rec := httptest.NewRecorder()
+			handler(rec, req, nil)
+
+			if rec.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, rec.Code)
+			}
+			if rec.Code == http.StatusUnauthorized && len(rec.Header().Values("WWW-Authenticate")) != 2 {
+				t.Errorf("Expected Basic and Bearer challenges, got %v", rec.Header().Values("WWW-Authenticate"))
+			}
+		})
+	}
+
+	t.Run("gRPC metadata", func(t *testing.T) {
+		handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+
+		_, err := auth.unaryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("Expected Unauthenticated without credentials, got %v", err)
+		}
+
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer token-1"))
+		resp, err := auth.unaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+		if err != nil || resp != "ok" {
+			t.Errorf("Expected call to pass with bearer token, got %v, %v", resp, err)
+		}
+	})
+}
+
+func TestServerTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newTestCert(t, dir, "ca", nil, nil)
+	newTestCert(t, dir, "server", ca, caKey)
+	newTestCert(t, dir, "client", ca, caKey)
+
+	tlsConfig, err := newServerTLSConfig(&config.TLSServerConfig{
+		CertFile:       filepath.Join(dir, "server.crt"),
+		KeyFile:        filepath.Join(dir, "server.key"),
+		ClientCAFile:   filepath.Join(dir, "ca.crt"),
+		ClientAuthType: "RequireAndVerifyClientCert",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("Expected RequireAndVerifyClientCert, got %v", tlsConfig.ClientAuth)
+	}
+
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	clientCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dial := func(cfg *tls.Config) (*tls.Conn, error) {
+		conn, err := tls.Dial("tcp", lis.Addr().String(), cfg)
+		if err != nil {
+			return nil, err
+		}
+		// The server closes the connection after the handshake; client
+		// certificate errors only surface on this first read in TLS 1.3
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if _, err := conn.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+			conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+
+	t.Run("Client certificate required", func(t *testing.T) {
+		if _, err := dial(&tls.Config{RootCAs: roots, ServerName: "localhost"}); err == nil {
+			t.Error("Expected handshake without client certificate to fail")
+		}
+	})
+
+	t.Run("Negotiate protocols", func(t *testing.T) {
+		tests := []struct {
+			offered  []string
+			expected string
+		}{
+			{[]string{"h2"}, "h2"},
+			{[]string{"h2", "http/1.1"}, "http/1.1"},
+		}
+		for _, tt := range tests {
+			conn, err := dial(&tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: []tls.Certificate{clientCert}, NextProtos: tt.offered})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := conn.ConnectionState().NegotiatedProtocol; got != tt.expected {
+				t.Errorf("Expected %s for client offering %v, got %s", tt.expected, tt.offered, got)
+			}
+			conn.Close()
+		}
+	})
+}
+
+// newTestCert writes a certificate and key for localhost to dir, signed by
+// parent or self-signed as a CA if parent is nil
+func newTestCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, name+".crt"), certPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Avinash7390/Promenitheus/pkg/instrument"
+	"github.com/Avinash7390/Promenitheus/pkg/push"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// SelfMetricsPath is where the server exposes its own metrics
+const SelfMetricsPath = "/-/metrics"
+
+// requestMetrics counts the gRPC and HTTP requests served
+type requestMetrics struct {
+	grpcRequests *instrument.CounterVec
+	grpcDuration *instrument.HistogramVec
+	httpRequests *instrument.CounterVec
+	httpDuration *instrument.HistogramVec
+}
+
+func newRequestMetrics(reg *instrument.Registry) *requestMetrics {
+	m := &requestMetrics{
+		grpcRequests: instrument.NewCounterVec("promenitheus_grpc_requests_total",
+			"gRPC requests by method and status code.", "method", "code"),
+		grpcDuration: instrument.NewHistogramVec("promenitheus_grpc_request_duration_seconds",
+			"Latency of gRPC requests by method.", instrument.DefBuckets, "method"),
+		httpRequests: instrument.NewCounterVec("promenitheus_http_requests_total",
+			"HTTP requests by handler and status code.", "handler", "code"),
+		httpDuration: instrument.NewHistogramVec("promenitheus_http_request_duration_seconds",
+			"Latency of HTTP requests by handler.", instrument.DefBuckets, "handler"),
+	}
+	reg.MustRegister(m.grpcRequests, m.grpcDuration, m.httpRequests, m.httpDuration)
+	return m
+}
+
+// unaryInterceptor records every gRPC request. Requests through the
+// gateway call the handlers directly and are counted as HTTP requests.
+func (m *requestMetrics) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	m.grpcRequests.Inc(info.FullMethod, status.Code(err).String())
+	m.grpcDuration.Observe(time.Since(start).Seconds(), info.FullMethod)
+	return resp, err
+}
+
+// streamInterceptor records every gRPC stream when it ends
+func (m *requestMetrics) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	m.grpcRequests.Inc(info.FullMethod, status.Code(err).String())
+	m.grpcDuration.Observe(time.Since(start).Seconds(), info.FullMethod)
+	return err
+}
+
+// middleware records every HTTP request matched by the gateway mux
+func (m *requestMetrics) middleware(next runtime.HandlerFunc) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r, pathParams)
+
+		handler := r.URL.Path
+		if strings.HasPrefix(handler, push.PathPrefix) {
+			// Keep one series for all push groupings
+			handler = push.PathPrefix
+		}
+		m.httpRequests.Inc(handler, strconv.Itoa(rec.status))
+		m.httpDuration.Observe(time.Since(start).Seconds(), handler)
+	}
+}
+
+// statusRecorder remembers the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, so
+// streaming responses can be flushed
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -2,15 +2,23 @@ package storage
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"log/slog"
 	"net"
 	"net/http"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/soheilhy/cmux"
 	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"github.com/Avinash7390/Promenitheus/pkg/config"
+	"github.com/Avinash7390/Promenitheus/pkg/federation"
 	"github.com/Avinash7390/Promenitheus/pkg/grpcserver"
+	"github.com/Avinash7390/Promenitheus/pkg/instrument"
 	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"github.com/Avinash7390/Promenitheus/pkg/push"
+	"github.com/Avinash7390/Promenitheus/pkg/query"
+	"github.com/Avinash7390/Promenitheus/pkg/remote"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
@@ -23,6 +31,18 @@ type Server struct {
 	httpServer *http.Server
 	listener   net.Listener
 	mux        cmux.CMux
+	targets    grpcserver.TargetProvider
+	rules      grpcserver.RuleProvider
+	engine     *query.Engine
+
+	// externalLabels are added to federated series
+	externalLabels map[string]string
+
+	remoteWriteReceiver bool
+	push                *push.Store
+	reload              func() error
+	selfMetrics         *instrument.Registry
+	webConfig           *config.WebConfig
 }
 
 // NewServer creates a new storage server
@@ -33,15 +53,77 @@ func NewServer(registry *metrics.MetricRegistry, port int) *Server {
 	}
 }
 
+// SetTargetProvider sets the source of scrape target status served by the API
+func (s *Server) SetTargetProvider(targets grpcserver.TargetProvider) {
+	s.targets = targets
+}
+
+// SetRuleProvider sets the source of rule and alert state served by the API
+func (s *Server) SetRuleProvider(rules grpcserver.RuleProvider) {
+	s.rules = rules
+}
+
+// SetQueryEngine sets the engine evaluating queries through the API, one
+// reading the registry by default
+func (s *Server) SetQueryEngine(engine *query.Engine) {
+	s.engine = engine
+}
+
+// SetExternalLabels sets the labels added to series served by /federate
+func (s *Server) SetExternalLabels(labels map[string]string) {
+	s.externalLabels = labels
+}
+
+// EnableRemoteWriteReceiver accepts remote write requests at /api/v1/write
+func (s *Server) EnableRemoteWriteReceiver() {
+	s.remoteWriteReceiver = true
+}
+
+// SetPushStore accepts pushed metrics at /metrics/job/<job>{/<label>/<value>}
+func (s *Server) SetPushStore(store *push.Store) {
+	s.push = store
+}
+
+// SetReloadFunc enables reloading the configuration with a POST or PUT
+// request to /-/reload
+func (s *Server) SetReloadFunc(reload func() error) {
+	s.reload = reload
+}
+
+// SetSelfMetrics exposes the metrics of the server itself at
+// SelfMetricsPath and records gRPC and HTTP requests in reg
+func (s *Server) SetSelfMetrics(reg *instrument.Registry) {
+	s.selfMetrics = reg
+}
+
+// SetWebConfig enables TLS and authentication of all HTTP and gRPC
+// requests as configured
+func (s *Server) SetWebConfig(cfg *config.WebConfig) {
+	s.webConfig = cfg
+}
+
 // Start starts both HTTP and gRPC servers on the same port using cmux
 func (s *Server) Start() error {
-	// Create a TCP listener
+	var tlsConfig *tls.Config
 	var err error
+	if s.webConfig != nil && s.webConfig.TLSServerConfig != nil {
+		tlsConfig, err = newServerTLSConfig(s.webConfig.TLSServerConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create a TCP listener
 	s.listener, err = net.Listen("tcp", fmt.Sprintf(":%d", s.port))
 	if err != nil {
 		return fmt.Errorf("failed to listen on port %d: %w", s.port, err)
 	}
 
+	// TLS is terminated before cmux, so it matches the decrypted protocol
+	if tlsConfig != nil {
+		s.listener = tls.NewListener(s.listener, tlsConfig)
+	}
+
 	// Create a cmux instance
 	s.mux = cmux.New(s.listener)
 
@@ -52,14 +134,44 @@ func (s *Server) Start() error {
 	httpL := s.mux.Match(cmux.HTTP1Fast())
 
 	// Setup gRPC server
-	s.grpcServer = grpc.NewServer()
+	var grpcOpts []grpc.ServerOption
+	var gwOpts []runtime.ServeMuxOption
+	if s.selfMetrics != nil {
+		requests := newRequestMetrics(s.selfMetrics)
+		grpcOpts = append(grpcOpts,
+			grpc.ChainUnaryInterceptor(requests.unaryInterceptor),
+			grpc.ChainStreamInterceptor(requests.streamInterceptor))
+		gwOpts = append(gwOpts, runtime.WithMiddlewares(requests.middleware))
+	}
+	// Authentication runs inside instrumentation so rejected requests are
+	// counted
+	if s.webConfig != nil && s.webConfig.AuthEnabled() {
+		auth, err := newAuthenticator(s.webConfig)
+		if err != nil {
+			return fmt.Errorf("failed to set up authentication: %w", err)
+		}
+		grpcOpts = append(grpcOpts,
+			grpc.ChainUnaryInterceptor(auth.unaryInterceptor),
+			grpc.ChainStreamInterceptor(auth.streamInterceptor))
+		gwOpts = append(gwOpts, runtime.WithMiddlewares(auth.middleware))
+	}
+	s.grpcServer = grpc.NewServer(grpcOpts...)
 	metricsServer := grpcserver.NewMetricsServer(s.registry)
+	if s.targets != nil {
+		metricsServer.SetTargetProvider(s.targets)
+	}
+	if s.rules != nil {
+		metricsServer.SetRuleProvider(s.rules)
+	}
+	if s.engine != nil {
+		metricsServer.SetQueryEngine(s.engine)
+	}
 	pb.RegisterMetricsServiceServer(s.grpcServer, metricsServer)
 	reflection.Register(s.grpcServer)
 
 	// Setup gRPC-Gateway (HTTP to gRPC translator)
 	// Use in-process connection instead of dialing back to ourselves
-	gwmux := runtime.NewServeMux()
+	gwmux := runtime.NewServeMux(gwOpts...)
 	
 	// Register gRPC-Gateway handlers with the server directly (no dial needed)
 	err = pb.RegisterMetricsServiceHandlerServer(context.Background(), gwmux, metricsServer)
@@ -67,16 +179,87 @@ func (s *Server) Start() error {
 		return fmt.Errorf("failed to register gateway: %w", err)
 	}
 
-	// Setup HTTP server - ALL requests go through gRPC-Gateway
-	// No custom HTTP handlers - everything is routed to gRPC handlers
+	// Remote write and read requests are binary protobuf, so they are handled
+	// directly on the gateway mux instead of being translated to gRPC
+	if s.remoteWriteReceiver {
+		writeHandler := remote.NewWriteHandler(s.registry)
+		err = gwmux.HandlePath(http.MethodPost, "/api/v1/write", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			writeHandler.ServeHTTP(w, r)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register remote write receiver: %w", err)
+		}
+	}
+
+	readHandler := remote.NewReadHandler(s.registry)
+	err = gwmux.HandlePath(http.MethodPost, "/api/v1/read", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		readHandler.ServeHTTP(w, r)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register remote read endpoint: %w", err)
+	}
+
+	// Pushed metrics share the /metrics prefix with GetMetrics, which only
+	// handles GET, so the methods do not conflict
+	if s.push != nil {
+		for _, method := range []string{http.MethodPut, http.MethodPost, http.MethodDelete} {
+			err = gwmux.HandlePath(method, push.PathPrefix+"{grouping=**}", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				s.push.ServeHTTP(w, r)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to register push endpoint: %w", err)
+			}
+		}
+	}
+
+	if s.reload != nil {
+		for _, method := range []string{http.MethodPost, http.MethodPut} {
+			err = gwmux.HandlePath(method, "/-/reload", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				if err := s.reload(); err != nil {
+					http.Error(w, fmt.Sprintf("failed to reload config: %v", err), http.StatusInternalServerError)
+				}
+			})
+			if err != nil {
+				return fmt.Errorf("failed to register reload endpoint: %w", err)
+			}
+		}
+	}
+
+	if s.selfMetrics != nil {
+		err = gwmux.HandlePath(http.MethodGet, SelfMetricsPath, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			s.selfMetrics.ServeHTTP(w, r)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register self metrics endpoint: %w", err)
+		}
+	}
+
+	// Live series updates for browsers, sharing WatchMetrics with gRPC
+	watch := &watchHandler{server: metricsServer}
+	err = gwmux.HandlePath(http.MethodGet, WatchPath, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		watch.ServeHTTP(w, r)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register watch endpoint: %w", err)
+	}
+
+	// Federation serves the text format, not a JSON gateway response
+	federateHandler := federation.NewHandler(s.registry, s.externalLabels)
+	err = gwmux.HandlePath(http.MethodGet, "/federate", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		federateHandler.ServeHTTP(w, r)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register federation endpoint: %w", err)
+	}
+
+	// Setup HTTP server - ALL requests go through the gRPC-Gateway mux
 	s.httpServer = &http.Server{
 		Handler: gwmux,
 	}
 
-	fmt.Printf("Starting unified server on :%d (HTTP/1.1 and gRPC/HTTP2)\n", s.port)
-	fmt.Printf("  - HTTP/1.1 requests → grpc-gateway → gRPC handlers\n")
-	fmt.Printf("  - HTTP/2 gRPC requests → gRPC server (direct)\n")
-	fmt.Printf("  - All HTTP routes handled by grpc-gateway (no custom HTTP handlers)\n")
+	// HTTP/1.1 requests go through grpc-gateway, HTTP/2 gRPC requests
+	// directly to the gRPC server
+	slog.Info("Starting unified server for HTTP/1.1 and gRPC", "port", s.port, "tls", tlsConfig != nil)
 
 	// Start serving gRPC and HTTP
 	errChan := make(chan error, 3)
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"github.com/Avinash7390/Promenitheus/pkg/grpcserver"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// WatchPath serves live series updates as Server-Sent Events
+const WatchPath = "/api/v1/watch"
+
+// watchHandler bridges WatchMetrics to browsers, which cannot consume gRPC
+// streams. Each message of the stream is sent as an event whose data is the
+// message in the gateway's JSON encoding.
+type watchHandler struct {
+	server *grpcserver.MetricsServer
+}
+
+func (h *watchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	stream := &sseStream{ctx: r.Context(), w: w}
+	err := h.server.WatchMetrics(&pb.WatchMetricsRequest{Selector: r.URL.Query().Get("selector")}, stream)
+	if err == nil || r.Context().Err() != nil {
+		return
+	}
+
+	st := status.Convert(err)
+	if !stream.started {
+		http.Error(w, st.Message(), runtime.HTTPStatusFromCode(st.Code()))
+		return
+	}
+	// The stream ended on the server side, e.g. because the client was too
+	// slow; tell the client why before closing
+	data, _ := protojson.Marshal(st.Proto())
+	fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+	http.NewResponseController(w).Flush()
+}
+
+// sseStream is a WatchMetrics stream writing messages as Server-Sent Events
+type sseStream struct {
+	// Only Context and Send are used by WatchMetrics
+	grpc.ServerStream
+
+	ctx     context.Context
+	w       http.ResponseWriter
+	started bool
+}
+
+func (s *sseStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *sseStream) Send(resp *pb.WatchMetricsResponse) error {
+	if !s.started {
+		s.started = true
+		s.w.Header().Set("Content-Type", "text/event-stream")
+		s.w.Header().Set("Cache-Control", "no-cache")
+		s.w.WriteHeader(http.StatusOK)
+	}
+
+	data, err := protojson.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "data: %s\n\n", data); err != nil {
+		return err
+	}
+	return http.NewResponseController(s.w).Flush()
+}
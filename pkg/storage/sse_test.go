@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	pb "github.com/Avinash7390/Promenitheus/api/proto/v1"
+	"github.com/Avinash7390/Promenitheus/pkg/grpcserver"
+	"github.com/Avinash7390/Promenitheus/pkg/metrics"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestWatchHandler(t *testing.T) {
+	registry := metrics.NewMetricRegistry()
+	registry.Register(&metrics.Metric{Name: "up", Type: metrics.MetricTypeGauge, Value: 1, Labels: map[string]string{"job": "api"}})
+	registry.Register(&metrics.Metric{Name: "up", Type: metrics.MetricTypeGauge, Value: 1, Labels: map[string]string{"job": "web"}})
+	srv := httptest.NewServer(&watchHandler{server: grpcserver.NewMetricsServer(registry)})
+	defer srv.Close()
+
+	t.Run("Sends snapshot then updates", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "?selector=" + url.QueryEscape(`up{job="api"}`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("Expected content type text/event-stream, got %q", ct)
+		}
+		events := bufio.NewScanner(resp.Body)
+		next := func() *pb.WatchMetricsResponse {
+			t.Helper()
+			for events.Scan() {
+				data, ok := strings.CutPrefix(events.Text(), "data: ")
+				if !ok {
+					continue
+				}
+				msg := &pb.WatchMetricsResponse{}
+				if err := protojson.Unmarshal([]byte(data), msg); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return msg
+			}
+			t.Fatalf("Expected event, got %v", events.Err())
+			return nil
+		}
+
+		msg := next()
+		if len(msg.Metrics) != 1 || msg.Metrics[0].Labels["job"] != "api" {
+			t.Fatalf("Expected snapshot with up{job=\"api\"}, got %v", msg.Metrics)
+		}
+
+		registry.Register(&metrics.Metric{Name: "up", Type: metrics.MetricTypeGauge, Value: 0, Labels: map[string]string{"job": "web"}})
+		registry.Register(&metrics.Metric{Name: "up", Type: metrics.MetricTypeGauge, Value: 0, Labels: map[string]string{"job": "api"}})
+
+		msg = next()
+		if len(msg.Metrics) != 1 || msg.Metrics[0].Labels["job"] != "api" || msg.Metrics[0].Value != 0 {
+			t.Errorf("Expected update of up{job=\"api\"} to 0, got %v", msg.Metrics)
+		}
+	})
+
+	t.Run("Invalid selector", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "?selector=" + url.QueryEscape(`up{`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+}